@@ -6,12 +6,20 @@ package airflowreceiver
 import (
 	"errors"
 	"fmt"
+	"math"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/config/confignet"
 	"go.opentelemetry.io/collector/config/configopaque"
 	"go.opentelemetry.io/collector/scraper/scraperhelper"
+
+	scraper_internal "github.com/npcomplete777/airflowreceiver/internal/scraper"
 )
 
 var (
@@ -22,11 +30,114 @@ var (
 type Config struct {
 	scraperhelper.ControllerConfig `mapstructure:",squash"`
 
+	Profile string `mapstructure:"profile"`
+
+	// StrictStartup fails receiver startup when the REST API or a database
+	// is unreachable or rejects the credentials, instead of retrying on
+	// every scrape
+	StrictStartup bool `mapstructure:"strict_startup"`
+
+	// ScrapeSummaryLogInterval logs one Info summary per scraper at this
+	// interval; per-scrape details are only logged at debug level
+	ScrapeSummaryLogInterval time.Duration `mapstructure:"scrape_summary_log_interval"`
+
+	// MetricOwners picks the scraper that reports each metric family both the
+	// REST API and the database can emit
+	MetricOwners map[string]string `mapstructure:"metric_owners"`
+
+	// OutputMode "aggregated" drops per-run and per-task-instance data points
+	// in favour of per-DAG counts, duration histograms and last-run state
+	OutputMode string `mapstructure:"output_mode"`
+
+	// Attributes turns off optional data point attributes that add a series
+	// per DAG run, task instance or worker
+	Attributes map[string]AttributeConfig `mapstructure:"attributes"`
+
+	// Redaction hashes, truncates or drops sensitive attribute values, such
+	// as owners and hostnames, before they are emitted
+	Redaction RedactionConfig `mapstructure:"redaction"`
+
+	// ResourcePerDAG reports each DAG's metrics on its own resource with an
+	// airflow.dag.id attribute, instead of a dag.id attribute on every point
+	ResourcePerDAG bool `mapstructure:"resource_per_dag"`
+
 	CollectionModes CollectionModes `mapstructure:"collection_modes"`
 	RESTAPIConfig   *RESTAPIConfig   `mapstructure:"rest_api"`
 	DatabaseConfig  *DatabaseConfig  `mapstructure:"database"`
 	StatsDConfig    *StatsDConfig    `mapstructure:"statsd"`
 	LogConfig       *LogConfig       `mapstructure:"logs"`
+	OTLPConfig      *OTLPConfig      `mapstructure:"otlp"`
+
+	// Instances scrapes further Airflow deployments alongside the top-level
+	// rest_api and database blocks
+	Instances []InstanceConfig `mapstructure:"instances"`
+}
+
+// AttributeConfig toggles one optional attribute
+type AttributeConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// RedactionConfig maps attribute keys to how their values are redacted
+type RedactionConfig struct {
+	// Salt keys the hash so short values cannot be recovered by hashing
+	// candidates
+	Salt       configopaque.String            `mapstructure:"salt"`
+	Attributes map[string]RedactionRuleConfig `mapstructure:"attributes"`
+}
+
+// RedactionRuleConfig is the redaction of one attribute key
+type RedactionRuleConfig struct {
+	// Action is hash, truncate or drop
+	Action string `mapstructure:"action"`
+	// Length is the number of characters truncate keeps
+	Length int `mapstructure:"length"`
+}
+
+func (c RedactionConfig) validate() error {
+	for key, rule := range c.Attributes {
+		switch rule.Action {
+		case scraper_internal.RedactionHash, scraper_internal.RedactionDrop:
+		case scraper_internal.RedactionTruncate:
+			if rule.Length <= 0 {
+				return fmt.Errorf("%s: truncate requires a positive length", key)
+			}
+		default:
+			return fmt.Errorf("%s: action must be %q, %q or %q, got %q", key,
+				scraper_internal.RedactionHash, scraper_internal.RedactionTruncate, scraper_internal.RedactionDrop, rule.Action)
+		}
+	}
+	return nil
+}
+
+// redactor returns the scraper redactor, or nil when nothing is redacted
+func (c RedactionConfig) redactor() *scraper_internal.Redactor {
+	rules := make(map[string]scraper_internal.RedactionRule, len(c.Attributes))
+	for key, rule := range c.Attributes {
+		rules[key] = scraper_internal.RedactionRule{Action: rule.Action, Length: rule.Length}
+	}
+	return scraper_internal.NewRedactor(string(c.Salt), rules)
+}
+
+// disabledAttributes returns the optional attributes turned off in the config
+func (cfg *Config) disabledAttributes() []string {
+	var keys []string
+	for key, attr := range cfg.Attributes {
+		if !attr.Enabled {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// InstanceConfig is one additional Airflow deployment. Its resources carry
+// airflow.instance.name plus any extra resource attributes.
+type InstanceConfig struct {
+	Name               string            `mapstructure:"name"`
+	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
+	RESTAPIConfig      *RESTAPIConfig    `mapstructure:"rest_api"`
+	DatabaseConfig     *DatabaseConfig   `mapstructure:"database"`
 }
 
 type CollectionModes struct {
@@ -34,33 +145,196 @@ type CollectionModes struct {
 	Database bool `mapstructure:"database"`
 	StatsD   bool `mapstructure:"statsd"`
 	Logs     bool `mapstructure:"logs"`
+	OTLP     bool `mapstructure:"otlp"`
 }
 
 type RESTAPIConfig struct {
 	confighttp.ClientConfig `mapstructure:",squash"`
 
-	Username           string              `mapstructure:"username"`
-	Password           configopaque.String `mapstructure:"password"`
-	CollectionInterval time.Duration       `mapstructure:"collection_interval"`
-	IncludePastRuns    bool                `mapstructure:"include_past_runs"`
-	PastRunsLookback   time.Duration       `mapstructure:"past_runs_lookback"`
+	Username            string              `mapstructure:"username"`
+	Password            configopaque.String `mapstructure:"password"`
+	BearerToken         configopaque.String `mapstructure:"bearer_token"`
+	MappedTaskRawMax    int                 `mapstructure:"mapped_task_raw_max_fan_out"`
+	CollectionInterval  time.Duration       `mapstructure:"collection_interval"`
+	HealthCheckInterval time.Duration       `mapstructure:"health_check_interval"`
+	IncludePastRuns     bool                `mapstructure:"include_past_runs"`
+	PastRunsLookback    time.Duration       `mapstructure:"past_runs_lookback"`
+	Endpoints           []string            `mapstructure:"endpoints"`
+	Concurrency         int                 `mapstructure:"concurrency"`
+	ScrapeTimeout       time.Duration       `mapstructure:"scrape_timeout"`
+	RequestsPerSecond   float64             `mapstructure:"requests_per_second"`
+	Burst               int                 `mapstructure:"burst"`
+	DAGCacheTTL         time.Duration       `mapstructure:"dag_cache_ttl"`
+	IncrementalRuns     bool                `mapstructure:"incremental_runs"`
+	InventoryEvents     bool                `mapstructure:"inventory_events"`
+	DAGWarningLogs      bool                `mapstructure:"dag_warning_logs"`
+	TaskMetadata        bool                `mapstructure:"task_metadata"`
+	WorkerHostname      bool                `mapstructure:"worker_hostname"`
+	FailedTaskLogs      bool                `mapstructure:"failed_task_logs"`
+	FailedTaskLogBytes  int                 `mapstructure:"failed_task_log_max_bytes"`
+	AuthMode            string              `mapstructure:"auth_mode"`
+	MWAA                MWAAConfig          `mapstructure:"mwaa"`
+	Astro               AstroConfig         `mapstructure:"astro"`
+
+	// DisableResponseCompression stops asking the webserver for gzipped responses
+	DisableResponseCompression bool `mapstructure:"disable_response_compression"`
+	// DisableFieldProjection requests full objects instead of fields= projections
+	DisableFieldProjection bool `mapstructure:"disable_field_projection"`
+
+	// Paging of DAG run and task instance lists
+	PageSize         int `mapstructure:"page_size"`
+	MaxDAGRuns       int `mapstructure:"max_dag_runs"`
+	MaxTaskInstances int `mapstructure:"max_task_instances"`
+	// BatchDAGRuns lists runs for all DAGs with one paginated POST request
+	BatchDAGRuns bool `mapstructure:"batch_dag_runs"`
+	// BatchTaskInstances lists task instances for all runs the same way
+	BatchTaskInstances bool `mapstructure:"batch_task_instances"`
+	// ConfigOptions are "section.key" Airflow options attached as resource
+	// attributes, read from /config when expose_config is enabled
+	ConfigOptions []string `mapstructure:"config_options"`
+
+	// Server-side filters for the DAGs that are scraped
+	OnlyActiveDAGs    bool `mapstructure:"only_active_dags"`
+	ExcludePausedDAGs bool `mapstructure:"exclude_paused_dags"`
+}
+
+// MWAAConfig selects the Amazon MWAA environment to authenticate against when
+// auth_mode is mwaa
+type MWAAConfig struct {
+	EnvironmentName string `mapstructure:"environment_name"`
+	Region          string `mapstructure:"region"`
+	RoleARN         string `mapstructure:"role_arn"`
+}
+
+// AstroConfig holds the Astronomer deployment settings used when auth_mode is
+// astro
+type AstroConfig struct {
+	// DeploymentURL is the deployment URL shown in the Astro UI, for example
+	// clmh59gt0000308jv9fjnbn8p.astronomer.run/d8fe2ptd
+	DeploymentURL string              `mapstructure:"deployment_url"`
+	APIToken      configopaque.String `mapstructure:"api_token"`
+}
+
+// airflowEndpoint turns a deployment URL into the webserver endpoint,
+// accepting it with or without a scheme and the /api/v1 suffix
+func (c AstroConfig) airflowEndpoint() string {
+	endpoint := strings.TrimRight(strings.TrimSpace(c.DeploymentURL), "/")
+	endpoint = strings.TrimSuffix(endpoint, "/api/v1")
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	return endpoint
 }
 
 type DatabaseConfig struct {
+	// Host is a hostname, or a directory holding the Postgres Unix socket
 	Host               string              `mapstructure:"host"`
+	SocketDir          string              `mapstructure:"socket_dir"`
 	Port               int                 `mapstructure:"port"`
 	Database           string              `mapstructure:"database"`
 	Username           string              `mapstructure:"username"`
 	Password           configopaque.String `mapstructure:"password"`
 	SSLMode            string              `mapstructure:"ssl_mode"`
+	SSLRootCert        string              `mapstructure:"ssl_root_cert"`
+	SSLCert            string              `mapstructure:"ssl_cert"`
+	SSLKey             string              `mapstructure:"ssl_key"`
 	CollectionInterval time.Duration       `mapstructure:"collection_interval"`
 	QueryTimeout       time.Duration       `mapstructure:"query_timeout"`
+	MaxOpenConns       int                 `mapstructure:"max_open_conns"`
+	MaxIdleConns       int                 `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime    time.Duration       `mapstructure:"conn_max_lifetime"`
+	ConnMaxIdleTime    time.Duration       `mapstructure:"conn_max_idle_time"`
+	CacheStatements    bool                `mapstructure:"cache_statements"`
+	CustomQueries      []CustomQuery       `mapstructure:"custom_queries"`
+	// StatementTimeout is sent as the session's statement_timeout so Postgres
+	// cancels runaway queries itself; it defaults to QueryTimeout
+	StatementTimeout time.Duration `mapstructure:"statement_timeout"`
+	ApplicationName  string        `mapstructure:"application_name"`
+	AuthMode         string        `mapstructure:"auth_mode"`
+	RDSIAM           RDSIAMConfig  `mapstructure:"rds_iam"`
+	// Running task instances older than OrphanedTaskThreshold are reported as
+	// orphaned, except in DAGs matching OrphanedTaskExcludeDAGs
+	OrphanedTaskThreshold   time.Duration `mapstructure:"orphaned_task_threshold"`
+	OrphanedTaskExcludeDAGs []string      `mapstructure:"orphaned_task_exclude_dags"`
+	// TableStats reports row estimates and on-disk size of TableStatsTables,
+	// or of the tables that grow fastest when it is empty
+	TableStats       bool     `mapstructure:"table_stats"`
+	TableStatsTables []string `mapstructure:"table_stats_tables"`
+	// RetentionPeriod reports XCom and rendered template rows older than it,
+	// which `airflow db clean` should have removed; 0 disables the check
+	RetentionPeriod time.Duration `mapstructure:"retention_period"`
+	// DurationPercentiles adds percentile_cont gauges for task instance and
+	// DAG run durations, e.g. [0.5, 0.95, 0.99]
+	DurationPercentiles []float64 `mapstructure:"duration_percentiles"`
+	// DurationHistograms reports durations as exponential histograms instead
+	// of avg, max and percentile gauges
+	DurationHistograms bool `mapstructure:"duration_histograms"`
+	// IncrementalTaskStats reads only task instances updated since the last
+	// scrape and aggregates the 24h window in the receiver
+	IncrementalTaskStats bool `mapstructure:"incremental_task_stats"`
+	// ReadReplica sends the scraper's queries to standby hosts instead of host
+	ReadReplica ReadReplicaConfig `mapstructure:"read_replica"`
+	// StateChanges pushes task instance and DAG run state changes to the logs
+	// pipeline as they happen, through LISTEN/NOTIFY or logical replication
+	// on host
+	StateChanges StateChangesConfig `mapstructure:"state_changes"`
+}
+
+// StateChangesConfig configures the state change events. In notify mode, the
+// triggers sending the notifications are installed by the receiver when
+// install_trigger is set, and by the user otherwise. In logical_replication
+// mode, the user creates the publication and the receiver the slot.
+type StateChangesConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Mode          string        `mapstructure:"mode"`
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	// notify mode
+	Channel        string `mapstructure:"channel"`
+	InstallTrigger bool   `mapstructure:"install_trigger"`
+	// logical_replication mode
+	Publication   string `mapstructure:"publication"`
+	Slot          string `mapstructure:"slot"`
+	TemporarySlot bool   `mapstructure:"temporary_slot"`
+}
+
+// ReadReplicaConfig lists read-only hosts, as host or host:port. With several
+// hosts, target_session_attrs picks which of them may serve the queries.
+type ReadReplicaConfig struct {
+	Hosts              []string `mapstructure:"hosts"`
+	TargetSessionAttrs string   `mapstructure:"target_session_attrs"`
+}
+
+type CustomQuery struct {
+	SQL     string              `mapstructure:"sql"`
+	Metrics []CustomQueryMetric `mapstructure:"metrics"`
+}
+
+type CustomQueryMetric struct {
+	MetricName       string   `mapstructure:"metric_name"`
+	ValueColumn      string   `mapstructure:"value_column"`
+	AttributeColumns []string `mapstructure:"attribute_columns"`
+	Unit             string   `mapstructure:"unit"`
+	Description      string   `mapstructure:"description"`
+	DataType         string   `mapstructure:"data_type"`
+	ValueType        string   `mapstructure:"value_type"`
+	Monotonic        bool     `mapstructure:"monotonic"`
+	// Merge declares how gauge points combine when disabled or redacted
+	// attributes make them identical: sum, max or min. Without it the last
+	// point is kept and the others are counted as collapsed drops.
+	Merge string `mapstructure:"merge"`
 }
 
 type StatsDConfig struct {
 	confignet.AddrConfig `mapstructure:",squash"`
 
 	AggregationInterval   time.Duration             `mapstructure:"aggregation_interval"`
+	Prefix                string                    `mapstructure:"prefix"`
+	StripPrefix           bool                      `mapstructure:"strip_prefix"`
+	Rename                map[string]string         `mapstructure:"rename"`
+	ReadBufferSize        int                       `mapstructure:"read_buffer_size"`
+	SocketBufferSize      int                       `mapstructure:"socket_buffer_size"`
+	ReadBatchSize         int                       `mapstructure:"read_batch_size"`
+	ParserWorkers         int                       `mapstructure:"parser_workers"`
 	EnableMetricType      bool                      `mapstructure:"enable_metric_type"`
 	TimerHistogramMapping []TimerHistogramMapping   `mapstructure:"timer_histogram_mapping"`
 }
@@ -76,29 +350,115 @@ type Histogram struct {
 }
 
 type LogConfig struct {
+	Source             string              `mapstructure:"source"`
 	Host               string              `mapstructure:"host"`
+	SocketDir          string              `mapstructure:"socket_dir"`
 	Port               int                 `mapstructure:"port"`
 	Database           string              `mapstructure:"database"`
 	Username           string              `mapstructure:"username"`
 	Password           configopaque.String `mapstructure:"password"`
 	SSLMode            string              `mapstructure:"ssl_mode"`
+	SSLRootCert        string              `mapstructure:"ssl_root_cert"`
+	SSLCert            string              `mapstructure:"ssl_cert"`
+	SSLKey             string              `mapstructure:"ssl_key"`
 	CollectionInterval time.Duration       `mapstructure:"collection_interval"`
+	IncludeEvents      []string            `mapstructure:"include_events"`
+	ExcludeEvents      []string            `mapstructure:"exclude_events"`
+	BodyFormat         string              `mapstructure:"body_format"`
+	StatementTimeout   time.Duration       `mapstructure:"statement_timeout"`
+	ApplicationName    string              `mapstructure:"application_name"`
+	AuthMode           string              `mapstructure:"auth_mode"`
+	RDSIAM             RDSIAMConfig        `mapstructure:"rds_iam"`
+	// StartAt is earliest, latest or an RFC 3339 timestamp
+	StartAt string `mapstructure:"start_at"`
+}
+
+// start returns where the event log source begins reading. StartAt has been
+// validated, so a value that is not a position is a timestamp.
+func (c *LogConfig) start() scraper_internal.LogStart {
+	switch c.StartAt {
+	case scraper_internal.LogStartEarliest, scraper_internal.LogStartLatest:
+		return scraper_internal.LogStart{Position: c.StartAt}
+	}
+	t, _ := time.Parse(time.RFC3339, c.StartAt)
+	return scraper_internal.LogStart{Time: t}
+}
+
+// RDSIAMConfig configures RDS IAM database authentication when auth_mode is
+// rds_iam
+type RDSIAMConfig struct {
+	Region  string `mapstructure:"region"`
+	RoleARN string `mapstructure:"role_arn"`
+}
+
+// internal returns the scraper settings, or nil when authMode uses a password
+func (c RDSIAMConfig) internal(authMode string) *scraper_internal.RDSIAMConfig {
+	if authMode != scraper_internal.DBAuthModeRDSIAM {
+		return nil
+	}
+	return &scraper_internal.RDSIAMConfig{Region: c.Region, RoleARN: c.RoleARN}
+}
+
+type OTLPConfig struct {
+	confighttp.ServerConfig `mapstructure:",squash"`
+
+	InstanceName            string        `mapstructure:"instance_name"`
+	MetadataRefreshInterval time.Duration `mapstructure:"metadata_refresh_interval"`
 }
 
 func (cfg *Config) Validate() error {
-	if !cfg.CollectionModes.RESTAPI && !cfg.CollectionModes.Database && !cfg.CollectionModes.StatsD && !cfg.CollectionModes.Logs {
+	if !cfg.CollectionModes.RESTAPI && !cfg.CollectionModes.Database && !cfg.CollectionModes.StatsD && !cfg.CollectionModes.Logs && !cfg.CollectionModes.OTLP && len(cfg.Instances) == 0 {
 		return ErrNoMode
 	}
 
+	if cfg.ScrapeSummaryLogInterval < 0 {
+		return errors.New("scrape_summary_log_interval cannot be negative")
+	}
+	if err := validateMetricOwners(cfg.MetricOwners); err != nil {
+		return fmt.Errorf("metric_owners: %w", err)
+	}
+	for key := range cfg.Attributes {
+		if !slices.Contains(scraper_internal.OptionalAttributes, key) {
+			return fmt.Errorf("attributes: unknown attribute %q (expected one of %s)",
+				key, strings.Join(scraper_internal.OptionalAttributes, ", "))
+		}
+	}
+	if err := cfg.Redaction.validate(); err != nil {
+		return fmt.Errorf("redaction: %w", err)
+	}
+	switch cfg.OutputMode {
+	case "":
+		cfg.OutputMode = scraper_internal.OutputModeDetailed
+	case scraper_internal.OutputModeDetailed, scraper_internal.OutputModeAggregated:
+	default:
+		return fmt.Errorf("output_mode must be %q or %q, got %q",
+			scraper_internal.OutputModeDetailed, scraper_internal.OutputModeAggregated, cfg.OutputMode)
+	}
+
 	if cfg.CollectionModes.RESTAPI {
 		if cfg.RESTAPIConfig == nil {
 			return errors.New("rest_api config required when rest_api mode enabled")
 		}
-		if cfg.RESTAPIConfig.Endpoint == "" {
-			return fmt.Errorf("rest_api: %w", ErrNoEndpoint)
+		if err := cfg.RESTAPIConfig.validate(cfg.ControllerConfig.CollectionInterval); err != nil {
+			return fmt.Errorf("rest_api: %w", err)
+		}
+	}
+
+	if cfg.RESTAPIConfig != nil && cfg.RESTAPIConfig.InventoryEvents && !cfg.CollectionModes.RESTAPI {
+		return errors.New("rest_api: inventory_events requires rest_api mode")
+	}
+	if cfg.RESTAPIConfig != nil && cfg.RESTAPIConfig.DAGWarningLogs && !cfg.CollectionModes.RESTAPI {
+		return errors.New("rest_api: dag_warning_logs requires rest_api mode")
+	}
+	if cfg.RESTAPIConfig != nil && cfg.RESTAPIConfig.FailedTaskLogs {
+		if !cfg.CollectionModes.RESTAPI {
+			return errors.New("rest_api: failed_task_logs requires rest_api mode")
+		}
+		if cfg.RESTAPIConfig.FailedTaskLogBytes < 0 {
+			return errors.New("rest_api: failed_task_log_max_bytes cannot be negative")
 		}
-		if cfg.RESTAPIConfig.CollectionInterval <= 0 {
-			cfg.RESTAPIConfig.CollectionInterval = 30 * time.Second
+		if cfg.RESTAPIConfig.FailedTaskLogBytes == 0 {
+			cfg.RESTAPIConfig.FailedTaskLogBytes = scraper_internal.DefaultFailedTaskLogMaxBytes
 		}
 	}
 
@@ -106,22 +466,13 @@ func (cfg *Config) Validate() error {
 		if cfg.DatabaseConfig == nil {
 			return errors.New("database config required when database mode enabled")
 		}
-		if cfg.DatabaseConfig.Host == "" {
-			return errors.New("database host must be specified")
-		}
-		if cfg.DatabaseConfig.CollectionInterval <= 0 {
-			cfg.DatabaseConfig.CollectionInterval = 30 * time.Second
-		}
-		if cfg.DatabaseConfig.Port == 0 {
-			cfg.DatabaseConfig.Port = 5432
-		}
-		if cfg.DatabaseConfig.SSLMode == "" {
-			cfg.DatabaseConfig.SSLMode = "disable"
-		}
-		if cfg.DatabaseConfig.QueryTimeout <= 0 {
-			cfg.DatabaseConfig.QueryTimeout = 15 * time.Second
+		if err := cfg.DatabaseConfig.validate(cfg.ControllerConfig.CollectionInterval); err != nil {
+			return fmt.Errorf("database: %w", err)
 		}
 	}
+	if cfg.DatabaseConfig != nil && cfg.DatabaseConfig.StateChanges.Enabled && !cfg.CollectionModes.Database {
+		return errors.New("database: state_changes requires database mode")
+	}
 
 	if cfg.CollectionModes.StatsD {
 		if cfg.StatsDConfig == nil {
@@ -130,28 +481,549 @@ func (cfg *Config) Validate() error {
 		if cfg.StatsDConfig.AggregationInterval <= 0 {
 			cfg.StatsDConfig.AggregationInterval = 60 * time.Second
 		}
+		if cfg.StatsDConfig.Prefix == "" {
+			cfg.StatsDConfig.Prefix = scraper_internal.DefaultStatsDPrefix
+		}
+		if cfg.StatsDConfig.ReadBufferSize < 0 || cfg.StatsDConfig.SocketBufferSize < 0 {
+			return errors.New("statsd: read_buffer_size and socket_buffer_size cannot be negative")
+		}
+		if cfg.StatsDConfig.ReadBufferSize == 0 {
+			cfg.StatsDConfig.ReadBufferSize = scraper_internal.DefaultStatsDReadBufferSize
+		}
+		if cfg.StatsDConfig.ReadBatchSize < 0 || cfg.StatsDConfig.ParserWorkers < 0 {
+			return errors.New("statsd: read_batch_size and parser_workers cannot be negative")
+		}
+		if cfg.StatsDConfig.ReadBatchSize == 0 {
+			cfg.StatsDConfig.ReadBatchSize = scraper_internal.DefaultStatsDReadBatchSize
+		}
+		if cfg.StatsDConfig.ParserWorkers == 0 {
+			cfg.StatsDConfig.ParserWorkers = 1
+		}
+		for from, to := range cfg.StatsDConfig.Rename {
+			if from == "" || to == "" {
+				return errors.New("statsd: rename rules need a non-empty name and replacement")
+			}
+		}
+		switch cfg.StatsDConfig.Transport {
+		case "":
+			cfg.StatsDConfig.Transport = confignet.TransportTypeUDP
+		case confignet.TransportTypeUDP, confignet.TransportTypeUDP4, confignet.TransportTypeUDP6:
+		case confignet.TransportTypeUnixgram:
+			if cfg.StatsDConfig.Endpoint == "" {
+				return errors.New("statsd: unixgram transport requires a socket path as endpoint")
+			}
+		default:
+			return fmt.Errorf("statsd: unsupported transport %q (expected udp, udp4, udp6 or unixgram)", cfg.StatsDConfig.Transport)
+		}
 	}
 
 	if cfg.CollectionModes.Logs {
 		if cfg.LogConfig == nil {
 			return errors.New("logs config required when logs mode enabled")
 		}
-		if cfg.LogConfig.Host == "" {
-			return errors.New("logs database host must be specified")
+		switch cfg.LogConfig.Source {
+		case "", scraper_internal.LogSourceDatabase:
+			cfg.LogConfig.Source = scraper_internal.LogSourceDatabase
+			host, err := resolvePostgresHost(cfg.LogConfig.Host, cfg.LogConfig.SocketDir)
+			if err != nil {
+				return fmt.Errorf("logs: %w", err)
+			}
+			cfg.LogConfig.Host = host
+			if cfg.LogConfig.Database == "" {
+				return errors.New("logs database name must be specified")
+			}
+			if cfg.LogConfig.Port == 0 {
+				cfg.LogConfig.Port = 5432
+			}
+			if err := validatePostgresAuth(&cfg.LogConfig.AuthMode, &cfg.LogConfig.SSLMode,
+				cfg.LogConfig.Host, cfg.LogConfig.Password, cfg.LogConfig.RDSIAM); err != nil {
+				return fmt.Errorf("logs: %w", err)
+			}
+			if cfg.LogConfig.SSLMode == "" {
+				cfg.LogConfig.SSLMode = "disable"
+			}
+			if err := validateClientCert(cfg.LogConfig.SSLCert, cfg.LogConfig.SSLKey); err != nil {
+				return fmt.Errorf("logs: %w", err)
+			}
+			if cfg.LogConfig.StatementTimeout < 0 {
+				return errors.New("logs: statement_timeout cannot be negative")
+			}
+			if cfg.LogConfig.ApplicationName == "" {
+				cfg.LogConfig.ApplicationName = scraper_internal.DefaultApplicationName
+			}
+		case scraper_internal.LogSourceRESTAPI:
+			// Connection settings, auth and rate limits come from rest_api
+			if !cfg.CollectionModes.RESTAPI {
+				return errors.New("logs: source rest_api requires rest_api mode")
+			}
+		default:
+			return fmt.Errorf("logs: source must be %q or %q", scraper_internal.LogSourceDatabase, scraper_internal.LogSourceRESTAPI)
 		}
-		if cfg.LogConfig.Database == "" {
-			return errors.New("logs database name must be specified")
+		if cfg.LogConfig.CollectionInterval <= 0 {
+			cfg.LogConfig.CollectionInterval = 30 * time.Second
 		}
-		if cfg.LogConfig.Port == 0 {
-			cfg.LogConfig.Port = 5432
+		switch cfg.LogConfig.BodyFormat {
+		case "":
+			cfg.LogConfig.BodyFormat = scraper_internal.LogBodyText
+		case scraper_internal.LogBodyText, scraper_internal.LogBodyStructured:
+		default:
+			return fmt.Errorf("logs: body_format must be %q or %q", scraper_internal.LogBodyText, scraper_internal.LogBodyStructured)
 		}
-		if cfg.LogConfig.SSLMode == "" {
-			cfg.LogConfig.SSLMode = "disable"
+		switch cfg.LogConfig.StartAt {
+		case "":
+			cfg.LogConfig.StartAt = scraper_internal.LogStartEarliest
+		case scraper_internal.LogStartEarliest, scraper_internal.LogStartLatest:
+		default:
+			if _, err := time.Parse(time.RFC3339, cfg.LogConfig.StartAt); err != nil {
+				return fmt.Errorf("logs: start_at must be %q, %q or an RFC 3339 timestamp: %w",
+					scraper_internal.LogStartEarliest, scraper_internal.LogStartLatest, err)
+			}
 		}
-		if cfg.LogConfig.CollectionInterval <= 0 {
-			cfg.LogConfig.CollectionInterval = 30 * time.Second
+		if err := scraper_internal.ValidateEventPatterns(cfg.LogConfig.IncludeEvents); err != nil {
+			return fmt.Errorf("logs: invalid include_events pattern: %w", err)
+		}
+		if err := scraper_internal.ValidateEventPatterns(cfg.LogConfig.ExcludeEvents); err != nil {
+			return fmt.Errorf("logs: invalid exclude_events pattern: %w", err)
+		}
+	}
+
+	names := make(map[string]bool, len(cfg.Instances))
+	for i := range cfg.Instances {
+		inst := &cfg.Instances[i]
+		if err := inst.validate(cfg.ControllerConfig.CollectionInterval); err != nil {
+			return fmt.Errorf("instances[%d]: %w", i, err)
+		}
+		if names[inst.Name] {
+			return fmt.Errorf("instances[%d]: duplicate name %q", i, inst.Name)
 		}
+		names[inst.Name] = true
 	}
 
+	if cfg.CollectionModes.OTLP {
+		if cfg.OTLPConfig == nil {
+			return errors.New("otlp config required when otlp mode enabled")
+		}
+		if cfg.OTLPConfig.Endpoint == "" {
+			return fmt.Errorf("otlp: %w", ErrNoEndpoint)
+		}
+		if cfg.OTLPConfig.MetadataRefreshInterval <= 0 {
+			cfg.OTLPConfig.MetadataRefreshInterval = 5 * time.Minute
+		}
+	}
+
+	return nil
+}
+
+func (inst *InstanceConfig) validate(defaultInterval time.Duration) error {
+	if inst.Name == "" {
+		return errors.New("name must be specified")
+	}
+	if inst.RESTAPIConfig == nil && inst.DatabaseConfig == nil {
+		return fmt.Errorf("instance %q: rest_api or database must be configured", inst.Name)
+	}
+	if inst.RESTAPIConfig != nil {
+		// Logs sources only read from the top-level rest_api block
+		if inst.RESTAPIConfig.InventoryEvents || inst.RESTAPIConfig.FailedTaskLogs || inst.RESTAPIConfig.DAGWarningLogs {
+			return fmt.Errorf("instance %q: rest_api: inventory_events, failed_task_logs and dag_warning_logs are only supported on the top-level rest_api", inst.Name)
+		}
+		if err := inst.RESTAPIConfig.validate(defaultInterval); err != nil {
+			return fmt.Errorf("instance %q: rest_api: %w", inst.Name, err)
+		}
+	}
+	if inst.DatabaseConfig != nil {
+		// The state change listener only reads the top-level database block
+		if inst.DatabaseConfig.StateChanges.Enabled {
+			return fmt.Errorf("instance %q: database: state_changes is only supported on the top-level database", inst.Name)
+		}
+		if err := inst.DatabaseConfig.validate(defaultInterval); err != nil {
+			return fmt.Errorf("instance %q: database: %w", inst.Name, err)
+		}
+	}
+	return nil
+}
+
+// validate applies defaults for a REST API connection, falling back to the
+// receiver's collection interval
+func (c *RESTAPIConfig) validate(defaultInterval time.Duration) error {
+	switch c.AuthMode {
+	case "":
+		c.AuthMode = scraper_internal.AuthModeBasic
+	case scraper_internal.AuthModeBasic, scraper_internal.AuthModeMWAA,
+		scraper_internal.AuthModeAstro, scraper_internal.AuthModeSession:
+	default:
+		return fmt.Errorf("auth_mode must be one of %q, %q, %q or %q",
+			scraper_internal.AuthModeBasic, scraper_internal.AuthModeMWAA,
+			scraper_internal.AuthModeAstro, scraper_internal.AuthModeSession)
+	}
+	if c.AuthMode == scraper_internal.AuthModeSession && (c.Username == "" || c.Password == "") {
+		return errors.New("username and password must be specified for session auth")
+	}
+	if c.BearerToken != "" && c.AuthMode != scraper_internal.AuthModeBasic {
+		return fmt.Errorf("bearer_token cannot be combined with auth_mode %q", c.AuthMode)
+	}
+	if c.Auth.HasValue() {
+		// The extension owns the Authorization header
+		if c.AuthMode != scraper_internal.AuthModeBasic {
+			return fmt.Errorf("auth cannot be combined with auth_mode %q", c.AuthMode)
+		}
+		if c.Username != "" || c.Password != "" || c.BearerToken != "" {
+			return errors.New("auth cannot be combined with username, password or bearer_token")
+		}
+	}
+	if c.AuthMode == scraper_internal.AuthModeAstro {
+		if c.Astro.APIToken == "" {
+			return errors.New("astro.api_token must be specified")
+		}
+		if c.Endpoint == "" && c.Astro.DeploymentURL != "" {
+			c.Endpoint = c.Astro.airflowEndpoint()
+		}
+	}
+	if c.AuthMode == scraper_internal.AuthModeMWAA {
+		if c.MWAA.EnvironmentName == "" {
+			return errors.New("mwaa.environment_name must be specified")
+		}
+		if c.MWAA.Region == "" {
+			return errors.New("mwaa.region must be specified")
+		}
+	} else if c.Endpoint == "" {
+		// MWAA returns the webserver hostname along with the login token
+		return ErrNoEndpoint
+	}
+	if c.CollectionInterval <= 0 {
+		c.CollectionInterval = defaultInterval
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 30 * time.Second
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = 4
+	}
+	if c.ScrapeTimeout <= 0 {
+		c.ScrapeTimeout = c.CollectionInterval
+	}
+	if c.DAGCacheTTL < 0 {
+		return errors.New("dag_cache_ttl cannot be negative")
+	}
+	if c.MappedTaskRawMax < 0 {
+		return errors.New("mapped_task_raw_max_fan_out cannot be negative")
+	}
+	for _, option := range c.ConfigOptions {
+		if section, key, ok := strings.Cut(option, "."); !ok || section == "" || key == "" {
+			return fmt.Errorf("config_options entry %q must be <section>.<key>", option)
+		}
+	}
+	if c.BatchDAGRuns && c.IncrementalRuns {
+		// The batch listing cannot filter on updated_at
+		return errors.New("batch_dag_runs cannot be combined with incremental_runs")
+	}
+	if c.PageSize < 0 || c.MaxDAGRuns < 0 || c.MaxTaskInstances < 0 {
+		return errors.New("page_size, max_dag_runs and max_task_instances cannot be negative")
+	}
+	if c.PageSize == 0 {
+		c.PageSize = scraper_internal.DefaultPageSize
+	}
+	if c.MaxDAGRuns == 0 {
+		c.MaxDAGRuns = scraper_internal.DefaultMaxDAGRuns
+	}
+	if c.MaxTaskInstances == 0 {
+		c.MaxTaskInstances = scraper_internal.DefaultMaxTaskInstances
+	}
+	if c.RequestsPerSecond < 0 {
+		return errors.New("requests_per_second cannot be negative")
+	}
+	if c.RequestsPerSecond > 0 && c.Burst <= 0 {
+		c.Burst = int(math.Ceil(c.RequestsPerSecond))
+	}
+	for _, endpoint := range c.Endpoints {
+		if !scraper_internal.IsKnownEndpoint(endpoint) {
+			return fmt.Errorf("unknown endpoint %q (valid: %v)", endpoint, scraper_internal.AllEndpoints())
+		}
+	}
+	return nil
+}
+
+// validate applies defaults for a metadata database connection, falling back
+// to the receiver's collection interval
+func (c *DatabaseConfig) validate(defaultInterval time.Duration) error {
+	host, err := resolvePostgresHost(c.Host, c.SocketDir)
+	if err != nil {
+		return err
+	}
+	c.Host = host
+	if c.CollectionInterval <= 0 {
+		c.CollectionInterval = defaultInterval
+	}
+	if c.Port == 0 {
+		c.Port = 5432
+	}
+	if err := validatePostgresAuth(&c.AuthMode, &c.SSLMode, c.Host, c.Password, c.RDSIAM); err != nil {
+		return err
+	}
+	if c.SSLMode == "" {
+		c.SSLMode = "disable"
+	}
+	if err := validateClientCert(c.SSLCert, c.SSLKey); err != nil {
+		return err
+	}
+	if c.QueryTimeout <= 0 {
+		c.QueryTimeout = 15 * time.Second
+	}
+	if c.MaxOpenConns <= 0 {
+		c.MaxOpenConns = 10
+	}
+	if c.MaxIdleConns <= 0 {
+		c.MaxIdleConns = 5
+	}
+	if c.MaxIdleConns > c.MaxOpenConns {
+		return errors.New("max_idle_conns cannot exceed max_open_conns")
+	}
+	if c.ConnMaxLifetime <= 0 {
+		c.ConnMaxLifetime = 5 * time.Minute
+	}
+	if c.ConnMaxIdleTime <= 0 {
+		c.ConnMaxIdleTime = time.Minute
+	}
+	if c.StatementTimeout < 0 {
+		return errors.New("statement_timeout cannot be negative")
+	}
+	if c.StatementTimeout == 0 {
+		c.StatementTimeout = c.QueryTimeout
+	}
+	if c.ApplicationName == "" {
+		c.ApplicationName = scraper_internal.DefaultApplicationName
+	}
+	if c.OrphanedTaskThreshold < 0 {
+		return errors.New("orphaned_task_threshold cannot be negative")
+	}
+	if c.OrphanedTaskThreshold == 0 {
+		c.OrphanedTaskThreshold = time.Hour
+	}
+	if err := scraper_internal.ValidateEventPatterns(c.OrphanedTaskExcludeDAGs); err != nil {
+		return fmt.Errorf("orphaned_task_exclude_dags: %w", err)
+	}
+	if c.RetentionPeriod < 0 {
+		return errors.New("retention_period cannot be negative")
+	}
+	for _, p := range c.DurationPercentiles {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("duration_percentiles: %v is not between 0 and 1", p)
+		}
+	}
+	if c.DurationHistograms && len(c.DurationPercentiles) > 0 {
+		return errors.New("duration_percentiles cannot be combined with duration_histograms")
+	}
+	for i := range c.CustomQueries {
+		if err := c.CustomQueries[i].validate(); err != nil {
+			return fmt.Errorf("custom_queries[%d]: %w", i, err)
+		}
+	}
+	if err := c.ReadReplica.validate(c.Port); err != nil {
+		return fmt.Errorf("read_replica: %w", err)
+	}
+	if c.AuthMode == scraper_internal.DBAuthModeRDSIAM && len(c.ReadReplica.Hosts) > 1 {
+		return errors.New("rds_iam auth supports a single read_replica host")
+	}
+	if err := c.StateChanges.validate(); err != nil {
+		return fmt.Errorf("state_changes: %w", err)
+	}
+	return nil
+}
+
+// validate applies the state change defaults when enabled
+func (c *StateChangesConfig) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	switch c.Mode {
+	case "", scraper_internal.StateChangeModeNotify:
+		c.Mode = scraper_internal.StateChangeModeNotify
+		if c.Publication != "" || c.Slot != "" || c.TemporarySlot {
+			return errors.New("publication, slot and temporary_slot require logical_replication mode")
+		}
+		if c.Channel == "" {
+			c.Channel = scraper_internal.DefaultStateChannel
+		}
+		if err := scraper_internal.ValidateStateIdentifier(c.Channel); err != nil {
+			return fmt.Errorf("channel: %w", err)
+		}
+	case scraper_internal.StateChangeModeLogicalReplication:
+		if c.Channel != "" || c.InstallTrigger {
+			return errors.New("channel and install_trigger require notify mode")
+		}
+		if c.Publication == "" {
+			c.Publication = scraper_internal.DefaultStatePublication
+		}
+		if err := scraper_internal.ValidateStateIdentifier(c.Publication); err != nil {
+			return fmt.Errorf("publication: %w", err)
+		}
+		if c.Slot == "" {
+			c.Slot = scraper_internal.DefaultStateSlot
+		}
+		if err := scraper_internal.ValidateStateIdentifier(c.Slot); err != nil {
+			return fmt.Errorf("slot: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported mode %q (valid: %s, %s)", c.Mode,
+			scraper_internal.StateChangeModeNotify, scraper_internal.StateChangeModeLogicalReplication)
+	}
+	if c.FlushInterval < 0 {
+		return errors.New("flush_interval cannot be negative")
+	}
+	if c.FlushInterval == 0 {
+		c.FlushInterval = time.Second
+	}
+	return nil
+}
+
+// validate normalizes hosts to host:port, using the primary's port when a
+// host has none
+func (r *ReadReplicaConfig) validate(defaultPort int) error {
+	if len(r.Hosts) == 0 {
+		if r.TargetSessionAttrs != "" {
+			return errors.New("target_session_attrs requires hosts")
+		}
+		return nil
+	}
+	for i, host := range r.Hosts {
+		if host == "" {
+			return fmt.Errorf("hosts[%d] must not be empty", i)
+		}
+		if h, p, err := net.SplitHostPort(host); err == nil {
+			if _, err := strconv.Atoi(p); err != nil {
+				return fmt.Errorf("hosts[%d]: invalid port %q", i, p)
+			}
+			r.Hosts[i] = net.JoinHostPort(h, p)
+		} else {
+			r.Hosts[i] = net.JoinHostPort(host, strconv.Itoa(defaultPort))
+		}
+	}
+	switch r.TargetSessionAttrs {
+	case "":
+		r.TargetSessionAttrs = "prefer-standby"
+	case "any", "read-write", "read-only", "primary", "standby", "prefer-standby":
+	default:
+		return fmt.Errorf("unsupported target_session_attrs %q", r.TargetSessionAttrs)
+	}
+	return nil
+}
+
+// validatePostgresAuth checks auth_mode and its settings. RDS only accepts IAM
+// auth tokens over TLS, so ssl_mode defaults to require for rds_iam.
+func validatePostgresAuth(authMode, sslMode *string, host string, password configopaque.String, rds RDSIAMConfig) error {
+	switch *authMode {
+	case "":
+		*authMode = scraper_internal.DBAuthModePassword
+	case scraper_internal.DBAuthModePassword, scraper_internal.DBAuthModeRDSIAM:
+	default:
+		return fmt.Errorf("auth_mode must be %q or %q", scraper_internal.DBAuthModePassword, scraper_internal.DBAuthModeRDSIAM)
+	}
+	if *authMode != scraper_internal.DBAuthModeRDSIAM {
+		return nil
+	}
+	if rds.Region == "" {
+		return errors.New("rds_iam.region must be specified")
+	}
+	if password != "" {
+		return errors.New("password cannot be combined with rds_iam auth")
+	}
+	if strings.HasPrefix(host, "/") {
+		return errors.New("rds_iam auth requires a TCP host")
+	}
+	switch *sslMode {
+	case "":
+		*sslMode = "require"
+	case "disable", "allow":
+		return fmt.Errorf("rds_iam auth requires TLS, not ssl_mode %q", *sslMode)
+	}
+	return nil
+}
+
+// validateMetricOwners checks that each family is known and owned by the
+// REST API or the database
+func validateMetricOwners(owners map[string]string) error {
+	families := scraper_internal.MetricFamilies()
+	for family, owner := range owners {
+		known := false
+		for _, f := range families {
+			known = known || f == family
+		}
+		if !known {
+			return fmt.Errorf("unknown metric family %q (expected one of %s)", family, strings.Join(families, ", "))
+		}
+		if owner != scraper_internal.MetricOwnerRESTAPI && owner != scraper_internal.MetricOwnerDatabase {
+			return fmt.Errorf("%s: owner must be %q or %q", family,
+				scraper_internal.MetricOwnerRESTAPI, scraper_internal.MetricOwnerDatabase)
+		}
+	}
+	return nil
+}
+
+// resolvePostgresHost returns the host to connect to. A socket_dir, or a host
+// that is an absolute path, connects over the Unix socket in that directory.
+func resolvePostgresHost(host, socketDir string) (string, error) {
+	switch {
+	case host != "" && socketDir != "":
+		return "", errors.New("host and socket_dir cannot both be set")
+	case socketDir != "":
+		if !strings.HasPrefix(socketDir, "/") {
+			return "", errors.New("socket_dir must be an absolute path")
+		}
+		return socketDir, nil
+	case host == "":
+		return "", errors.New("host or socket_dir must be specified")
+	}
+	return host, nil
+}
+
+// validateClientCert ensures a client certificate and its key are configured together
+func validateClientCert(cert, key string) error {
+	if (cert == "") != (key == "") {
+		return errors.New("ssl_cert and ssl_key must be set together")
+	}
+	return nil
+}
+
+func (q *CustomQuery) validate() error {
+	if q.SQL == "" {
+		return errors.New("sql must be specified")
+	}
+	if len(q.Metrics) == 0 {
+		return errors.New("at least one metric must be specified")
+	}
+	for i := range q.Metrics {
+		m := &q.Metrics[i]
+		if m.MetricName == "" {
+			return fmt.Errorf("metrics[%d]: metric_name must be specified", i)
+		}
+		if m.ValueColumn == "" {
+			return fmt.Errorf("metrics[%d]: value_column must be specified", i)
+		}
+		switch m.DataType {
+		case "":
+			m.DataType = scraper_internal.CustomDataTypeGauge
+		case scraper_internal.CustomDataTypeGauge, scraper_internal.CustomDataTypeSum:
+		default:
+			return fmt.Errorf("metrics[%d]: unsupported data_type %q (expected gauge or sum)", i, m.DataType)
+		}
+		switch m.ValueType {
+		case "":
+			m.ValueType = scraper_internal.CustomValueTypeDouble
+		case scraper_internal.CustomValueTypeInt, scraper_internal.CustomValueTypeDouble:
+		default:
+			return fmt.Errorf("metrics[%d]: unsupported value_type %q (expected int or double)", i, m.ValueType)
+		}
+		if m.Monotonic && m.DataType != scraper_internal.CustomDataTypeSum {
+			return fmt.Errorf("metrics[%d]: monotonic requires data_type sum", i)
+		}
+		switch m.Merge {
+		case "":
+		case scraper_internal.GaugeMergeSum, scraper_internal.GaugeMergeMax, scraper_internal.GaugeMergeMin:
+			if m.DataType != scraper_internal.CustomDataTypeGauge {
+				return fmt.Errorf("metrics[%d]: merge requires data_type gauge", i)
+			}
+		default:
+			return fmt.Errorf("metrics[%d]: unsupported merge %q (expected sum, max or min)", i, m.Merge)
+		}
+	}
 	return nil
 }
@@ -6,6 +6,8 @@ package airflowreceiver
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
@@ -13,6 +15,7 @@ import (
 	"go.opentelemetry.io/collector/scraper"
 	"go.opentelemetry.io/collector/scraper/scraperhelper"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 	
 	scraper_internal "github.com/npcomplete777/airflowreceiver/internal/scraper"
 )
@@ -21,6 +24,78 @@ const typeStr = "airflow"
 
 var typeVal = component.MustNewType(typeStr)
 
+// dropTrackers shares one drop tracker between the metrics and logs receivers
+// created for the same component ID, so airflow.receiver.dropped covers both
+var dropTrackers = struct {
+	sync.Mutex
+	byID map[component.ID]*scraper_internal.DropTracker
+}{byID: make(map[component.ID]*scraper_internal.DropTracker)}
+
+// restLimiters shares one REST API token bucket between the metrics and logs
+// receivers created for the same component ID and instance
+var restLimiters = struct {
+	sync.Mutex
+	byKey map[instanceKey]*rate.Limiter
+}{byKey: make(map[instanceKey]*rate.Limiter)}
+
+// airflowInfos shares the version and executor of each instance between the
+// metrics and logs receivers created for the same component ID
+var airflowInfos = struct {
+	sync.Mutex
+	byKey map[instanceKey]*scraper_internal.AirflowInfo
+}{byKey: make(map[instanceKey]*scraper_internal.AirflowInfo)}
+
+// instanceKey identifies one Airflow instance of a receiver component. The
+// top-level configuration is instance "".
+type instanceKey struct {
+	id       component.ID
+	instance string
+}
+
+func getDropTracker(settings receiver.Settings) *scraper_internal.DropTracker {
+	dropTrackers.Lock()
+	defer dropTrackers.Unlock()
+	
+	drops, ok := dropTrackers.byID[settings.ID]
+	if !ok {
+		drops = scraper_internal.NewDropTracker(settings.Logger)
+		dropTrackers.byID[settings.ID] = drops
+	}
+	return drops
+}
+
+// getRESTLimiter returns the shared token bucket of an instance's webserver,
+// or nil when rate limiting is off. The top-level rest_api is instance "".
+func getRESTLimiter(settings receiver.Settings, instance string, cfg *RESTAPIConfig) *rate.Limiter {
+	if cfg == nil {
+		return nil
+	}
+	
+	restLimiters.Lock()
+	defer restLimiters.Unlock()
+	
+	key := instanceKey{id: settings.ID, instance: instance}
+	limiter, ok := restLimiters.byKey[key]
+	if !ok {
+		limiter = scraper_internal.NewRateLimiter(cfg.RequestsPerSecond, cfg.Burst)
+		restLimiters.byKey[key] = limiter
+	}
+	return limiter
+}
+
+func getAirflowInfo(settings receiver.Settings, instance string) *scraper_internal.AirflowInfo {
+	airflowInfos.Lock()
+	defer airflowInfos.Unlock()
+	
+	key := instanceKey{id: settings.ID, instance: instance}
+	info, ok := airflowInfos.byKey[key]
+	if !ok {
+		info = scraper_internal.NewAirflowInfo()
+		airflowInfos.byKey[key] = info
+	}
+	return info
+}
+
 func NewFactory() receiver.Factory {
 	return receiver.NewFactory(
 		typeVal,
@@ -46,53 +121,65 @@ func createMetricsReceiver(
 	consumer consumer.Metrics,
 ) (receiver.Metrics, error) {
 	rCfg := cfg.(*Config)
+	drops := getDropTracker(settings)
 	
-	opts := make([]scraperhelper.ControllerOption, 0, 3)
+	// Scraper health is reported to the healthcheck extension as one status
+	status := scraper_internal.NewStatusReporter()
 	
-	// REST API scraper
-	if rCfg.CollectionModes.RESTAPI {
-		settings.Logger.Info("Enabling REST API scraper")
-		
-		restCfg := &scraper_internal.RESTAPIConfig{
-			Endpoint:           rCfg.RESTAPIConfig.Endpoint,
-			Username:           rCfg.RESTAPIConfig.Username,
-			Password:           string(rCfg.RESTAPIConfig.Password),
-			CollectionInterval: rCfg.RESTAPIConfig.CollectionInterval,
-			IncludePastRuns:    rCfg.RESTAPIConfig.IncludePastRuns,
-			PastRunsLookback:   rCfg.RESTAPIConfig.PastRunsLookback,
+	// Scrapers with the same name and interval share a controller, so every
+	// instance of a mode is scraped on the same tick
+	type scraperGroup struct {
+		name     string
+		interval time.Duration
+		scrapers []scraper.Metrics
+	}
+	var groups []*scraperGroup
+	addScraper := func(name string, interval time.Duration, sc scraper.Metrics) {
+		for _, g := range groups {
+			if g.name == name && g.interval == interval {
+				g.scrapers = append(g.scrapers, sc)
+				return
+			}
 		}
-		
-		scraperInstance := scraper_internal.NewRESTAPIScraper(restCfg, settings)
-		sc, err := scraper.NewMetrics(scraperInstance.Scrape)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create REST API scraper: %w", err)
+		groups = append(groups, &scraperGroup{name: name, interval: interval, scrapers: []scraper.Metrics{sc}})
+	}
+	
+	// The top-level rest_api and database blocks are the unnamed instance
+	instances := make([]metricsInstance, 0, len(rCfg.Instances)+1)
+	if rCfg.CollectionModes.RESTAPI || rCfg.CollectionModes.Database {
+		inst := metricsInstance{info: getAirflowInfo(settings, "")}
+		if rCfg.CollectionModes.RESTAPI {
+			inst.rest = rCfg.RESTAPIConfig
 		}
-		
-		opts = append(opts, scraperhelper.AddScraper(component.MustNewType("airflow_rest"), sc))
+		if rCfg.CollectionModes.Database {
+			inst.db = rCfg.DatabaseConfig
+		}
+		instances = append(instances, inst)
+	}
+	for _, instCfg := range rCfg.Instances {
+		instances = append(instances, metricsInstance{
+			name:               instCfg.Name,
+			resourceAttributes: instCfg.ResourceAttributes,
+			rest:               instCfg.RESTAPIConfig,
+			db:                 instCfg.DatabaseConfig,
+			info:               getAirflowInfo(settings, instCfg.Name),
+		})
 	}
 	
-	// Database scraper with wrapper
-	if rCfg.CollectionModes.Database {
-		settings.Logger.Info("Enabling Database scraper")
-		
-		dbCfg := &scraper_internal.DatabaseConfig{
-			Host:               rCfg.DatabaseConfig.Host,
-			Port:               rCfg.DatabaseConfig.Port,
-			Database:           rCfg.DatabaseConfig.Database,
-			Username:           rCfg.DatabaseConfig.Username,
-			Password:           string(rCfg.DatabaseConfig.Password),
-			SSLMode:            rCfg.DatabaseConfig.SSLMode,
-			CollectionInterval: rCfg.DatabaseConfig.CollectionInterval,
+	for _, inst := range instances {
+		warnMetricOverlap(settings.Logger, rCfg, inst)
+		if inst.rest != nil {
+			settings.Logger.Info("Enabling REST API scraper", zap.String("instance", inst.name))
+			if err := addRESTScrapers(settings, rCfg, inst, status, drops, addScraper); err != nil {
+				return nil, err
+			}
 		}
-		
-		dbScraper := scraper_internal.NewDatabaseScraper(dbCfg, settings)
-		wrapper := scraper_internal.NewDatabaseScraperWrapper(dbScraper)
-		sc, err := scraper.NewMetrics(wrapper.Scrape)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create database scraper: %w", err)
+		if inst.db != nil {
+			settings.Logger.Info("Enabling Database scraper", zap.String("instance", inst.name))
+			if err := addDatabaseScraper(settings, rCfg, inst, status, drops, addScraper); err != nil {
+				return nil, err
+			}
 		}
-		
-		opts = append(opts, scraperhelper.AddScraper(component.MustNewType("airflow_db"), sc))
 	}
 	
 	// StatsD scraper
@@ -101,30 +188,162 @@ func createMetricsReceiver(
 		
 		statsdCfg := &scraper_internal.StatsDConfig{
 			Endpoint:            rCfg.StatsDConfig.Endpoint,
+			Transport:           string(rCfg.StatsDConfig.Transport),
 			AggregationInterval: rCfg.StatsDConfig.AggregationInterval,
+			Prefix:              rCfg.StatsDConfig.Prefix,
+			StripPrefix:         rCfg.StatsDConfig.StripPrefix,
+			Rename:              rCfg.StatsDConfig.Rename,
+			ReadBufferSize:      rCfg.StatsDConfig.ReadBufferSize,
+			SocketBufferSize:    rCfg.StatsDConfig.SocketBufferSize,
+			ReadBatchSize:       rCfg.StatsDConfig.ReadBatchSize,
+			ParserWorkers:       rCfg.StatsDConfig.ParserWorkers,
+			DisabledAttributes:  rCfg.disabledAttributes(),
+			Redactor:            rCfg.Redaction.redactor(),
+			ResourcePerDAG:      rCfg.ResourcePerDAG,
 		}
 		
-		scraperInstance := scraper_internal.NewStatsDScraper(statsdCfg, settings)
-		sc, err := scraper.NewMetrics(scraperInstance.Scrape)
+		// StatsD comes from the top-level deployment, so it shares its metadata
+		topLevel := metricsInstance{info: getAirflowInfo(settings, "")}
+		scraperInstance := scraper_internal.NewStatsDScraper(statsdCfg, settings, drops)
+		sc, err := scraper.NewMetrics(
+			topLevel.wrap(scraperInstance.Scrape),
+			scraper.WithStart(scraperInstance.Start),
+			scraper.WithShutdown(scraperInstance.Shutdown),
+		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create StatsD scraper: %w", err)
 		}
 		
-		opts = append(opts, scraperhelper.AddScraper(component.MustNewType("airflow_statsd"), sc))
+		addScraper("airflow_statsd", rCfg.StatsDConfig.AggregationInterval, sc)
 	}
 	
-	if len(opts) == 0 {
-		return nil, fmt.Errorf("no data collection modes enabled")
+	components := make([]component.Component, 0, len(groups)+2)
+	if len(groups) > 0 {
+		// Receiver self-telemetry
+		selfScraper := scraper_internal.NewReceiverScraper(drops, settings)
+		sc, err := scraper.NewMetrics(selfScraper.Scrape)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create receiver self-telemetry scraper: %w", err)
+		}
+		addScraper("airflow_receiver", 0, sc)
+		
+		// Each group runs on its own controller so per-mode intervals are honored
+		for _, g := range groups {
+			controllerCfg := rCfg.ControllerConfig
+			if g.interval > 0 {
+				controllerCfg.CollectionInterval = g.interval
+			}
+			
+			options := make([]scraperhelper.ControllerOption, 0, len(g.scrapers))
+			for _, sc := range g.scrapers {
+				options = append(options, scraperhelper.AddScraper(component.MustNewType(g.name), sc))
+			}
+			controller, err := scraperhelper.NewMetricsController(&controllerCfg, settings, consumer, options...)
+			if err != nil {
+				return nil, err
+			}
+			
+			settings.Logger.Info("Scheduling Airflow scraper",
+				zap.String("scraper", g.name),
+				zap.Int("instances", len(g.scrapers)),
+				zap.Duration("interval", controllerCfg.CollectionInterval))
+			components = append(components, controller)
+		}
+		
+		settings.Logger.Info("Creating Airflow receiver", zap.Int("scraper_count", len(components)))
 	}
 	
-	settings.Logger.Info("Creating Airflow receiver", zap.Int("scraper_count", len(opts)))
+	// OTLP passthrough for Airflow's native OpenTelemetry metrics
+	if rCfg.CollectionModes.OTLP {
+		settings.Logger.Info("Enabling OTLP passthrough")
+		
+		// The REST API, when configured, supplies version and DAG tag metadata
+		var rest *scraper_internal.RESTAPIScraper
+		if rCfg.RESTAPIConfig != nil && rCfg.RESTAPIConfig.Endpoint != "" {
+			restCfg := newRESTAPIScraperConfig(rCfg.RESTAPIConfig)
+			restCfg.Limiter = getRESTLimiter(settings, "", rCfg.RESTAPIConfig)
+			rest = scraper_internal.NewRESTAPIScraper(restCfg, settings, drops)
+		}
+		enricher := scraper_internal.NewOTLPEnricher(&scraper_internal.OTLPEnricherConfig{
+			InstanceName:    rCfg.OTLPConfig.InstanceName,
+			RefreshInterval: rCfg.OTLPConfig.MetadataRefreshInterval,
+		}, rest, settings)
+		
+		components = append(components, newOTLPReceiver(settings, rCfg.OTLPConfig, enricher, consumer))
+	}
 	
-	return scraperhelper.NewMetricsController(
-		&rCfg.ControllerConfig,
-		settings,
-		consumer,
-		opts...,
-	)
+	if len(components) == 0 {
+		return nil, fmt.Errorf("no data collection modes enabled")
+	}
+	
+	return &metricsReceiver{components: components}, nil
+}
+
+func newRESTAPIScraperConfig(cfg *RESTAPIConfig) *scraper_internal.RESTAPIConfig {
+	var mwaa *scraper_internal.MWAAConfig
+	if cfg.AuthMode == scraper_internal.AuthModeMWAA {
+		mwaa = &scraper_internal.MWAAConfig{
+			EnvironmentName: cfg.MWAA.EnvironmentName,
+			Region:          cfg.MWAA.Region,
+			RoleARN:         cfg.MWAA.RoleARN,
+		}
+	}
+	bearerToken := string(cfg.BearerToken)
+	if cfg.AuthMode == scraper_internal.AuthModeAstro {
+		bearerToken = string(cfg.Astro.APIToken)
+	}
+	return &scraper_internal.RESTAPIConfig{
+		ClientConfig:               cfg.ClientConfig,
+		Endpoint:                   cfg.Endpoint,
+		Username:                   cfg.Username,
+		Password:                   string(cfg.Password),
+		CollectionInterval:         cfg.CollectionInterval,
+		IncludePastRuns:            cfg.IncludePastRuns,
+		PastRunsLookback:           cfg.PastRunsLookback,
+		Endpoints:                  cfg.Endpoints,
+		Concurrency:                cfg.Concurrency,
+		ScrapeTimeout:              cfg.ScrapeTimeout,
+		DAGCacheTTL:                cfg.DAGCacheTTL,
+		IncrementalRuns:            cfg.IncrementalRuns,
+		TaskMetadata:               cfg.TaskMetadata,
+		MWAA:                       mwaa,
+		BearerToken:                bearerToken,
+		SessionLogin:               cfg.AuthMode == scraper_internal.AuthModeSession,
+		MappedTaskRawMaxFanOut:     cfg.MappedTaskRawMax,
+		WorkerHostname:             cfg.WorkerHostname,
+		DisableResponseCompression: cfg.DisableResponseCompression,
+		PageSize:                   cfg.PageSize,
+		MaxDAGRuns:                 cfg.MaxDAGRuns,
+		MaxTaskInstances:           cfg.MaxTaskInstances,
+		BatchDAGRuns:               cfg.BatchDAGRuns,
+		BatchTaskInstances:         cfg.BatchTaskInstances,
+		ConfigOptions:              cfg.ConfigOptions,
+		DisableFieldProjection:     cfg.DisableFieldProjection,
+		OnlyActiveDAGs:             cfg.OnlyActiveDAGs,
+		ExcludePausedDAGs:          cfg.ExcludePausedDAGs,
+	}
+}
+
+func newCustomQueries(queries []CustomQuery) []scraper_internal.CustomQuery {
+	out := make([]scraper_internal.CustomQuery, 0, len(queries))
+	for _, q := range queries {
+		metrics := make([]scraper_internal.CustomQueryMetric, 0, len(q.Metrics))
+		for _, m := range q.Metrics {
+			metrics = append(metrics, scraper_internal.CustomQueryMetric{
+				MetricName:       m.MetricName,
+				ValueColumn:      m.ValueColumn,
+				AttributeColumns: m.AttributeColumns,
+				Unit:             m.Unit,
+				Description:      m.Description,
+				DataType:         m.DataType,
+				ValueType:        m.ValueType,
+				Monotonic:        m.Monotonic,
+				Merge:            m.Merge,
+			})
+		}
+		out = append(out, scraper_internal.CustomQuery{SQL: q.SQL, Metrics: metrics})
+	}
+	return out
 }
 
 func createLogsReceiver(
@@ -134,16 +353,46 @@ func createLogsReceiver(
 	consumer consumer.Logs,
 ) (receiver.Logs, error) {
 	rCfg := cfg.(*Config)
+	drops := getDropTracker(settings)
+	r := newLogsReceiver(settings, consumer, rCfg.StrictStartup, getAirflowInfo(settings, ""))
+	r.redactor = rCfg.Redaction.redactor()
+	r.controllerCfg = rCfg.ControllerConfig
+	r.drops = drops
 	
-	if !rCfg.CollectionModes.Logs {
-		return nil, fmt.Errorf("logs collection mode not enabled")
+	restLimiter := getRESTLimiter(settings, "", rCfg.RESTAPIConfig)
+	
+	if rCfg.CollectionModes.Logs {
+		if rCfg.LogConfig == nil {
+			return nil, fmt.Errorf("logs config is required when logs mode is enabled")
+		}
+		if rCfg.LogConfig.Source == scraper_internal.LogSourceRESTAPI {
+			r.addRESTEventLogSource(rCfg.RESTAPIConfig, rCfg.LogConfig, restLimiter, drops)
+		} else {
+			r.addEventLogSource(rCfg.LogConfig, drops)
+		}
 	}
 	
-	if rCfg.LogConfig == nil {
-		return nil, fmt.Errorf("logs config is required when logs mode is enabled")
+	if rCfg.CollectionModes.RESTAPI && rCfg.RESTAPIConfig.InventoryEvents {
+		r.addInventorySource(rCfg.RESTAPIConfig, restLimiter, drops)
+	}
+	
+	if rCfg.CollectionModes.RESTAPI && rCfg.RESTAPIConfig.DAGWarningLogs {
+		r.addDAGWarningSource(rCfg.RESTAPIConfig, restLimiter, drops)
+	}
+	
+	if rCfg.CollectionModes.RESTAPI && rCfg.RESTAPIConfig.FailedTaskLogs {
+		r.addFailedTaskLogSource(rCfg.RESTAPIConfig, restLimiter, drops)
+	}
+	
+	if rCfg.CollectionModes.Database && rCfg.DatabaseConfig.StateChanges.Enabled {
+		r.addStateChangeSource(rCfg.DatabaseConfig, drops)
+	}
+	
+	if len(r.sources) == 0 {
+		return nil, fmt.Errorf("logs collection mode not enabled")
 	}
 	
 	settings.Logger.Info("Creating Airflow logs receiver")
 	
-	return newLogsReceiver(settings, rCfg.LogConfig, consumer)
+	return r, nil
 }
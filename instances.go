@@ -0,0 +1,217 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package airflowreceiver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/scraper"
+	"go.uber.org/zap"
+
+	scraper_internal "github.com/npcomplete777/airflowreceiver/internal/scraper"
+)
+
+// instanceNameAttribute distinguishes the resources of each configured instance
+const instanceNameAttribute = "airflow.instance.name"
+
+// metricsInstance is one Airflow deployment to scrape: the top-level rest_api
+// and database blocks (with an empty name) or an entry of instances
+type metricsInstance struct {
+	name               string
+	resourceAttributes map[string]string
+	rest               *RESTAPIConfig
+	db                 *DatabaseConfig
+	// info is the version and executor loaded by the instance's REST scraper
+	info *scraper_internal.AirflowInfo
+}
+
+// scraperName qualifies a scraper type with the instance name so health
+// metrics and component status stay separate per instance
+func (inst metricsInstance) scraperName(base string) string {
+	if inst.name == "" {
+		return base
+	}
+	return base + "." + inst.name
+}
+
+// wrap labels every resource an instance's scraper emits with the instance
+// name and the deployment metadata
+func (inst metricsInstance) wrap(scrape scraper.ScrapeMetricsFunc) scraper.ScrapeMetricsFunc {
+	return func(ctx context.Context) (pmetric.Metrics, error) {
+		md, err := scrape(ctx)
+		if inst.name != "" {
+			rms := md.ResourceMetrics()
+			for i := 0; i < rms.Len(); i++ {
+				attrs := rms.At(i).Resource().Attributes()
+				attrs.PutStr(instanceNameAttribute, inst.name)
+				for k, v := range inst.resourceAttributes {
+					attrs.PutStr(k, v)
+				}
+			}
+		}
+		inst.info.ApplyMetrics(md)
+		return md, err
+	}
+}
+
+func addRESTScrapers(
+	settings receiver.Settings,
+	rCfg *Config,
+	inst metricsInstance,
+	status *scraper_internal.StatusReporter,
+	drops *scraper_internal.DropTracker,
+	addScraper func(name string, interval time.Duration, sc scraper.Metrics),
+) error {
+	// One token bucket for every scraper talking to the webserver
+	limiter := getRESTLimiter(settings, inst.name, inst.rest)
+	
+	restCfg := newRESTAPIScraperConfig(inst.rest)
+	restCfg.Name = inst.scraperName("rest_api")
+	restCfg.Limiter = limiter
+	restCfg.Status = status
+	restCfg.StrictStartup = rCfg.StrictStartup
+	restCfg.SummaryLogInterval = rCfg.ScrapeSummaryLogInterval
+	restCfg.OutputMode = rCfg.OutputMode
+	restCfg.DisabledAttributes = rCfg.disabledAttributes()
+	restCfg.Redactor = rCfg.Redaction.redactor()
+	restCfg.ResourcePerDAG = rCfg.ResourcePerDAG
+	if inst.db != nil {
+		restCfg.ExcludeMetrics = scraper_internal.ExcludedMetrics(scraper_internal.MetricOwnerRESTAPI, rCfg.MetricOwners)
+	}
+	restCfg.Info = inst.info
+	
+	// Health checks can run more often than the heavier DAG scrape
+	if inst.rest.HealthCheckInterval > 0 && restCfg.HasEndpoint(scraper_internal.EndpointHealth) {
+		healthCfg := newRESTAPIScraperConfig(inst.rest)
+		healthCfg.Name = inst.scraperName("rest_api_health")
+		healthCfg.Limiter = limiter
+		healthCfg.Status = status
+		healthCfg.StrictStartup = rCfg.StrictStartup
+		healthCfg.SummaryLogInterval = rCfg.ScrapeSummaryLogInterval
+		healthCfg.Info = inst.info
+		healthCfg.Endpoints = []string{scraper_internal.EndpointHealth}
+		restCfg.Endpoints = restCfg.EndpointsExcept(scraper_internal.EndpointHealth)
+		
+		healthScraper := scraper_internal.NewRESTAPIScraper(healthCfg, settings, drops)
+		sc, err := scraper.NewMetrics(
+			inst.wrap(healthScraper.Scrape),
+			scraper.WithStart(healthScraper.Start),
+			scraper.WithShutdown(healthScraper.Shutdown),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create REST API health scraper: %w", err)
+		}
+		addScraper("airflow_rest_health", inst.rest.HealthCheckInterval, sc)
+	}
+	
+	scraperInstance := scraper_internal.NewRESTAPIScraper(restCfg, settings, drops)
+	sc, err := scraper.NewMetrics(
+		inst.wrap(scraperInstance.Scrape),
+		scraper.WithStart(scraperInstance.Start),
+		scraper.WithShutdown(scraperInstance.Shutdown),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create REST API scraper: %w", err)
+	}
+	addScraper("airflow_rest", inst.rest.CollectionInterval, sc)
+	return nil
+}
+
+// dbExcludedMetrics returns the metric families the REST API owns, when the
+// instance scrapes both
+func dbExcludedMetrics(rCfg *Config, inst metricsInstance) []string {
+	if inst.rest == nil {
+		return nil
+	}
+	return scraper_internal.ExcludedMetrics(scraper_internal.MetricOwnerDatabase, rCfg.MetricOwners)
+}
+
+// warnMetricOverlap lists the metric families an instance reports twice
+// because both its REST API and database scrapers emit them
+func warnMetricOverlap(logger *zap.Logger, rCfg *Config, inst metricsInstance) {
+	if inst.rest == nil || inst.db == nil {
+		return
+	}
+	for _, family := range scraper_internal.MetricFamilies() {
+		if _, owned := rCfg.MetricOwners[family]; owned {
+			continue
+		}
+		restAPI, database := scraper_internal.MetricFamilyMetrics(family)
+		logger.Warn("REST API and database scrapers both report a metric family; set metric_owners to keep one",
+			zap.String("instance", inst.name),
+			zap.String("family", family),
+			zap.Strings("rest_api_metrics", restAPI),
+			zap.Strings("database_metrics", database))
+	}
+}
+
+func addDatabaseScraper(
+	settings receiver.Settings,
+	rCfg *Config,
+	inst metricsInstance,
+	status *scraper_internal.StatusReporter,
+	drops *scraper_internal.DropTracker,
+	addScraper func(name string, interval time.Duration, sc scraper.Metrics),
+) error {
+	dbCfg := &scraper_internal.DatabaseConfig{
+		PostgresConnConfig: scraper_internal.PostgresConnConfig{
+			Host:        inst.db.Host,
+			Port:        inst.db.Port,
+			Database:    inst.db.Database,
+			Username:    inst.db.Username,
+			Password:    string(inst.db.Password),
+			SSLMode:     inst.db.SSLMode,
+			SSLRootCert: inst.db.SSLRootCert,
+			SSLCert:     inst.db.SSLCert,
+			SSLKey:      inst.db.SSLKey,
+			// Server-side backstop for the client-side query deadline
+			StatementTimeout: inst.db.StatementTimeout,
+			ApplicationName:  inst.db.ApplicationName,
+			RDSIAM:           inst.db.RDSIAM.internal(inst.db.AuthMode),
+			// Aggregation queries go to the replicas when configured
+			Hosts:              inst.db.ReadReplica.Hosts,
+			TargetSessionAttrs: inst.db.ReadReplica.TargetSessionAttrs,
+		},
+		Name:               inst.scraperName("database"),
+		SummaryLogInterval: rCfg.ScrapeSummaryLogInterval,
+		ExcludeMetrics:     dbExcludedMetrics(rCfg, inst),
+		DisabledAttributes: rCfg.disabledAttributes(),
+		Redactor:           rCfg.Redaction.redactor(),
+		ResourcePerDAG:     rCfg.ResourcePerDAG,
+		CollectionInterval: inst.db.CollectionInterval,
+		QueryTimeout:       inst.db.QueryTimeout,
+		MaxOpenConns:       inst.db.MaxOpenConns,
+		MaxIdleConns:       inst.db.MaxIdleConns,
+		ConnMaxLifetime:    inst.db.ConnMaxLifetime,
+		ConnMaxIdleTime:    inst.db.ConnMaxIdleTime,
+		CacheStatements:    inst.db.CacheStatements,
+		CustomQueries:      newCustomQueries(inst.db.CustomQueries),
+		
+		OrphanedTaskThreshold:   inst.db.OrphanedTaskThreshold,
+		OrphanedTaskExcludeDAGs: inst.db.OrphanedTaskExcludeDAGs,
+		TableStats:              inst.db.TableStats,
+		TableStatsTables:        inst.db.TableStatsTables,
+		RetentionPeriod:         inst.db.RetentionPeriod,
+		DurationPercentiles:     inst.db.DurationPercentiles,
+		DurationHistograms:      inst.db.DurationHistograms,
+		IncrementalTaskStats:    inst.db.IncrementalTaskStats,
+	}
+	
+	dbScraper := scraper_internal.NewDatabaseScraper(dbCfg, settings, drops)
+	wrapper := scraper_internal.NewDatabaseScraperWrapper(dbScraper, status, rCfg.StrictStartup)
+	sc, err := scraper.NewMetrics(
+		inst.wrap(wrapper.Scrape),
+		scraper.WithStart(wrapper.Start),
+		scraper.WithShutdown(wrapper.Shutdown),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create database scraper: %w", err)
+	}
+	addScraper("airflow_db", inst.db.CollectionInterval, sc)
+	return nil
+}
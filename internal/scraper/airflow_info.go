@@ -0,0 +1,176 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// AirflowInfo holds deployment metadata that is attached as resource
+// attributes to everything emitted for one Airflow instance. It is loaded
+// once by the first REST API scraper that reaches the webserver.
+type AirflowInfo struct {
+	mu            sync.RWMutex
+	version       string
+	executor      string
+	configChecked bool
+	// config holds allowlisted options by "section.key"
+	config map[string]string
+}
+
+func NewAirflowInfo() *AirflowInfo {
+	return &AirflowInfo{}
+}
+
+// load fetches the version until it succeeds. The executor and the options in
+// RESTAPIConfig.ConfigOptions need expose_config, so they are only attempted
+// once.
+func (i *AirflowInfo) load(ctx context.Context, s *RESTAPIScraper) {
+	if i == nil {
+		return
+	}
+	i.mu.RLock()
+	done := i.version != "" && i.configChecked
+	i.mu.RUnlock()
+	if done {
+		return
+	}
+	
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	
+	if i.version == "" {
+		version, err := s.getVersion(ctx)
+		if err != nil {
+			s.settings.Logger.Debug("Failed to get Airflow version, retrying next scrape", zap.Error(err))
+			return
+		}
+		i.version = version.Version
+	}
+	
+	if !i.configChecked {
+		i.configChecked = true
+		i.loadConfig(ctx, s)
+	}
+}
+
+// loadConfig reads the executor and the allowlisted options, one request per
+// section
+func (i *AirflowInfo) loadConfig(ctx context.Context, s *RESTAPIScraper) {
+	bySection := map[string][]string{"core": {"executor"}}
+	for _, option := range s.cfg.ConfigOptions {
+		section, key, _ := strings.Cut(option, ".")
+		bySection[section] = append(bySection[section], key)
+	}
+	
+	i.config = make(map[string]string)
+	for section, keys := range bySection {
+		options, err := s.getConfigSection(ctx, section)
+		if err != nil {
+			s.settings.Logger.Debug("Configuration not available from /config (expose_config may be disabled)",
+				zap.String("section", section), zap.Error(err))
+			continue
+		}
+		for _, key := range keys {
+			value, ok := options[key]
+			if !ok {
+				continue
+			}
+			if section == "core" && key == "executor" {
+				i.executor = value
+			}
+			if slices.Contains(s.cfg.ConfigOptions, section+"."+key) {
+				i.config[section+"."+key] = value
+			}
+		}
+	}
+}
+
+// apply adds the known attributes without overwriting existing ones
+func (i *AirflowInfo) apply(attrs pcommon.Map) {
+	if i.version != "" {
+		if _, ok := attrs.Get("airflow.version"); !ok {
+			attrs.PutStr("airflow.version", i.version)
+		}
+	}
+	if i.executor != "" {
+		if _, ok := attrs.Get("airflow.executor"); !ok {
+			attrs.PutStr("airflow.executor", i.executor)
+		}
+	}
+	for option, value := range i.config {
+		if _, ok := attrs.Get("airflow.config." + option); !ok {
+			attrs.PutStr("airflow.config."+option, value)
+		}
+	}
+}
+
+// ApplyMetrics decorates every resource in md
+func (i *AirflowInfo) ApplyMetrics(md pmetric.Metrics) {
+	if i == nil {
+		return
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	
+	rms := md.ResourceMetrics()
+	for j := 0; j < rms.Len(); j++ {
+		i.apply(rms.At(j).Resource().Attributes())
+	}
+}
+
+// ApplyLogs decorates every resource in ld
+func (i *AirflowInfo) ApplyLogs(ld plog.Logs) {
+	if i == nil {
+		return
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	
+	rls := ld.ResourceLogs()
+	for j := 0; j < rls.Len(); j++ {
+		i.apply(rls.At(j).Resource().Attributes())
+	}
+}
+
+// getConfigSection reads the options of one section from /api/v1/config,
+// which is only available when the webserver sets expose_config
+func (s *RESTAPIScraper) getConfigSection(ctx context.Context, section string) (map[string]string, error) {
+	body, err := s.doRequest(ctx, "/api/v1/config?section="+url.QueryEscape(section))
+	if err != nil {
+		return nil, err
+	}
+	
+	var response ConfigResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	
+	options := make(map[string]string)
+	for _, sec := range response.Sections {
+		if sec.Name != section {
+			continue
+		}
+		for _, opt := range sec.Options {
+			options[opt.Key] = opt.Value
+		}
+	}
+	return options, nil
+}
+
+// LoadInfo loads deployment metadata for scrapers that only use the REST
+// API as a client, such as the logs sources
+func (s *RESTAPIScraper) LoadInfo(ctx context.Context) {
+	s.cfg.Info.load(ctx, s)
+}
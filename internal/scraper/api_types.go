@@ -8,7 +8,8 @@ import "time"
 // API Response types with ALL available fields for complete dimensions
 
 type DAGResponse struct {
-	DAGs []DAG `json:"dags"`
+	DAGs         []DAG `json:"dags"`
+	TotalEntries int   `json:"total_entries"`
 }
 
 type DAG struct {
@@ -51,6 +52,21 @@ type DAGRun struct {
 	LastSchedulingDecision time.Time             `json:"last_scheduling_decision"`
 }
 
+type DAGStatsResponse struct {
+	DAGs         []DAGStats `json:"dags"`
+	TotalEntries int        `json:"total_entries"`
+}
+
+type DAGStats struct {
+	DAGID string          `json:"dag_id"`
+	Stats []DAGStateCount `json:"stats"`
+}
+
+type DAGStateCount struct {
+	State string `json:"state"`
+	Count int64  `json:"count"`
+}
+
 type TaskInstancesResponse struct {
 	TaskInstances []TaskInstance `json:"task_instances"`
 	TotalEntries  int            `json:"total_entries"`
@@ -78,7 +94,8 @@ type TaskInstance struct {
 }
 
 type PoolsResponse struct {
-	Pools []Pool `json:"pools"`
+	Pools        []Pool `json:"pools"`
+	TotalEntries int    `json:"total_entries"`
 }
 
 type Pool struct {
@@ -134,6 +151,11 @@ type Variable struct {
 	Description string `json:"description"`
 }
 
+type VersionResponse struct {
+	Version    string `json:"version"`
+	GitVersion string `json:"git_version"`
+}
+
 type ImportErrorsResponse struct {
 	ImportErrors []ImportError `json:"import_errors"`
 	TotalEntries int           `json:"total_entries"`
@@ -145,3 +167,95 @@ type ImportError struct {
 	StackTrace    string    `json:"stack_trace"`
 	Timestamp     time.Time `json:"timestamp"`
 }
+
+type TasksResponse struct {
+	Tasks        []Task `json:"tasks"`
+	TotalEntries int    `json:"total_entries"`
+}
+
+type Task struct {
+	TaskID            string   `json:"task_id"`
+	Owner             string   `json:"owner"`
+	Retries           float64  `json:"retries"`
+	TriggerRule       string   `json:"trigger_rule"`
+	Pool              string   `json:"pool"`
+	Queue             string   `json:"queue"`
+	DownstreamTaskIDs []string `json:"downstream_task_ids"`
+	ClassRef          struct {
+		ModulePath string `json:"module_path"`
+		ClassName  string `json:"class_name"`
+	} `json:"class_ref"`
+}
+
+type EventLogsResponse struct {
+	EventLogs    []EventLog `json:"event_logs"`
+	TotalEntries int        `json:"total_entries"`
+}
+
+type EventLog struct {
+	EventLogID    int64      `json:"event_log_id"`
+	When          time.Time  `json:"when"`
+	DAGID         string     `json:"dag_id"`
+	TaskID        string     `json:"task_id"`
+	RunID         string     `json:"run_id"`
+	Event         string     `json:"event"`
+	ExecutionDate *time.Time `json:"execution_date"`
+	Owner         string     `json:"owner"`
+	Extra         string     `json:"extra"`
+}
+
+type TaskInstanceLogResponse struct {
+	Content           string `json:"content"`
+	ContinuationToken string `json:"continuation_token"`
+}
+
+type ConfigResponse struct {
+	Sections []ConfigSection `json:"sections"`
+}
+
+type ConfigSection struct {
+	Name    string         `json:"name"`
+	Options []ConfigOption `json:"options"`
+}
+
+type ConfigOption struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type DatasetsResponse struct {
+	TotalEntries int `json:"total_entries"`
+}
+
+type ProvidersResponse struct {
+	Providers    []Provider `json:"providers"`
+	TotalEntries int        `json:"total_entries"`
+}
+
+type Provider struct {
+	PackageName string `json:"package_name"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type PluginsResponse struct {
+	Plugins      []Plugin `json:"plugins"`
+	TotalEntries int      `json:"total_entries"`
+}
+
+type Plugin struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+}
+
+type DAGWarningsResponse struct {
+	DAGWarnings  []DAGWarning `json:"dag_warnings"`
+	TotalEntries int          `json:"total_entries"`
+}
+
+type DAGWarning struct {
+	DAGID       string    `json:"dag_id"`
+	WarningType string    `json:"warning_type"`
+	Message     string    `json:"message"`
+	Timestamp   time.Time `json:"timestamp"`
+}
@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"errors"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// ErrNotFound is returned for a 404, which for a top-level endpoint means the
+// webserver runs an Airflow version without it
+var ErrNotFound = errors.New("endpoint not found")
+
+// Optional API features that older Airflow versions lack
+const (
+	// CapabilityDatasets is /api/v1/datasets, Airflow 2.4+
+	CapabilityDatasets = "datasets"
+	// CapabilityDAGStats is /api/v1/dagStats, Airflow 2.7+
+	CapabilityDAGStats = "dag_stats"
+	// CapabilityDAGWarnings is /api/v1/dagWarnings, Airflow 2.6+
+	CapabilityDAGWarnings = "dag_warnings"
+)
+
+// apiCapabilities remembers which optional endpoints the webserver lacks, so
+// a mixed-version fleet does not log the same error every interval
+type apiCapabilities struct {
+	mu          sync.Mutex
+	unavailable map[string]bool
+}
+
+func newAPICapabilities() *apiCapabilities {
+	return &apiCapabilities{unavailable: make(map[string]bool)}
+}
+
+func (c *apiCapabilities) supported(capability string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.unavailable[capability]
+}
+
+// disable records a missing capability and reports whether it was newly found
+func (c *apiCapabilities) disable(capability string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unavailable[capability] {
+		return false
+	}
+	c.unavailable[capability] = true
+	return true
+}
+
+// scrapeOptional runs a sub-scrape that depends on an optional endpoint. The
+// first call is the probe: a 404 disables the sub-scrape for the lifetime of
+// the scraper and is not reported as an error.
+func (s *RESTAPIScraper) scrapeOptional(capability string, fn func() error) error {
+	if !s.caps.supported(capability) {
+		return nil
+	}
+	
+	err := fn()
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if s.caps.disable(capability) {
+		s.settings.Logger.Info("Airflow API does not support endpoint, disabling sub-scrape",
+			zap.String("capability", capability),
+			zap.String("endpoint", s.cfg.Endpoint))
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// conditionalEntry is the last decoded response of an endpoint along with the
+// validators the webserver sent for it
+type conditionalEntry struct {
+	etag         string
+	lastModified string
+	value        any
+}
+
+// conditionalCache keeps validated responses for endpoints that rarely
+// change, so a 304 Not Modified can reuse the decoded value
+type conditionalCache struct {
+	mu      sync.Mutex
+	entries map[string]conditionalEntry
+}
+
+func newConditionalCache() *conditionalCache {
+	return &conditionalCache{entries: make(map[string]conditionalEntry)}
+}
+
+func (c *conditionalCache) get(path string) (conditionalEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[path]
+	return entry, ok
+}
+
+func (c *conditionalCache) put(path string, entry conditionalEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[path] = entry
+}
+
+// getConditional fetches path with If-None-Match / If-Modified-Since from the
+// previous response. When the webserver answers 304 the previously decoded
+// value is returned without reading or parsing a body. Responses without an
+// ETag or Last-Modified header are decoded every time.
+func getConditional[T any](ctx context.Context, s *RESTAPIScraper, path string, decode func([]byte) (T, error)) (T, error) {
+	cached, ok := s.conditional.get(path)
+	header := http.Header{}
+	if ok {
+		if cached.etag != "" {
+			header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	resp, err := s.fetch(ctx, http.MethodGet, path, nil, header)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if resp.status == http.StatusNotModified && ok {
+		return cached.value.(T), nil
+	}
+
+	value, err := decode(resp.body)
+	if err != nil {
+		return value, err
+	}
+	etag, lastModified := resp.header.Get("ETag"), resp.header.Get("Last-Modified")
+	if etag != "" || lastModified != "" {
+		s.conditional.put(path, conditionalEntry{etag: etag, lastModified: lastModified, value: value})
+	}
+	return value, nil
+}
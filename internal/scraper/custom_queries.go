@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/collector/scraper/scrapererror"
+	"go.uber.org/zap"
+)
+
+// Custom query metric data and value types
+const (
+	CustomDataTypeGauge   = "gauge"
+	CustomDataTypeSum     = "sum"
+	CustomValueTypeInt    = "int"
+	CustomValueTypeDouble = "double"
+)
+
+// CustomQuery is a user-defined SQL statement run against the Airflow
+// metadata database on every database scrape
+type CustomQuery struct {
+	SQL     string
+	Metrics []CustomQueryMetric
+}
+
+// CustomQueryMetric maps one value column of a custom query to a metric
+type CustomQueryMetric struct {
+	MetricName       string
+	ValueColumn      string
+	AttributeColumns []string
+	Unit             string
+	Description      string
+	DataType         string
+	ValueType        string
+	Monotonic        bool
+	// Merge is how gauge points combine when disabled or redacted attributes
+	// make them identical: GaugeMergeSum, GaugeMergeMax or GaugeMergeMin.
+	// Empty keeps the last point.
+	Merge string
+}
+
+func (s *DatabaseScraper) scrapeCustomQueries(ctx context.Context, errs *scrapererror.ScrapeErrors) {
+	for i, query := range s.cfg.CustomQueries {
+		if err := s.scrapeCustomQuery(ctx, query); err != nil {
+			s.settings.Logger.Warn("Failed to run custom query", zap.Int("index", i), zap.Error(err))
+			errs.AddPartial(len(query.Metrics), fmt.Errorf("custom query %d: %w", i, err))
+		}
+	}
+}
+
+func (s *DatabaseScraper) scrapeCustomQuery(ctx context.Context, query CustomQuery) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	var rows *sql.Rows
+	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "custom query", func() error {
+		var err error
+		rows, err = s.db.QueryContext(ctx, query.SQL)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	columnIndex := make(map[string]int, len(columns))
+	for i, column := range columns {
+		columnIndex[column] = i
+	}
+	for _, metric := range query.Metrics {
+		if _, ok := columnIndex[metric.ValueColumn]; !ok {
+			return fmt.Errorf("metric %s: value column %q not in result set", metric.MetricName, metric.ValueColumn)
+		}
+		for _, column := range metric.AttributeColumns {
+			if _, ok := columnIndex[column]; !ok {
+				return fmt.Errorf("metric %s: attribute column %q not in result set", metric.MetricName, column)
+			}
+		}
+	}
+
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	now := time.Now()
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			s.drops.Record(SignalMetrics, DropReasonScanError, int64(len(query.Metrics)),
+				zap.String("query", "custom"), zap.Error(err))
+			continue
+		}
+
+		for _, metric := range query.Metrics {
+			raw := values[columnIndex[metric.ValueColumn]]
+			if !raw.Valid {
+				s.drops.Record(SignalMetrics, DropReasonParseError, 1,
+					zap.String("metric", metric.MetricName), zap.String("reason", "null value"))
+				continue
+			}
+
+			attrs := make(map[string]string, len(metric.AttributeColumns))
+			for _, column := range metric.AttributeColumns {
+				attrs[column] = values[columnIndex[column]].String
+			}
+
+			if metric.ValueType == CustomValueTypeInt {
+				value, err := strconv.ParseInt(raw.String, 10, 64)
+				if err != nil {
+					s.drops.Record(SignalMetrics, DropReasonParseError, 1,
+						zap.String("metric", metric.MetricName), zap.Error(err))
+					continue
+				}
+				s.mb.RecordCustomIntMetric(metric, value, attrs, s.startTime, now)
+			} else {
+				value, err := strconv.ParseFloat(raw.String, 64)
+				if err != nil {
+					s.drops.Record(SignalMetrics, DropReasonParseError, 1,
+						zap.String("metric", metric.MetricName), zap.Error(err))
+					continue
+				}
+				s.mb.RecordCustomDoubleMetric(metric, value, attrs, s.startTime, now)
+			}
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,125 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// Output modes for the REST API scraper
+const (
+	// OutputModeDetailed reports a data point per DAG run and task instance
+	OutputModeDetailed = "detailed"
+	// OutputModeAggregated reports only per-DAG counts, duration histograms
+	// and the state of the latest run
+	OutputModeAggregated = "aggregated"
+)
+
+type dagStateKey struct {
+	dagID string
+	state string
+}
+
+type dagRunKey struct {
+	dagID string
+	runID string
+}
+
+// dagAggregates replaces per-run and per-task-instance points in aggregated
+// output mode. Like operatorHistograms, finished runs and attempts are
+// remembered so each one is counted in a single delta histogram.
+type dagAggregates struct {
+	seenRuns  map[dagRunKey]time.Time
+	seenTasks map[taskInstanceKey]time.Time
+	runs      map[dagStateKey]*durationHistogram
+	tasks     map[dagStateKey]*durationHistogram
+	lastEmit  time.Time
+}
+
+func newDAGAggregates() *dagAggregates {
+	return &dagAggregates{
+		seenRuns:  make(map[dagRunKey]time.Time),
+		seenTasks: make(map[taskInstanceKey]time.Time),
+		runs:      make(map[dagStateKey]*durationHistogram),
+		tasks:     make(map[dagStateKey]*durationHistogram),
+		lastEmit:  time.Now(),
+	}
+}
+
+// observeRun adds a DAG run if it finished and has not been counted yet
+func (a *dagAggregates) observeRun(run DAGRun) {
+	if (run.State != "success" && run.State != "failed") || run.StartDate.IsZero() || run.EndDate.IsZero() {
+		return
+	}
+	duration := run.EndDate.Sub(run.StartDate).Seconds()
+	if duration <= 0 {
+		return
+	}
+	key := dagRunKey{dagID: run.DAGID, runID: run.DAGRunID}
+	if _, ok := a.seenRuns[key]; ok {
+		return
+	}
+	a.seenRuns[key] = run.EndDate
+	histogramFor(a.runs, dagStateKey{dagID: run.DAGID, state: run.State}).observe(duration)
+}
+
+// observeTask adds a task instance if it finished and has not been counted yet
+func (a *dagAggregates) observeTask(task TaskInstance) {
+	if (task.State != "success" && task.State != "failed") || task.Duration <= 0 {
+		return
+	}
+	key := taskInstanceKey{dagID: task.DAGID, runID: task.DAGRunID, taskID: task.TaskID, mapIndex: task.MapIndex, try: task.TryNumber}
+	if _, ok := a.seenTasks[key]; ok {
+		return
+	}
+	a.seenTasks[key] = task.EndDate
+	histogramFor(a.tasks, dagStateKey{dagID: task.DAGID, state: task.State}).observe(task.Duration)
+}
+
+// emit records the durations observed since the previous emit as delta
+// histograms and forgets runs and attempts that finished before retention
+func (a *dagAggregates) emit(mb *MetricsBuilder, now time.Time, retention time.Duration) {
+	start := pcommon.NewTimestampFromTime(a.lastEmit)
+	ts := pcommon.NewTimestampFromTime(now)
+	for key, h := range a.runs {
+		mb.RecordDAGRunDurationHistogram(key.dagID, key.state, h, start, ts)
+	}
+	for key, h := range a.tasks {
+		mb.RecordDAGTaskDurationHistogram(key.dagID, key.state, h, start, ts)
+	}
+	a.runs = make(map[dagStateKey]*durationHistogram)
+	a.tasks = make(map[dagStateKey]*durationHistogram)
+	a.lastEmit = now
+
+	cutoff := now.Add(-retention)
+	for key, endDate := range a.seenRuns {
+		if endDate.Before(cutoff) {
+			delete(a.seenRuns, key)
+		}
+	}
+	for key, endDate := range a.seenTasks {
+		if endDate.Before(cutoff) {
+			delete(a.seenTasks, key)
+		}
+	}
+}
+
+// latestRun returns the run with the most recent logical date, falling back
+// to the start date for runs that have none
+func latestRun(runs []DAGRun) (DAGRun, bool) {
+	var latest DAGRun
+	var latestAt time.Time
+	for _, run := range runs {
+		at := run.LogicalDate
+		if at.IsZero() {
+			at = run.StartDate
+		}
+		if latestAt.IsZero() || at.After(latestAt) {
+			latest, latestAt = run, at
+		}
+	}
+	return latest, len(runs) > 0
+}
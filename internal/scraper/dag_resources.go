@@ -0,0 +1,171 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// DAGResourceAttribute identifies the DAG of a per-DAG resource
+const DAGResourceAttribute = "airflow.dag.id"
+
+// dagResources moves data points with a dag.id attribute onto one resource
+// per DAG, so resource-centric backends can group and restrict access by DAG
+type dagResources struct {
+	metrics pmetric.Metrics
+	// base holds the points of no particular DAG
+	base pmetric.ScopeMetrics
+	// resource and scope the per-DAG copies start from
+	resource pcommon.Resource
+	scope    pcommon.InstrumentationScope
+
+	byDAG map[string]*dagResource
+}
+
+type dagResource struct {
+	sm     pmetric.ScopeMetrics
+	byName map[string]pmetric.Metric
+}
+
+// splitByDAG returns md regrouped into a resource per DAG. Points without a
+// dag.id stay on the original resource.
+func splitByDAG(md pmetric.Metrics) pmetric.Metrics {
+	out := pmetric.NewMetrics()
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			base := out.ResourceMetrics().AppendEmpty()
+			rm.Resource().CopyTo(base.Resource())
+			base.SetSchemaUrl(rm.SchemaUrl())
+			sm := base.ScopeMetrics().AppendEmpty()
+			sms.At(j).Scope().CopyTo(sm.Scope())
+			sm.SetSchemaUrl(sms.At(j).SchemaUrl())
+
+			d := &dagResources{
+				metrics:  out,
+				base:     sm,
+				resource: rm.Resource(),
+				scope:    sms.At(j).Scope(),
+				byDAG:    make(map[string]*dagResource),
+			}
+			ms := sms.At(j).Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				d.split(ms.At(k))
+			}
+		}
+	}
+	out.ResourceMetrics().RemoveIf(func(rm pmetric.ResourceMetrics) bool {
+		return rm.ScopeMetrics().At(0).Metrics().Len() == 0
+	})
+	return out
+}
+
+func (d *dagResources) split(m pmetric.Metric) {
+	var baseMetric pmetric.Metric
+	baseCreated := false
+	target := func(dagID string) pmetric.Metric {
+		if dagID == "" {
+			if !baseCreated {
+				baseMetric = d.base.Metrics().AppendEmpty()
+				copyMetricDefinition(m, baseMetric)
+				baseCreated = true
+			}
+			return baseMetric
+		}
+		return d.dag(dagID).metric(m)
+	}
+
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		movePoints[pmetric.NumberDataPoint](m.Gauge().DataPoints(), func(dagID string) pointAppender[pmetric.NumberDataPoint] {
+			return target(dagID).Gauge().DataPoints()
+		})
+	case pmetric.MetricTypeSum:
+		movePoints[pmetric.NumberDataPoint](m.Sum().DataPoints(), func(dagID string) pointAppender[pmetric.NumberDataPoint] {
+			return target(dagID).Sum().DataPoints()
+		})
+	case pmetric.MetricTypeHistogram:
+		movePoints[pmetric.HistogramDataPoint](m.Histogram().DataPoints(), func(dagID string) pointAppender[pmetric.HistogramDataPoint] {
+			return target(dagID).Histogram().DataPoints()
+		})
+	case pmetric.MetricTypeExponentialHistogram:
+		movePoints[pmetric.ExponentialHistogramDataPoint](m.ExponentialHistogram().DataPoints(), func(dagID string) pointAppender[pmetric.ExponentialHistogramDataPoint] {
+			return target(dagID).ExponentialHistogram().DataPoints()
+		})
+	default:
+		m.CopyTo(d.base.Metrics().AppendEmpty())
+	}
+}
+
+// dag returns the scope of the DAG's resource, adding the resource on first use
+func (d *dagResources) dag(dagID string) *dagResource {
+	if r, ok := d.byDAG[dagID]; ok {
+		return r
+	}
+	rm := d.metrics.ResourceMetrics().AppendEmpty()
+	d.resource.CopyTo(rm.Resource())
+	rm.Resource().Attributes().PutStr(DAGResourceAttribute, dagID)
+	sm := rm.ScopeMetrics().AppendEmpty()
+	d.scope.CopyTo(sm.Scope())
+
+	r := &dagResource{sm: sm, byName: make(map[string]pmetric.Metric)}
+	d.byDAG[dagID] = r
+	return r
+}
+
+// metric returns the DAG's copy of m's definition, without data points
+func (r *dagResource) metric(m pmetric.Metric) pmetric.Metric {
+	if metric, ok := r.byName[m.Name()]; ok {
+		return metric
+	}
+	metric := r.sm.Metrics().AppendEmpty()
+	copyMetricDefinition(m, metric)
+	r.byName[m.Name()] = metric
+	return metric
+}
+
+// copyMetricDefinition sets dst's name, unit, description and data type from
+// src, leaving out the data points
+func copyMetricDefinition(src, dst pmetric.Metric) {
+	dst.SetName(src.Name())
+	dst.SetUnit(src.Unit())
+	dst.SetDescription(src.Description())
+	switch src.Type() {
+	case pmetric.MetricTypeGauge:
+		dst.SetEmptyGauge()
+	case pmetric.MetricTypeSum:
+		sum := dst.SetEmptySum()
+		sum.SetIsMonotonic(src.Sum().IsMonotonic())
+		sum.SetAggregationTemporality(src.Sum().AggregationTemporality())
+	case pmetric.MetricTypeHistogram:
+		dst.SetEmptyHistogram().SetAggregationTemporality(src.Histogram().AggregationTemporality())
+	case pmetric.MetricTypeExponentialHistogram:
+		dst.SetEmptyExponentialHistogram().SetAggregationTemporality(src.ExponentialHistogram().AggregationTemporality())
+	}
+}
+
+// pointAppender is implemented by the pmetric data point slices
+type pointAppender[P any] interface {
+	AppendEmpty() P
+}
+
+// movePoints copies every point to the slice for its DAG, without dag.id
+func movePoints[P interface {
+	Attributes() pcommon.Map
+	CopyTo(P)
+}](src dataPointSlice[P], dst func(dagID string) pointAppender[P]) {
+	for i := 0; i < src.Len(); i++ {
+		dp := src.At(i)
+		dagID := ""
+		if v, ok := dp.Attributes().Get("dag.id"); ok {
+			dagID = v.AsString()
+		}
+		moved := dst(dagID).AppendEmpty()
+		dp.CopyTo(moved)
+		moved.Attributes().Remove("dag.id")
+	}
+}
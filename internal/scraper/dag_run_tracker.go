@@ -0,0 +1,72 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"time"
+)
+
+// runWatermarkOverlap re-requests a short window before the previous fetch so
+// runs updated while it was in flight are not missed
+const runWatermarkOverlap = 30 * time.Second
+
+// dagRunTracker remembers the runs seen for one DAG so later scrapes only
+// need to request runs updated since the previous fetch
+type dagRunTracker struct {
+	lastFetch time.Time
+	runs      map[string]DAGRun
+}
+
+func newDAGRunTracker() *dagRunTracker {
+	return &dagRunTracker{runs: make(map[string]DAGRun)}
+}
+
+// since returns the updated_at lower bound for the next fetch, or the zero
+// time if a full fetch is needed
+func (t *dagRunTracker) since() time.Time {
+	if t.lastFetch.IsZero() {
+		return time.Time{}
+	}
+	return t.lastFetch.Add(-runWatermarkOverlap)
+}
+
+// runningRunIDs returns runs that were still active at the previous fetch,
+// whose task instances keep changing even if the run itself is not updated
+func (t *dagRunTracker) runningRunIDs() []string {
+	var ids []string
+	for id, run := range t.runs {
+		if run.State == "running" || run.State == "queued" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// merge applies fetched runs and reports which of them finished since the
+// previous fetch, so their durations are recorded exactly once
+func (t *dagRunTracker) merge(runs []DAGRun, fetchedAt time.Time, retention time.Duration) []DAGRun {
+	var finished []DAGRun
+	for _, run := range runs {
+		previous, seen := t.runs[run.DAGRunID]
+		if isFinishedRunState(run.State) && (!seen || !isFinishedRunState(previous.State)) {
+			finished = append(finished, run)
+		}
+		t.runs[run.DAGRunID] = run
+	}
+	t.lastFetch = fetchedAt
+
+	// Forget runs that fell out of the lookback window to bound memory
+	cutoff := fetchedAt.Add(-retention)
+	for id, run := range t.runs {
+		if !run.StartDate.IsZero() && run.StartDate.Before(cutoff) && isFinishedRunState(run.State) {
+			delete(t.runs, id)
+		}
+	}
+
+	return finished
+}
+
+func isFinishedRunState(state string) bool {
+	return state == "success" || state == "failed"
+}
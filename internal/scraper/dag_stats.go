@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// dagStatsBatchSize bounds the dag_ids of one /dagStats request so the URL
+// stays within common proxy limits
+const dagStatsBatchSize = 50
+
+// getDAGStats returns the run counts by state of each DAG, with one request
+// per batch of DAGs instead of one per DAG
+func (s *RESTAPIScraper) getDAGStats(ctx context.Context, dagIDs []string) (map[string]map[string]int64, error) {
+	stats := make(map[string]map[string]int64, len(dagIDs))
+	for batch := range slices.Chunk(dagIDs, dagStatsBatchSize) {
+		body, err := s.doRequest(ctx, "/api/v1/dagStats?dag_ids="+url.QueryEscape(strings.Join(batch, ",")))
+		if err != nil {
+			return nil, err
+		}
+
+		var response DAGStatsResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, err
+		}
+		for _, dag := range response.DAGs {
+			counts := make(map[string]int64, len(dag.Stats))
+			for _, stat := range dag.Stats {
+				counts[stat.State] = stat.Count
+			}
+			stats[dag.DAGID] = counts
+		}
+	}
+	return stats, nil
+}
+
+// runsSettled reports whether a DAG has had no run activity since the
+// previous scrape: no runs in flight and the same counts as last time
+func runsSettled(previous, current map[string]int64) bool {
+	if previous == nil || current == nil {
+		return false
+	}
+	if current["running"] > 0 || current["queued"] > 0 {
+		return false
+	}
+	return maps.Equal(previous, current)
+}
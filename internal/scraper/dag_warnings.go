@@ -0,0 +1,127 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+// maxDAGWarnings bounds how many warnings one scrape reads
+const maxDAGWarnings = 1000
+
+// getDAGWarnings lists the warnings the scheduler attached to DAGs, such as a
+// task referencing a pool that does not exist. Warnings past maxDAGWarnings
+// are recorded as truncated for signal.
+func (s *RESTAPIScraper) getDAGWarnings(ctx context.Context, signal string) ([]DAGWarning, error) {
+	page, err := fetchPages(ctx, s, "/api/v1/dagWarnings", maxDAGWarnings, func(body []byte) ([]DAGWarning, int, error) {
+		var response DAGWarningsResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, 0, err
+		}
+		return response.DAGWarnings, response.TotalEntries, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.recordTruncated(signal, EndpointDAGWarnings, page.total, len(page.items), maxDAGWarnings)
+	return page.items, nil
+}
+
+// dagWarningKey identifies a warning; the scheduler replaces a DAG's warnings
+// on every parse, so the timestamp is not part of it
+type dagWarningKey struct {
+	dagID       string
+	warningType string
+	message     string
+}
+
+// DAGWarningWatcher polls /dagWarnings and emits one log record per warning
+// when it first appears, as the webserver otherwise only shows them in the UI
+type DAGWarningWatcher struct {
+	rest     *RESTAPIScraper
+	settings receiver.Settings
+	previous map[dagWarningKey]bool
+	// pending holds the warnings of the last scrape until its records are
+	// consumed
+	pending map[dagWarningKey]bool
+}
+
+func NewDAGWarningWatcher(rest *RESTAPIScraper, settings receiver.Settings) *DAGWarningWatcher {
+	return &DAGWarningWatcher{
+		rest:     rest,
+		settings: settings,
+		previous: make(map[dagWarningKey]bool),
+	}
+}
+
+func (w *DAGWarningWatcher) Start(ctx context.Context, host component.Host) error {
+	return w.rest.Start(ctx, host)
+}
+
+func (w *DAGWarningWatcher) Scrape(ctx context.Context) (plog.Logs, error) {
+	// Resource attributes come from the shared deployment metadata
+	w.rest.LoadInfo(ctx)
+
+	lb := NewDAGWarningLogsBuilder(w.settings)
+	lb.SetRedactor(w.rest.cfg.Redactor)
+
+	w.pending = nil
+	var warnings []DAGWarning
+	err := w.rest.scrapeOptional(CapabilityDAGWarnings, func() error {
+		var err error
+		warnings, err = w.rest.getDAGWarnings(ctx, SignalLogs)
+		return err
+	})
+	if err != nil {
+		return lb.Emit(), fmt.Errorf("failed to get DAG warnings: %w", err)
+	}
+
+	// A warning that was resolved and comes back is reported again
+	current := make(map[dagWarningKey]bool, len(warnings))
+	for _, warning := range warnings {
+		key := dagWarningKey{dagID: warning.DAGID, warningType: warning.WarningType, message: warning.Message}
+		if current[key] {
+			continue
+		}
+		current[key] = true
+		if !w.previous[key] {
+			lb.RecordDAGWarning(warning)
+		}
+	}
+
+	w.pending = current
+	logs := lb.Emit()
+	w.settings.Logger.Debug("Checked DAG warnings",
+		zap.Int("warnings", len(current)),
+		zap.Int("new", logs.LogRecordCount()))
+	return logs, nil
+}
+
+// Commit makes the last scrape's warnings the ones later scrapes compare to
+func (w *DAGWarningWatcher) Commit() {
+	if w.pending != nil {
+		w.previous = w.pending
+		w.pending = nil
+	}
+}
+
+func (w *DAGWarningWatcher) Shutdown(ctx context.Context) error {
+	return w.rest.Shutdown(ctx)
+}
+
+// countDAGWarnings groups warnings by warning_type
+func countDAGWarnings(warnings []DAGWarning) map[string]int64 {
+	counts := make(map[string]int64)
+	for _, warning := range warnings {
+		counts[warning.WarningType]++
+	}
+	return counts
+}
@@ -6,15 +6,20 @@ package scraper
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/scraper/scrapererror"
 	"go.uber.org/zap"
-	_ "github.com/lib/pq"
+	
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type DatabaseScraper struct {
@@ -23,16 +28,111 @@ type DatabaseScraper struct {
 	db          *sql.DB
 	mb          *MetricsBuilder
 	retryConfig RetryConfig
+	drops       *DropTracker
+	startTime   time.Time
+	
+	// queuedAtMissing is set once the metadata schema is found to predate
+	// dag_run.queued_at (Airflow < 2.2)
+	queuedAtMissing bool
+	// datasetsMissing is set once the metadata schema is found to predate
+	// the dataset tables (Airflow < 2.4)
+	datasetsMissing bool
+	
+	// Cumulative task failure and retry totals since startTime, advanced
+	// incrementally from counterWatermark on every scrape. The watermark is
+	// read from the database clock and unset until the first scrape.
+	counterWatermark time.Time
+	failureTotals    map[string]int64
+	retryTotals      map[string]int64
+	
+	// Cumulative sensor reschedules and their delay per task since startTime,
+	// advanced from rescheduleWatermark on every scrape, which is read from
+	// the database clock like counterWatermark
+	rescheduleWatermark   time.Time
+	rescheduleTotals      map[taskKey]int64
+	rescheduleDelayTotals map[taskKey]float64
+	
+	// End of the window the last duration histograms covered
+	histogramWatermark time.Time
+	
+	// taskWindow holds the last 24h of task instances with
+	// IncrementalTaskStats, until the schema turns out to lack updated_at
+	taskWindow *taskInstanceWindow
+	
+	// Highest log.id and when it was read, the baseline for the growth rate
+	logMaxID     int64
+	logMaxIDTime time.Time
 }
 
 type DatabaseConfig struct {
-	Host               string
-	Port               int
-	Database           string
-	Username           string
-	Password           string
-	SSLMode            string
+	PostgresConnConfig
+	// Name identifies the scraper in health metrics, "database" by default
+	Name               string
+	// SummaryLogInterval logs a summary of scrape results periodically when set
+	SummaryLogInterval time.Duration
+	// ExcludeMetrics are not emitted, for metric families the REST API owns
+	ExcludeMetrics     []string
+	// DisabledAttributes are dropped from every data point
+	DisabledAttributes []string
+	// Redactor rewrites sensitive attribute values
+	Redactor           *Redactor
+	// ResourcePerDAG reports each DAG on its own resource
+	ResourcePerDAG     bool
 	CollectionInterval time.Duration
+	QueryTimeout       time.Duration
+	MaxOpenConns       int
+	MaxIdleConns       int
+	ConnMaxLifetime    time.Duration
+	ConnMaxIdleTime    time.Duration
+	CacheStatements    bool
+	CustomQueries      []CustomQuery
+	// OrphanedTaskThreshold is how long a task instance may run before it
+	// counts as orphaned; DAGs matching OrphanedTaskExcludeDAGs never do
+	OrphanedTaskThreshold   time.Duration
+	OrphanedTaskExcludeDAGs []string
+	// TableStats enables size and row metrics for TableStatsTables, which
+	// defaults to DefaultTableStatsTables
+	TableStats       bool
+	TableStatsTables []string
+	// RetentionPeriod enables counts of retentionTables rows older than it
+	RetentionPeriod time.Duration
+	// DurationPercentiles are computed for task instance and DAG run
+	// durations, each between 0 and 1
+	DurationPercentiles []float64
+	// DurationHistograms replaces the duration gauges with exponential
+	// histograms of the durations finished since the previous scrape
+	DurationHistograms bool
+	// IncrementalTaskStats aggregates task instances in the receiver from the
+	// rows updated since the previous scrape
+	IncrementalTaskStats bool
+}
+
+// DefaultTableStatsTables are the metadata tables that grow without bound
+// unless `airflow db clean` runs regularly
+var DefaultTableStatsTables = []string{
+	"task_instance",
+	"log",
+	"xcom",
+	"dag_run",
+	"rendered_task_instance_fields",
+	"job",
+}
+
+// retentionTables counts the rows of each table that `airflow db clean` would
+// remove for a cutoff. Rendered fields have no timestamp of their own and are
+// cleaned along with their task instance.
+var retentionTables = map[string]string{
+	"xcom": `SELECT COUNT(*) FROM xcom WHERE timestamp < $1`,
+	"rendered_task_instance_fields": `
+		SELECT COUNT(*)
+		FROM rendered_task_instance_fields r
+		JOIN task_instance ti
+			ON ti.dag_id = r.dag_id
+			AND ti.task_id = r.task_id
+			AND ti.run_id = r.run_id
+			AND ti.map_index = r.map_index
+		WHERE ti.start_date < $1
+	`,
 }
 
 // Database query result types
@@ -64,42 +164,70 @@ type SchedulerMetrics struct {
 	RunningTasks    int64
 	SuccessTasks24h int64
 	FailedTasks24h  int64
-	OrphanedTasks   int64
 }
 
-func NewDatabaseScraper(cfg *DatabaseConfig, settings receiver.Settings) *DatabaseScraper {
-	return &DatabaseScraper{
+// taskInstanceStatsLimit caps the number of task groups reported per scrape
+const taskInstanceStatsLimit = 1000
+
+// dagFileLimit caps the number of DAG files reported per scrape, stalest first
+const dagFileLimit = 1000
+
+// serializedDAGLimit caps the number of DAGs reported per scrape, stalest first
+const serializedDAGLimit = 1000
+
+// datasetLimit caps the number of datasets reported per scrape, stalest first
+const datasetLimit = 1000
+
+// jobHeartbeatThreshold matches Airflow's default scheduler and triggerer
+// health check threshold: a running job is alive while its heartbeat is newer
+const jobHeartbeatThreshold = 30 * time.Second
+
+// monitoredJobTypes are the long-lived Airflow components tracked in the job
+// table; each gets an alive count even when none are running
+var monitoredJobTypes = []string{"SchedulerJob", "TriggererJob", "DagProcessorJob"}
+
+func NewDatabaseScraper(cfg *DatabaseConfig, settings receiver.Settings, drops *DropTracker) *DatabaseScraper {
+	now := time.Now()
+	mb := NewMetricsBuilder(settings)
+	mb.SetExcludedMetrics(cfg.ExcludeMetrics)
+	mb.SetDisabledAttributes(cfg.DisabledAttributes)
+	mb.SetDropTracker(drops)
+	mb.SetRedactor(cfg.Redactor)
+	mb.SetResourcePerDAG(cfg.ResourcePerDAG)
+	s := &DatabaseScraper{
 		cfg:         cfg,
 		settings:    settings,
-		mb:          NewMetricsBuilder(),
+		mb:          mb,
 		retryConfig: DefaultRetryConfig(),
+		drops:       drops,
+		startTime:   now,
+		
+		failureTotals: make(map[string]int64),
+		retryTotals:   make(map[string]int64),
+		
+		rescheduleTotals:      make(map[taskKey]int64),
+		rescheduleDelayTotals: make(map[taskKey]float64),
 	}
+	if cfg.IncrementalTaskStats {
+		s.taskWindow = newTaskInstanceWindow(now)
+	}
+	return s
 }
 
 func (s *DatabaseScraper) Start(ctx context.Context, host component.Host) error {
-	connStr := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		s.cfg.Host,
-		s.cfg.Port,
-		s.cfg.Username,
-		s.cfg.Password,
-		s.cfg.Database,
-		s.cfg.SSLMode,
-	)
-	
 	var db *sql.DB
 	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "database connection", func() error {
 		var err error
-		db, err = sql.Open("postgres", connStr)
+		db, err = s.cfg.OpenDB(s.cfg.CacheStatements)
 		if err != nil {
 			return fmt.Errorf("failed to open database: %w", err)
 		}
 		
 		// Configure connection pool
-		db.SetMaxOpenConns(10)
-		db.SetMaxIdleConns(5)
-		db.SetConnMaxLifetime(5 * time.Minute)
-		db.SetConnMaxIdleTime(1 * time.Minute)
+		db.SetMaxOpenConns(s.cfg.MaxOpenConns)
+		db.SetMaxIdleConns(s.cfg.MaxIdleConns)
+		db.SetConnMaxLifetime(s.cfg.ConnMaxLifetime)
+		db.SetConnMaxIdleTime(s.cfg.ConnMaxIdleTime)
 		
 		// Test connection
 		if err := db.PingContext(ctx); err != nil {
@@ -115,40 +243,168 @@ func (s *DatabaseScraper) Start(ctx context.Context, host component.Host) error
 	}
 	
 	s.db = db
+	addr := s.cfg.Host
+	if len(s.cfg.Hosts) > 0 {
+		addr = strings.Join(s.cfg.Hosts, ",")
+	}
 	s.settings.Logger.Info("Connected to Airflow database",
-		zap.String("host", s.cfg.Host),
+		zap.String("host", addr),
 		zap.String("database", s.cfg.Database))
 	
 	return nil
 }
 
+// name identifies the scraper in health and self-telemetry metrics
+func (s *DatabaseScraper) name() string {
+	if s.cfg.Name == "" {
+		return "database"
+	}
+	return s.cfg.Name
+}
+
 func (s *DatabaseScraper) Scrape(ctx context.Context) (pmetric.Metrics, error) {
 	now := pcommon.NewTimestampFromTime(time.Now())
+	var errs scrapererror.ScrapeErrors
 	
-	// Query 1: Task instance statistics
-	if err := s.scrapeTaskInstanceStats(ctx, now); err != nil {
-		s.settings.Logger.Warn("Failed to scrape task instance stats", zap.Error(err))
-	}
+	// Query 1: Task instance statistics and attempts per finished task
+	// instance, read incrementally when enabled
+	s.scrapeTaskStats(ctx, now, &errs)
 	
 	// Query 2: DAG run statistics
 	if err := s.scrapeDAGRunStats(ctx, now); err != nil {
 		s.settings.Logger.Warn("Failed to scrape DAG run stats", zap.Error(err))
+		errs.AddPartial(1, fmt.Errorf("failed to scrape DAG run stats: %w", err))
+	}
+	
+	// Query 2b: DAG run queue time
+	if err := s.scrapeDAGRunQueueStats(ctx, now); err != nil {
+		s.settings.Logger.Warn("Failed to scrape DAG run queue stats", zap.Error(err))
+		errs.AddPartial(1, fmt.Errorf("failed to scrape DAG run queue stats: %w", err))
+	}
+	
+	// Query 2c: Exponential duration histograms
+	if s.cfg.DurationHistograms {
+		if err := s.scrapeDurationHistograms(ctx); err != nil {
+			s.settings.Logger.Warn("Failed to scrape duration histograms", zap.Error(err))
+			errs.AddPartial(2, fmt.Errorf("failed to scrape duration histograms: %w", err))
+		}
 	}
 	
 	// Query 3: Scheduler metrics
 	if err := s.scrapeSchedulerMetrics(ctx, now); err != nil {
 		s.settings.Logger.Warn("Failed to scrape scheduler metrics", zap.Error(err))
+		errs.AddPartial(1, fmt.Errorf("failed to scrape scheduler metrics: %w", err))
+	}
+	
+	// Query 3a: Long-running (orphaned) tasks per DAG and pool
+	if err := s.scrapeOrphanedTasks(ctx, now); err != nil {
+		s.settings.Logger.Warn("Failed to scrape orphaned tasks", zap.Error(err))
+		errs.AddPartial(1, fmt.Errorf("failed to scrape orphaned tasks: %w", err))
+	}
+	
+	// Query 3b: Cumulative task failures and retries
+	if err := s.scrapeTaskCounters(ctx); err != nil {
+		s.settings.Logger.Warn("Failed to scrape task failure and retry counters", zap.Error(err))
+		errs.AddPartial(2, fmt.Errorf("failed to scrape task counters: %w", err))
+	}
+	
+	// Query 3c: DAG processing health
+	if err := s.scrapeDAGProcessing(ctx, now); err != nil {
+		s.settings.Logger.Warn("Failed to scrape DAG processing stats", zap.Error(err))
+		errs.AddPartial(1, fmt.Errorf("failed to scrape DAG processing stats: %w", err))
+	}
+	
+	// Query 3d: Per-file DAG parse age
+	if err := s.scrapeDAGFileParseAge(ctx, now); err != nil {
+		s.settings.Logger.Warn("Failed to scrape DAG file parse times", zap.Error(err))
+		errs.AddPartial(1, fmt.Errorf("failed to scrape DAG file parse times: %w", err))
+	}
+	
+	// Query 3e: Serialized DAG staleness
+	if err := s.scrapeSerializedDAGAge(ctx, now); err != nil {
+		s.settings.Logger.Warn("Failed to scrape serialized DAG age", zap.Error(err))
+		errs.AddPartial(1, fmt.Errorf("failed to scrape serialized DAG age: %w", err))
+	}
+	
+	// Query 3f: Scheduler, triggerer and DAG processor liveness
+	if err := s.scrapeJobs(ctx, now); err != nil {
+		s.settings.Logger.Warn("Failed to scrape jobs", zap.Error(err))
+		errs.AddPartial(1, fmt.Errorf("failed to scrape jobs: %w", err))
+	}
+	
+	// Query 3g: Dataset event throughput and freshness
+	if err := s.scrapeDatasetEvents(ctx, now); err != nil {
+		s.settings.Logger.Warn("Failed to scrape dataset events", zap.Error(err))
+		errs.AddPartial(1, fmt.Errorf("failed to scrape dataset events: %w", err))
+	}
+	
+	// Query 3h: Metadata table growth
+	if s.cfg.TableStats {
+		if err := s.scrapeTableStats(ctx, now); err != nil {
+			s.settings.Logger.Warn("Failed to scrape table stats", zap.Error(err))
+			errs.AddPartial(1, fmt.Errorf("failed to scrape table stats: %w", err))
+		}
+	}
+	
+	// The log table's growth is a single index lookup, so it is not gated
+	if err := s.scrapeLogGrowth(ctx, now); err != nil {
+		s.settings.Logger.Warn("Failed to scrape log table growth", zap.Error(err))
+		errs.AddPartial(1, fmt.Errorf("failed to scrape log table growth: %w", err))
+	}
+	
+	// Query 3i: Rows past the retention period
+	if s.cfg.RetentionPeriod > 0 {
+		if err := s.scrapeRetention(ctx, now); err != nil {
+			s.settings.Logger.Warn("Failed to scrape expired rows", zap.Error(err))
+			errs.AddPartial(1, fmt.Errorf("failed to scrape expired rows: %w", err))
+		}
+	}
+	
+	// Query 3j: Cumulative sensor reschedules
+	if err := s.scrapeTaskReschedules(ctx); err != nil {
+		s.settings.Logger.Warn("Failed to scrape task reschedules", zap.Error(err))
+		errs.AddPartial(2, fmt.Errorf("failed to scrape task reschedules: %w", err))
 	}
 	
 	// Query 4: SLA misses
 	if err := s.scrapeSLAMisses(ctx, now); err != nil {
 		s.settings.Logger.Warn("Failed to scrape SLA misses", zap.Error(err))
+		errs.AddPartial(1, fmt.Errorf("failed to scrape SLA misses: %w", err))
 	}
 	
-	return s.mb.Emit(), nil
+	// User-defined queries
+	s.scrapeCustomQueries(ctx, &errs)
+	
+	// The receiver's own connection pool, for tuning max_open_conns
+	s.mb.RecordReceiverDBPoolStats(s.db.Stats(), s.name(), s.startTime, time.Now())
+	
+	return s.mb.Emit(), errs.Combine()
+}
+
+// percentileColumns renders a percentile_cont select column of expr for each
+// percentile, each preceded by a comma. Percentiles are validated numbers, so
+// they are safe to inline.
+func percentileColumns(percentiles []float64, expr string) string {
+	var b strings.Builder
+	for _, p := range percentiles {
+		fmt.Fprintf(&b, ",\n\t\t\tpercentile_cont(%s) WITHIN GROUP (ORDER BY %s)", strconv.FormatFloat(p, 'f', -1, 64), expr)
+	}
+	return b.String()
+}
+
+// queryContext bounds a single query so a slow metadata database cannot stall
+// the whole scrape cycle
+func (s *DatabaseScraper) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.cfg.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.cfg.QueryTimeout)
 }
 
 func (s *DatabaseScraper) scrapeTaskInstanceStats(ctx context.Context, ts pcommon.Timestamp) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+	
 	query := `
 		SELECT 
 			dag_id,
@@ -160,19 +416,21 @@ func (s *DatabaseScraper) scrapeTaskInstanceStats(ctx context.Context, ts pcommo
 			COUNT(*) as count,
 			AVG(EXTRACT(EPOCH FROM (end_date - start_date))) as avg_duration,
 			MAX(EXTRACT(EPOCH FROM (end_date - start_date))) as max_duration,
-			MIN(EXTRACT(EPOCH FROM (end_date - start_date))) as min_duration
+			MIN(EXTRACT(EPOCH FROM (end_date - start_date))) as min_duration` +
+		percentileColumns(s.cfg.DurationPercentiles, "EXTRACT(EPOCH FROM (end_date - start_date))") + `,
+			COUNT(*) OVER () as total_groups
 		FROM task_instance
 		WHERE start_date >= NOW() - INTERVAL '24 hours'
 			AND end_date IS NOT NULL
 		GROUP BY dag_id, task_id, state, operator, pool, queue
 		ORDER BY count DESC
-		LIMIT 1000
+		LIMIT $1
 	`
 	
 	var rows *sql.Rows
 	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "query task instances", func() error {
 		var err error
-		rows, err = s.db.QueryContext(ctx, query)
+		rows, err = s.db.QueryContext(ctx, query, taskInstanceStatsLimit)
 		return err
 	})
 	
@@ -182,9 +440,11 @@ func (s *DatabaseScraper) scrapeTaskInstanceStats(ctx context.Context, ts pcommo
 	defer rows.Close()
 	
 	count := 0
+	totalGroups := int64(0)
+	percentiles := make([]float64, len(s.cfg.DurationPercentiles))
 	for rows.Next() {
 		var stats TaskInstanceStats
-		if err := rows.Scan(
+		dest := []any{
 			&stats.DAGID,
 			&stats.TaskID,
 			&stats.State,
@@ -195,32 +455,114 @@ func (s *DatabaseScraper) scrapeTaskInstanceStats(ctx context.Context, ts pcommo
 			&stats.AvgDuration,
 			&stats.MaxDuration,
 			&stats.MinDuration,
-		); err != nil {
+		}
+		for i := range percentiles {
+			dest = append(dest, &percentiles[i])
+		}
+		if err := rows.Scan(append(dest, &totalGroups)...); err != nil {
+			s.drops.Record(SignalMetrics, DropReasonScanError, 1,
+				zap.String("query", "task_instance_stats"), zap.Error(err))
 			continue
 		}
 		
-		// Record aggregated metrics
-		s.mb.RecordTaskInstanceCountDB(stats.Count, stats.DAGID, stats.TaskID, stats.State, stats.Operator, stats.Pool, time.Now())
-		
-		if stats.AvgDuration > 0 {
-			s.mb.RecordTaskInstanceAvgDuration(stats.AvgDuration, stats.DAGID, stats.TaskID, stats.State, time.Now())
-			s.mb.RecordTaskInstanceMaxDuration(stats.MaxDuration, stats.DAGID, stats.TaskID, stats.State, time.Now())
-		}
+		s.recordTaskInstanceStats(stats, percentiles)
 		count++
 	}
 	
-	s.settings.Logger.Info("Scraped task instance stats from DB", zap.Int("records", count))
+	if totalGroups > taskInstanceStatsLimit {
+		s.drops.Record(SignalMetrics, DropReasonTruncated, totalGroups-taskInstanceStatsLimit,
+			zap.String("query", "task_instance_stats"), zap.Int("limit", taskInstanceStatsLimit))
+	}
+	
+	s.settings.Logger.Debug("Scraped task instance stats from DB", zap.Int("records", count))
 	return rows.Err()
 }
 
+// recordTaskInstanceStats records one task group, with percentiles in the
+// order of DurationPercentiles
+func (s *DatabaseScraper) recordTaskInstanceStats(stats TaskInstanceStats, percentiles []float64) {
+	s.mb.RecordTaskInstanceCountDB(stats.Count, stats.DAGID, stats.TaskID, stats.State, stats.Operator, stats.Pool, time.Now())
+	
+	if stats.AvgDuration > 0 && !s.cfg.DurationHistograms {
+		s.mb.RecordTaskInstanceAvgDuration(stats.AvgDuration, stats.DAGID, stats.TaskID, stats.State, time.Now())
+		s.mb.RecordTaskInstanceMaxDuration(stats.MaxDuration, stats.DAGID, stats.TaskID, stats.State, time.Now())
+		for i, p := range s.cfg.DurationPercentiles {
+			s.mb.RecordTaskInstanceDurationPercentile(percentiles[i], p, stats.DAGID, stats.TaskID, stats.State, time.Now())
+		}
+	}
+}
+
+// scrapeTaskTries counts task instances that finished in the last 24 hours by
+// the attempt they finished on
+func (s *DatabaseScraper) scrapeTaskTries(ctx context.Context, ts pcommon.Timestamp) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+	
+	query := `
+		SELECT
+			dag_id,
+			task_id,
+			state,
+			COALESCE(try_number, 0) as try_number,
+			COUNT(*) as count,
+			COUNT(*) OVER () as total_groups
+		FROM task_instance
+		WHERE end_date >= NOW() - INTERVAL '24 hours'
+			AND state IN ('success', 'failed')
+		GROUP BY dag_id, task_id, state, try_number
+		ORDER BY count DESC
+		LIMIT $1
+	`
+	
+	tries := newTaskTryStats()
+	totalGroups := int64(0)
+	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "query task tries", func() error {
+		tries = newTaskTryStats()
+		rows, err := s.db.QueryContext(ctx, query, taskInstanceStatsLimit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		
+		for rows.Next() {
+			var (
+				dagID, taskID, state string
+				try                  int
+				count                int64
+			)
+			if err := rows.Scan(&dagID, &taskID, &state, &try, &count, &totalGroups); err != nil {
+				s.drops.Record(SignalMetrics, DropReasonScanError, 1,
+					zap.String("query", "task_tries"), zap.Error(err))
+				continue
+			}
+			tries.add(dagID, taskID, state, try, count)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return err
+	}
+	
+	if totalGroups > taskInstanceStatsLimit {
+		s.drops.Record(SignalMetrics, DropReasonTruncated, totalGroups-taskInstanceStatsLimit,
+			zap.String("query", "task_tries"), zap.Int("limit", taskInstanceStatsLimit))
+	}
+	tries.record(s.mb, ts)
+	return nil
+}
+
 func (s *DatabaseScraper) scrapeDAGRunStats(ctx context.Context, ts pcommon.Timestamp) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+	
 	query := `
 		SELECT 
 			dag_id,
 			state,
 			COUNT(*) as count,
 			AVG(EXTRACT(EPOCH FROM (end_date - start_date))) as avg_duration,
-			MAX(EXTRACT(EPOCH FROM (end_date - start_date))) as max_duration
+			MAX(EXTRACT(EPOCH FROM (end_date - start_date))) as max_duration` +
+		percentileColumns(s.cfg.DurationPercentiles, "EXTRACT(EPOCH FROM (end_date - start_date))") + `
 		FROM dag_run
 		WHERE start_date >= NOW() - INTERVAL '24 hours'
 			AND end_date IS NOT NULL
@@ -241,39 +583,105 @@ func (s *DatabaseScraper) scrapeDAGRunStats(ctx context.Context, ts pcommon.Time
 	defer rows.Close()
 	
 	count := 0
+	percentiles := make([]float64, len(s.cfg.DurationPercentiles))
 	for rows.Next() {
 		var stats DAGRunStats
-		if err := rows.Scan(
+		dest := []any{
 			&stats.DAGID,
 			&stats.State,
 			&stats.Count,
 			&stats.AvgDuration,
 			&stats.MaxDuration,
-		); err != nil {
+		}
+		for i := range percentiles {
+			dest = append(dest, &percentiles[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			s.drops.Record(SignalMetrics, DropReasonScanError, 1,
+				zap.String("query", "dag_run_stats"), zap.Error(err))
 			continue
 		}
 		
 		s.mb.RecordDAGRunCountDB(stats.Count, stats.DAGID, stats.State, time.Now())
 		
-		if stats.AvgDuration > 0 {
+		if stats.AvgDuration > 0 && !s.cfg.DurationHistograms {
 			s.mb.RecordDAGRunAvgDuration(stats.AvgDuration, stats.DAGID, stats.State, time.Now())
+			for i, p := range s.cfg.DurationPercentiles {
+				s.mb.RecordDAGRunDurationPercentile(percentiles[i], p, stats.DAGID, stats.State, time.Now())
+			}
 		}
 		count++
 	}
 	
-	s.settings.Logger.Info("Scraped DAG run stats from DB", zap.Int("records", count))
+	s.settings.Logger.Debug("Scraped DAG run stats from DB", zap.Int("records", count))
+	return rows.Err()
+}
+
+// scrapeDAGRunQueueStats reports how long runs waited between being queued
+// and starting, which separates scheduler-side queuing from task queuing
+func (s *DatabaseScraper) scrapeDAGRunQueueStats(ctx context.Context, ts pcommon.Timestamp) error {
+	if s.queuedAtMissing {
+		return nil
+	}
+	
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+	
+	query := `
+		SELECT 
+			dag_id,
+			AVG(EXTRACT(EPOCH FROM (start_date - queued_at))) as avg_queue_duration
+		FROM dag_run
+		WHERE start_date >= NOW() - INTERVAL '24 hours'
+			AND queued_at IS NOT NULL
+			AND start_date >= queued_at
+		GROUP BY dag_id
+	`
+	
+	var rows *sql.Rows
+	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "query dag run queue time", func() error {
+		var err error
+		rows, err = s.db.QueryContext(ctx, query)
+		return err
+	})
+	
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "42703" {
+			s.queuedAtMissing = true
+			s.settings.Logger.Info("dag_run.queued_at not available (Airflow < 2.2), skipping queue duration metrics")
+			return nil
+		}
+		return err
+	}
+	defer rows.Close()
+	
+	for rows.Next() {
+		var dagID string
+		var avgQueueDuration float64
+		if err := rows.Scan(&dagID, &avgQueueDuration); err != nil {
+			s.drops.Record(SignalMetrics, DropReasonScanError, 1,
+				zap.String("query", "dag_run_queue_stats"), zap.Error(err))
+			continue
+		}
+		
+		s.mb.RecordDAGRunQueueDuration(avgQueueDuration, dagID, time.Now())
+	}
+	
 	return rows.Err()
 }
 
 func (s *DatabaseScraper) scrapeSchedulerMetrics(ctx context.Context, ts pcommon.Timestamp) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+	
 	query := `
 		SELECT 
 			COUNT(*) FILTER (WHERE state = 'scheduled') as scheduled,
 			COUNT(*) FILTER (WHERE state = 'queued') as queued,
 			COUNT(*) FILTER (WHERE state = 'running') as running,
 			COUNT(*) FILTER (WHERE state = 'success' AND start_date >= NOW() - INTERVAL '24 hours') as success_24h,
-			COUNT(*) FILTER (WHERE state = 'failed' AND start_date >= NOW() - INTERVAL '24 hours') as failed_24h,
-			COUNT(*) FILTER (WHERE state = 'running' AND start_date < NOW() - INTERVAL '1 hour') as orphaned
+			COUNT(*) FILTER (WHERE state = 'failed' AND start_date >= NOW() - INTERVAL '24 hours') as failed_24h
 		FROM task_instance
 	`
 	
@@ -285,7 +693,6 @@ func (s *DatabaseScraper) scrapeSchedulerMetrics(ctx context.Context, ts pcommon
 			&metrics.RunningTasks,
 			&metrics.SuccessTasks24h,
 			&metrics.FailedTasks24h,
-			&metrics.OrphanedTasks,
 		)
 	})
 	
@@ -298,16 +705,600 @@ func (s *DatabaseScraper) scrapeSchedulerMetrics(ctx context.Context, ts pcommon
 	s.mb.RecordSchedulerTasksRunning(metrics.RunningTasks, time.Now())
 	s.mb.RecordSchedulerTasksSuccess24h(metrics.SuccessTasks24h, time.Now())
 	s.mb.RecordSchedulerTasksFailed24h(metrics.FailedTasks24h, time.Now())
-	s.mb.RecordSchedulerTasksOrphaned(metrics.OrphanedTasks, time.Now())
 	
-	s.settings.Logger.Info("Scraped scheduler metrics from DB",
+	s.settings.Logger.Debug("Scraped scheduler metrics from DB",
 		zap.Int64("queued", metrics.QueuedTasks),
 		zap.Int64("running", metrics.RunningTasks))
 	
 	return nil
 }
 
+// scrapeOrphanedTasks counts task instances that have been running longer
+// than the configured threshold, per DAG and pool. The total excludes DAGs
+// that are expected to run that long.
+func (s *DatabaseScraper) scrapeOrphanedTasks(ctx context.Context, ts pcommon.Timestamp) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+	
+	query := `
+		SELECT dag_id, COALESCE(pool, ''), COUNT(*)
+		FROM task_instance
+		WHERE state = 'running'
+			AND start_date < NOW() - make_interval(secs => $1)
+		GROUP BY dag_id, pool
+	`
+	
+	type orphanedGroup struct {
+		dagID string
+		pool  string
+		count int64
+	}
+	var groups []orphanedGroup
+	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "query orphaned tasks", func() error {
+		groups = groups[:0]
+		rows, err := s.db.QueryContext(ctx, query, s.cfg.OrphanedTaskThreshold.Seconds())
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		
+		for rows.Next() {
+			var g orphanedGroup
+			if err := rows.Scan(&g.dagID, &g.pool, &g.count); err != nil {
+				return err
+			}
+			groups = append(groups, g)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return err
+	}
+	
+	total := int64(0)
+	for _, g := range groups {
+		if matchAny(s.cfg.OrphanedTaskExcludeDAGs, g.dagID) {
+			continue
+		}
+		total += g.count
+		s.mb.RecordDAGTasksOrphaned(g.count, g.dagID, g.pool, ts)
+	}
+	s.mb.RecordSchedulerTasksOrphaned(total, time.Now())
+	return nil
+}
+
+// counterSettleLag keeps the incremental counter windows this far behind the
+// database clock. A row whose end_date is already past when it commits, or
+// that a worker with a slightly slow clock stamped, still lands in a later
+// window instead of one that was already read.
+const counterSettleLag = time.Minute
+
+// settledUpperBound returns the end of the next incremental counter window,
+// counterSettleLag before the database's clock. The receiver's clock is not
+// comparable with the dates workers write.
+func (s *DatabaseScraper) settledUpperBound(ctx context.Context) (time.Time, error) {
+	var to time.Time
+	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "query database clock", func() error {
+		return s.db.QueryRowContext(ctx, `SELECT NOW() - make_interval(secs => $1)`,
+			counterSettleLag.Seconds()).Scan(&to)
+	})
+	return to, err
+}
+
+// scrapeTaskCounters adds task failures and retry attempts observed since the
+// previous scrape to running totals, so the emitted sums are true cumulative
+// counters anchored at the scraper start time. Retries are task instances
+// that started a try after their first in the window, so a task retried
+// several times between two windows counts once.
+func (s *DatabaseScraper) scrapeTaskCounters(ctx context.Context) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+	
+	to, err := s.settledUpperBound(ctx)
+	if err != nil {
+		return err
+	}
+	// The first scrape only sets where counting starts
+	if s.counterWatermark.IsZero() {
+		s.counterWatermark = to
+		return nil
+	}
+	
+	query := `
+		SELECT 
+			dag_id,
+			COUNT(*) FILTER (WHERE state = 'failed' AND end_date > $1 AND end_date <= $2) as failures,
+			COUNT(*) FILTER (WHERE try_number > 1 AND start_date > $1 AND start_date <= $2) as retries
+		FROM task_instance
+		WHERE (end_date > $1 AND end_date <= $2)
+			OR (start_date > $1 AND start_date <= $2)
+		GROUP BY dag_id
+	`
+	
+	from := s.counterWatermark
+	
+	var rows *sql.Rows
+	err = RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "query task counters", func() error {
+		var err error
+		rows, err = s.db.QueryContext(ctx, query, from, to)
+		return err
+	})
+	
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	
+	failures := make(map[string]int64)
+	retries := make(map[string]int64)
+	for rows.Next() {
+		var dagID string
+		var failed, retried int64
+		if err := rows.Scan(&dagID, &failed, &retried); err != nil {
+			s.drops.Record(SignalMetrics, DropReasonScanError, 1,
+				zap.String("query", "task_counters"), zap.Error(err))
+			continue
+		}
+		failures[dagID] = failed
+		retries[dagID] = retried
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	
+	// Only advance once the whole window was read, so nothing is counted twice
+	for dagID, count := range failures {
+		s.failureTotals[dagID] += count
+	}
+	for dagID, count := range retries {
+		s.retryTotals[dagID] += count
+	}
+	s.counterWatermark = to
+	
+	for dagID, total := range s.failureTotals {
+		s.mb.RecordTaskInstanceFailures(total, dagID, s.startTime, to)
+	}
+	for dagID, total := range s.retryTotals {
+		s.mb.RecordTaskInstanceRetries(total, dagID, s.startTime, to)
+	}
+	
+	return nil
+}
+
+// scrapeTaskReschedules adds the task_reschedule rows written since the
+// previous scrape to running totals per DAG and task. Each row is a sensor in
+// reschedule mode giving up its slot; the delay is how long it asked to wait
+// before the next poke. Sensors that reschedule often with short delays load
+// the scheduler without making progress.
+func (s *DatabaseScraper) scrapeTaskReschedules(ctx context.Context) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+	
+	to, err := s.settledUpperBound(ctx)
+	if err != nil {
+		return err
+	}
+	if s.rescheduleWatermark.IsZero() {
+		s.rescheduleWatermark = to
+		return nil
+	}
+	
+	query := `
+		SELECT
+			dag_id,
+			task_id,
+			COUNT(*) as reschedules,
+			COALESCE(SUM(EXTRACT(EPOCH FROM (reschedule_date - end_date))), 0) as delay
+		FROM task_reschedule
+		WHERE end_date > $1 AND end_date <= $2
+		GROUP BY dag_id, task_id
+	`
+	
+	from := s.rescheduleWatermark
+	
+	var rows *sql.Rows
+	err = RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "query task reschedules", func() error {
+		var err error
+		rows, err = s.db.QueryContext(ctx, query, from, to)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	
+	reschedules := make(map[taskKey]int64)
+	delays := make(map[taskKey]float64)
+	for rows.Next() {
+		var key taskKey
+		var count int64
+		var delay float64
+		if err := rows.Scan(&key.dagID, &key.taskID, &count, &delay); err != nil {
+			s.drops.Record(SignalMetrics, DropReasonScanError, 1,
+				zap.String("query", "task_reschedules"), zap.Error(err))
+			continue
+		}
+		reschedules[key] = count
+		delays[key] = delay
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	
+	// Only advance once the whole window was read, so nothing is counted twice
+	for key, count := range reschedules {
+		s.rescheduleTotals[key] += count
+		s.rescheduleDelayTotals[key] += delays[key]
+	}
+	s.rescheduleWatermark = to
+	
+	for key, total := range s.rescheduleTotals {
+		s.mb.RecordTaskReschedules(total, key.dagID, key.taskID, s.startTime, to)
+		s.mb.RecordTaskRescheduleDelay(s.rescheduleDelayTotals[key], key.dagID, key.taskID, s.startTime, to)
+	}
+	
+	return nil
+}
+
+// scrapeDAGProcessing reports the DAG bag size and import errors as the DAG
+// processor last wrote them to the metadata database
+func (s *DatabaseScraper) scrapeDAGProcessing(ctx context.Context, ts pcommon.Timestamp) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+	
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM dag WHERE is_active) as dagbag_size,
+			(SELECT COUNT(*) FROM import_error) as import_errors
+	`
+	
+	var dagbagSize, importErrors int64
+	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "query DAG processing stats", func() error {
+		return s.db.QueryRowContext(ctx, query).Scan(&dagbagSize, &importErrors)
+	})
+	if err != nil {
+		return err
+	}
+	
+	s.mb.RecordDAGProcessingDagbagSize(dagbagSize, ts)
+	s.mb.RecordDAGProcessingImportErrors(importErrors, ts)
+	return nil
+}
+
+// scrapeDAGFileParseAge reports how long ago each DAG file was last parsed.
+// A file whose age keeps growing is skipped or starved by the DAG processor.
+func (s *DatabaseScraper) scrapeDAGFileParseAge(ctx context.Context, ts pcommon.Timestamp) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+	
+	query := `
+		SELECT
+			fileloc,
+			EXTRACT(EPOCH FROM (NOW() - MAX(last_parsed_time))) as age,
+			COUNT(*) as dags,
+			COUNT(*) OVER () as total_files
+		FROM dag
+		WHERE is_active AND last_parsed_time IS NOT NULL
+		GROUP BY fileloc
+		ORDER BY age DESC
+		LIMIT $1
+	`
+	
+	var rows *sql.Rows
+	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "query DAG file parse times", func() error {
+		var err error
+		rows, err = s.db.QueryContext(ctx, query, dagFileLimit)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	
+	totalFiles := int64(0)
+	for rows.Next() {
+		var fileloc string
+		var age float64
+		var dags int64
+		if err := rows.Scan(&fileloc, &age, &dags, &totalFiles); err != nil {
+			s.drops.Record(SignalMetrics, DropReasonScanError, 1,
+				zap.String("query", "dag_file_parse_age"), zap.Error(err))
+			continue
+		}
+		s.mb.RecordDAGFileParseAge(age, fileloc, dags, ts)
+	}
+	
+	if totalFiles > dagFileLimit {
+		s.drops.Record(SignalMetrics, DropReasonTruncated, totalFiles-dagFileLimit,
+			zap.String("query", "dag_file_parse_age"), zap.Int("limit", dagFileLimit))
+	}
+	return rows.Err()
+}
+
+// scrapeJobs reports the heartbeat age of every running scheduler, triggerer
+// and DAG processor job, and how many of each are alive. With several HA
+// schedulers, one silently dying only shows up as a drop in the alive count.
+// Jobs left running without a heartbeat for a day are ignored.
+func (s *DatabaseScraper) scrapeJobs(ctx context.Context, ts pcommon.Timestamp) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+	
+	query := `
+		SELECT
+			id,
+			job_type,
+			COALESCE(hostname, ''),
+			EXTRACT(EPOCH FROM (NOW() - latest_heartbeat)) as age
+		FROM job
+		WHERE state = 'running'
+			AND job_type = ANY($1)
+			AND latest_heartbeat >= NOW() - INTERVAL '1 day'
+	`
+	
+	var rows *sql.Rows
+	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "query jobs", func() error {
+		var err error
+		rows, err = s.db.QueryContext(ctx, query, monitoredJobTypes)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	
+	alive := make(map[string]int64, len(monitoredJobTypes))
+	for _, jobType := range monitoredJobTypes {
+		alive[jobType] = 0
+	}
+	for rows.Next() {
+		var id int64
+		var jobType, hostname string
+		var age float64
+		if err := rows.Scan(&id, &jobType, &hostname, &age); err != nil {
+			s.drops.Record(SignalMetrics, DropReasonScanError, 1,
+				zap.String("query", "jobs"), zap.Error(err))
+			continue
+		}
+		s.mb.RecordJobHeartbeatAge(age, id, jobType, hostname, ts)
+		if age <= jobHeartbeatThreshold.Seconds() {
+			alive[jobType]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	
+	for jobType, count := range alive {
+		s.mb.RecordJobsAlive(count, jobType, ts)
+	}
+	return nil
+}
+
+// scrapeDatasetEvents reports the events of each dataset over the last 24h
+// and how long ago its last event was. A dataset whose age keeps growing
+// leaves the DAGs scheduled on it waiting.
+func (s *DatabaseScraper) scrapeDatasetEvents(ctx context.Context, ts pcommon.Timestamp) error {
+	if s.datasetsMissing {
+		return nil
+	}
+	
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+	
+	query := `
+		SELECT
+			d.uri,
+			COUNT(e.id) FILTER (WHERE e.timestamp >= NOW() - INTERVAL '24 hours') as events,
+			EXTRACT(EPOCH FROM (NOW() - MAX(e.timestamp))) as age,
+			COUNT(*) OVER () as total_datasets
+		FROM dataset d
+		LEFT JOIN dataset_event e ON e.dataset_id = d.id
+		GROUP BY d.uri
+		ORDER BY MAX(e.timestamp) ASC NULLS FIRST
+		LIMIT $1
+	`
+	
+	var rows *sql.Rows
+	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "query dataset events", func() error {
+		var err error
+		rows, err = s.db.QueryContext(ctx, query, datasetLimit)
+		return err
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "42P01" {
+			s.datasetsMissing = true
+			s.settings.Logger.Info("dataset tables not available (Airflow < 2.4), skipping dataset event metrics")
+			return nil
+		}
+		return err
+	}
+	defer rows.Close()
+	
+	totalDatasets := int64(0)
+	for rows.Next() {
+		var uri string
+		var events int64
+		var age sql.NullFloat64
+		if err := rows.Scan(&uri, &events, &age, &totalDatasets); err != nil {
+			s.drops.Record(SignalMetrics, DropReasonScanError, 1,
+				zap.String("query", "dataset_events"), zap.Error(err))
+			continue
+		}
+		s.mb.RecordDatasetEvents(events, uri, ts)
+		// Datasets that never had an event have no age
+		if age.Valid {
+			s.mb.RecordDatasetEventAge(age.Float64, uri, ts)
+		}
+	}
+	
+	if totalDatasets > datasetLimit {
+		s.drops.Record(SignalMetrics, DropReasonTruncated, totalDatasets-datasetLimit,
+			zap.String("query", "dataset_events"), zap.Int("limit", datasetLimit))
+	}
+	return rows.Err()
+}
+
+// scrapeSerializedDAGAge reports how long ago each active DAG was last
+// serialized. The scheduler only sees the serialized form, so a DAG whose age
+// keeps growing after a deploy never had its code change picked up.
+func (s *DatabaseScraper) scrapeSerializedDAGAge(ctx context.Context, ts pcommon.Timestamp) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+	
+	query := `
+		SELECT
+			sd.dag_id,
+			EXTRACT(EPOCH FROM (NOW() - sd.last_updated)) as age,
+			COUNT(*) OVER () as total_dags
+		FROM serialized_dag sd
+		JOIN dag d ON d.dag_id = sd.dag_id
+		WHERE d.is_active
+		ORDER BY age DESC
+		LIMIT $1
+	`
+	
+	var rows *sql.Rows
+	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "query serialized DAG age", func() error {
+		var err error
+		rows, err = s.db.QueryContext(ctx, query, serializedDAGLimit)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	
+	totalDAGs := int64(0)
+	for rows.Next() {
+		var dagID string
+		var age float64
+		if err := rows.Scan(&dagID, &age, &totalDAGs); err != nil {
+			s.drops.Record(SignalMetrics, DropReasonScanError, 1,
+				zap.String("query", "serialized_dag_age"), zap.Error(err))
+			continue
+		}
+		s.mb.RecordDAGSerializationAge(age, dagID, ts)
+	}
+	
+	if totalDAGs > serializedDAGLimit {
+		s.drops.Record(SignalMetrics, DropReasonTruncated, totalDAGs-serializedDAGLimit,
+			zap.String("query", "serialized_dag_age"), zap.Int("limit", serializedDAGLimit))
+	}
+	return rows.Err()
+}
+
+// scrapeTableStats reports the size and estimated row count of the metadata
+// tables. Row counts come from the statistics collector rather than COUNT(*),
+// which would scan tables that are already too large.
+func (s *DatabaseScraper) scrapeTableStats(ctx context.Context, ts pcommon.Timestamp) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+	
+	tables := s.cfg.TableStatsTables
+	if len(tables) == 0 {
+		tables = DefaultTableStatsTables
+	}
+	
+	query := `
+		SELECT
+			schemaname,
+			relname,
+			n_live_tup,
+			pg_total_relation_size(relid)
+		FROM pg_stat_user_tables
+		WHERE relname = ANY($1)
+	`
+	
+	var rows *sql.Rows
+	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "query table stats", func() error {
+		var err error
+		rows, err = s.db.QueryContext(ctx, query, tables)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	
+	for rows.Next() {
+		var schema, table string
+		var liveRows, size int64
+		if err := rows.Scan(&schema, &table, &liveRows, &size); err != nil {
+			s.drops.Record(SignalMetrics, DropReasonScanError, 1,
+				zap.String("query", "table_stats"), zap.Error(err))
+			continue
+		}
+		s.mb.RecordDatabaseTableRows(liveRows, schema, table, ts)
+		s.mb.RecordDatabaseTableSize(size, schema, table, ts)
+	}
+	return rows.Err()
+}
+
+// scrapeRetention reports how many rows of retentionTables are older than
+// the retention period. A growing count means `airflow db clean` is overdue.
+// Tables are counted exactly, so each gets its own query timeout.
+func (s *DatabaseScraper) scrapeRetention(ctx context.Context, ts pcommon.Timestamp) error {
+	cutoff := time.Now().Add(-s.cfg.RetentionPeriod)
+	var errs []error
+	for table, query := range retentionTables {
+		count, err := s.countExpiredRows(ctx, table, query, cutoff)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", table, err))
+			continue
+		}
+		s.mb.RecordDatabaseTableExpiredRows(count, table, ts)
+	}
+	return errors.Join(errs...)
+}
+
+func (s *DatabaseScraper) countExpiredRows(ctx context.Context, table, query string, cutoff time.Time) (int64, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+	
+	var count int64
+	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "query expired "+table+" rows", func() error {
+		return s.db.QueryRowContext(ctx, query, cutoff).Scan(&count)
+	})
+	return count, err
+}
+
+// scrapeLogGrowth reports how fast rows are added to the log table, which
+// usually grows fastest of all metadata tables. The rate is derived from the
+// highest id, which the primary key index answers without a scan, so the
+// first scrape only takes the baseline.
+func (s *DatabaseScraper) scrapeLogGrowth(ctx context.Context, ts pcommon.Timestamp) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+	
+	var maxID int64
+	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "query log table growth", func() error {
+		return s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM log`).Scan(&maxID)
+	})
+	if err != nil {
+		return err
+	}
+	
+	now := time.Now()
+	prevID, prevTime := s.logMaxID, s.logMaxIDTime
+	s.logMaxID, s.logMaxIDTime = maxID, now
+	
+	// Skip the first scrape and an id that went backwards after a restore
+	if prevTime.IsZero() || maxID < prevID {
+		return nil
+	}
+	elapsed := now.Sub(prevTime).Seconds()
+	if elapsed <= 0 {
+		return nil
+	}
+	s.mb.RecordDBLogGrowthRate(float64(maxID-prevID)/elapsed, ts)
+	return nil
+}
+
 func (s *DatabaseScraper) scrapeSLAMisses(ctx context.Context, ts pcommon.Timestamp) error {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+	
 	query := `
 		SELECT 
 			dag_id,
@@ -334,6 +1325,8 @@ func (s *DatabaseScraper) scrapeSLAMisses(ctx context.Context, ts pcommon.Timest
 		var dagID string
 		var count int64
 		if err := rows.Scan(&dagID, &count); err != nil {
+			s.drops.Record(SignalMetrics, DropReasonScanError, 1,
+				zap.String("query", "sla_miss"), zap.Error(err))
 			continue
 		}
 		
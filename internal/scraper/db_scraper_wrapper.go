@@ -5,6 +5,7 @@ package scraper
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -16,37 +17,77 @@ import (
 type DatabaseScraperWrapper struct {
 	scraper *DatabaseScraper
 	health  *ScraperHealth
-	once    sync.Once
+	status  *StatusReporter
+	strict  bool
+	host    component.Host
+	mu      sync.Mutex
 	started bool
 }
 
-func NewDatabaseScraperWrapper(scraper *DatabaseScraper) *DatabaseScraperWrapper {
+// NewDatabaseScraperWrapper wraps the database scraper with health tracking.
+// With strict set, a failed connection fails receiver startup; otherwise the
+// connection is retried on every scrape until it succeeds.
+func NewDatabaseScraperWrapper(scraper *DatabaseScraper, status *StatusReporter, strict bool) *DatabaseScraperWrapper {
+	health := NewScraperHealth(scraper.name(), scraper.settings)
+	health.SetStatusReporter(status)
+	health.SetSummaryInterval(scraper.cfg.SummaryLogInterval)
 	return &DatabaseScraperWrapper{
 		scraper: scraper,
-		health:  NewScraperHealth("database", scraper.settings.Logger),
+		health:  health,
+		status:  status,
+		strict:  strict,
 	}
 }
 
-func (w *DatabaseScraperWrapper) Scrape(ctx context.Context) (pmetric.Metrics, error) {
-	// Ensure Start is called before first scrape
-	w.once.Do(func() {
-		if err := w.scraper.Start(ctx, component.Host(nil)); err != nil {
-			w.health.RecordScrape(0, err)
-			w.scraper.settings.Logger.Error("Failed to start database scraper", zap.Error(err))
-		} else {
-			w.started = true
-		}
-	})
+func (w *DatabaseScraperWrapper) Start(ctx context.Context, host component.Host) error {
+	w.status.SetHost(host)
+	w.host = host
 	
-	if !w.started {
-		return pmetric.NewMetrics(), nil
+	if !w.strict {
+		// Connect lazily so an unreachable database does not block startup
+		return nil
+	}
+	if err := w.start(ctx); err != nil {
+		return fmt.Errorf("failed to start database scraper: %w", err)
+	}
+	return nil
+}
+
+// start connects once; concurrent callers wait for the first attempt
+func (w *DatabaseScraperWrapper) start(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	
+	if w.started {
+		return nil
+	}
+	if err := w.scraper.Start(ctx, w.host); err != nil {
+		return err
+	}
+	w.started = true
+	return nil
+}
+
+func (w *DatabaseScraperWrapper) Scrape(ctx context.Context) (pmetric.Metrics, error) {
+	// Retry the connection on every scrape until it succeeds
+	if err := w.start(ctx); err != nil {
+		w.health.RecordScrape(0, err)
+		w.scraper.settings.Logger.Error("Failed to start database scraper, retrying next scrape", zap.Error(err))
+		
+		metrics := pmetric.NewMetrics()
+		w.health.AppendMetrics(metrics, time.Now())
+		return metrics, err
 	}
 	
 	// Use health tracking wrapper
 	metrics, err := w.health.WithScrapeTracking(ctx, w.scraper.Scrape)
 	
 	// Add health metrics to output
-	w.health.EmitMetrics(w.scraper.mb, time.Now())
+	w.health.AppendMetrics(metrics, time.Now())
 	
 	return metrics, err
 }
+
+func (w *DatabaseScraperWrapper) Shutdown(ctx context.Context) error {
+	return w.scraper.Shutdown(ctx)
+}
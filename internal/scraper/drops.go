@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Signals a drop can be attributed to
+const (
+	SignalMetrics = "metrics"
+	SignalLogs    = "logs"
+)
+
+// Reasons the receiver intentionally did not emit data
+const (
+	DropReasonMissingID       = "missing_id"
+	DropReasonScanError       = "scan_error"
+	DropReasonParseError      = "parse_error"
+	DropReasonUnsupportedType = "unsupported_type"
+	DropReasonTruncated       = "truncated"
+	// DropReasonFiltered counts records an event filter excluded
+	DropReasonFiltered = "filtered"
+	// DropReasonRejected counts records the pipeline permanently refused
+	DropReasonRejected = "rejected"
+	// DropReasonCollapsed counts points that disabled attributes collapsed
+	// into another point without merging them
+	DropReasonCollapsed = "collapsed"
+)
+
+type dropKey struct {
+	signal string
+	reason string
+}
+
+// DropTracker accounts for every item the receiver chose not to emit, so
+// users can reconcile what Airflow produced with what reached the pipeline
+type DropTracker struct {
+	mu        sync.Mutex
+	logger    *zap.Logger
+	startTime time.Time
+	counts    map[dropKey]int64
+}
+
+func NewDropTracker(logger *zap.Logger) *DropTracker {
+	return &DropTracker{
+		logger:    logger,
+		startTime: time.Now(),
+		counts:    make(map[dropKey]int64),
+	}
+}
+
+// Record adds count dropped items for the given signal and reason. Extra
+// fields are only used for the debug log line.
+func (d *DropTracker) Record(signal, reason string, count int64, fields ...zap.Field) {
+	if d == nil || count <= 0 {
+		return
+	}
+
+	d.mu.Lock()
+	d.counts[dropKey{signal: signal, reason: reason}] += count
+	d.mu.Unlock()
+
+	d.logger.Debug("Dropped data",
+		append([]zap.Field{
+			zap.String("signal", signal),
+			zap.String("reason", reason),
+			zap.Int64("count", count),
+		}, fields...)...)
+}
+
+// EmitMetrics adds the cumulative drop counters to the metrics builder
+func (d *DropTracker) EmitMetrics(mb *MetricsBuilder, ts time.Time) {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for key, count := range d.counts {
+		mb.RecordReceiverDropped(count, key.signal, key.reason, d.startTime, ts)
+	}
+}
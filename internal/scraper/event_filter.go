@@ -0,0 +1,41 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import "path"
+
+// EventFilter selects log table events by name. Patterns use shell glob
+// syntax, so "cli_*" matches every CLI command.
+type EventFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// Allows reports whether an event passes the filter. An empty include list
+// allows everything, and exclusions take precedence over inclusions.
+func (f EventFilter) Allows(event string) bool {
+	if len(f.Include) > 0 && !matchAny(f.Include, event) {
+		return false
+	}
+	return !matchAny(f.Exclude, event)
+}
+
+// ValidateEventPatterns checks that every pattern is a well-formed glob
+func ValidateEventPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func matchAny(patterns []string, event string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, event); ok {
+			return true
+		}
+	}
+	return false
+}
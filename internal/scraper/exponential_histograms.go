@@ -0,0 +1,266 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// expHistogramScale is the scale of the exponential duration histograms.
+// Each bucket is 2^(1/8) wider than the previous one, about 9% relative error,
+// and a second to a day spans some 130 buckets.
+const expHistogramScale = 3
+
+// expHistogramLogBase is ln(2^(2^-scale)), dividing a duration's natural log
+// by it gives its bucket index
+var expHistogramLogBase = math.Ln2 / math.Exp2(expHistogramScale)
+
+// durationGroup is a series of the database duration histograms; taskID is
+// empty for DAG runs
+type durationGroup struct {
+	dagID  string
+	taskID string
+	state  string
+}
+
+// expHistogram is an exponential histogram assembled from per-bucket rows
+type expHistogram struct {
+	buckets   map[int32]uint64
+	zeroCount uint64
+	count     uint64
+	sum       float64
+	min       float64
+	max       float64
+}
+
+// expHistogramFor returns the histogram for key, adding an empty one if needed
+func expHistogramFor(histograms map[durationGroup]*expHistogram, key durationGroup) *expHistogram {
+	h, ok := histograms[key]
+	if !ok {
+		h = &expHistogram{
+			buckets: make(map[int32]uint64),
+			min:     math.Inf(1),
+			max:     math.Inf(-1),
+		}
+		histograms[key] = h
+	}
+	return h
+}
+
+// add merges a bucket row; an invalid bucket is the zero bucket
+func (h *expHistogram) add(bucket sql.NullInt32, count int64, sum, minValue, maxValue float64) {
+	if bucket.Valid {
+		h.buckets[bucket.Int32] += uint64(count)
+	} else {
+		h.zeroCount += uint64(count)
+	}
+	h.count += uint64(count)
+	h.sum += sum
+	h.min = math.Min(h.min, minValue)
+	h.max = math.Max(h.max, maxValue)
+}
+
+// copyTo fills dp's buckets, count, sum, min and max
+func (h *expHistogram) copyTo(dp pmetric.ExponentialHistogramDataPoint) {
+	dp.SetScale(expHistogramScale)
+	dp.SetCount(h.count)
+	dp.SetSum(h.sum)
+	dp.SetMin(h.min)
+	dp.SetMax(h.max)
+	dp.SetZeroCount(h.zeroCount)
+	if len(h.buckets) == 0 {
+		return
+	}
+
+	low, high := int32(math.MaxInt32), int32(math.MinInt32)
+	for index := range h.buckets {
+		low = min(low, index)
+		high = max(high, index)
+	}
+	counts := make([]uint64, high-low+1)
+	for index, count := range h.buckets {
+		counts[index-low] = count
+	}
+	dp.Positive().SetOffset(low)
+	dp.Positive().BucketCounts().FromRaw(counts)
+}
+
+// scrapeDurationHistograms reports exponential histograms of the task
+// instances and DAG runs that finished since the previous scrape. Durations
+// are bucketed by the database, so only one row per non-empty bucket is read
+// and backends can compute any quantile from the result. Windows end
+// counterSettleLag before the database's clock, like the task counters.
+func (s *DatabaseScraper) scrapeDurationHistograms(ctx context.Context) error {
+	clockCtx, cancel := s.queryContext(ctx)
+	to, err := s.settledUpperBound(clockCtx)
+	cancel()
+	if err != nil {
+		return err
+	}
+	// The first scrape only sets where counting starts
+	if s.histogramWatermark.IsZero() {
+		s.histogramWatermark = to
+		return nil
+	}
+	from := s.histogramWatermark
+
+	tasks, err := s.queryTaskDurationHistograms(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	runs, err := s.queryDAGRunDurationHistograms(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	// Only advance once both windows were read, so nothing is counted twice
+	s.histogramWatermark = to
+	for group, h := range tasks {
+		s.mb.RecordTaskInstanceDurationDistribution(h, group.dagID, group.taskID, group.state, from, to)
+	}
+	for group, h := range runs {
+		s.mb.RecordDAGRunDurationDistribution(h, group.dagID, group.state, from, to)
+	}
+	return nil
+}
+
+func (s *DatabaseScraper) queryTaskDurationHistograms(ctx context.Context, from, to time.Time) (map[durationGroup]*expHistogram, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	query := `
+		WITH durations AS (
+			SELECT
+				dag_id,
+				task_id,
+				state,
+				EXTRACT(EPOCH FROM (end_date - start_date))::float8 as duration
+			FROM task_instance
+			WHERE end_date > $2 AND end_date <= $3
+				AND start_date IS NOT NULL
+				AND end_date >= start_date
+		), groups AS (
+			SELECT
+				dag_id,
+				task_id,
+				state,
+				COUNT(*) OVER () as total_groups
+			FROM durations
+			GROUP BY dag_id, task_id, state
+			ORDER BY COUNT(*) DESC
+			LIMIT $4
+		)
+		SELECT
+			d.dag_id,
+			d.task_id,
+			d.state,
+			CASE WHEN d.duration > 0 THEN CEIL(LN(d.duration) / $1)::int - 1 END as bucket,
+			COUNT(*) as count,
+			SUM(d.duration) as sum,
+			MIN(d.duration) as min,
+			MAX(d.duration) as max,
+			g.total_groups
+		FROM durations d
+		JOIN groups g ON g.dag_id = d.dag_id AND g.task_id = d.task_id AND g.state = d.state
+		GROUP BY d.dag_id, d.task_id, d.state, bucket, g.total_groups
+	`
+
+	var rows *sql.Rows
+	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "query task duration histograms", func() error {
+		var err error
+		rows, err = s.db.QueryContext(ctx, query, expHistogramLogBase, from, to, taskInstanceStatsLimit)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	histograms := make(map[durationGroup]*expHistogram)
+	totalGroups := int64(0)
+	for rows.Next() {
+		var group durationGroup
+		var bucket sql.NullInt32
+		var count int64
+		var sum, minValue, maxValue float64
+		if err := rows.Scan(&group.dagID, &group.taskID, &group.state, &bucket, &count, &sum, &minValue, &maxValue, &totalGroups); err != nil {
+			s.drops.Record(SignalMetrics, DropReasonScanError, 1,
+				zap.String("query", "task_duration_histograms"), zap.Error(err))
+			continue
+		}
+		expHistogramFor(histograms, group).add(bucket, count, sum, minValue, maxValue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if totalGroups > taskInstanceStatsLimit {
+		s.drops.Record(SignalMetrics, DropReasonTruncated, totalGroups-taskInstanceStatsLimit,
+			zap.String("query", "task_duration_histograms"), zap.Int("limit", taskInstanceStatsLimit))
+	}
+	return histograms, nil
+}
+
+func (s *DatabaseScraper) queryDAGRunDurationHistograms(ctx context.Context, from, to time.Time) (map[durationGroup]*expHistogram, error) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	query := `
+		WITH durations AS (
+			SELECT
+				dag_id,
+				state,
+				EXTRACT(EPOCH FROM (end_date - start_date))::float8 as duration
+			FROM dag_run
+			WHERE end_date > $2 AND end_date <= $3
+				AND start_date IS NOT NULL
+				AND end_date >= start_date
+		)
+		SELECT
+			dag_id,
+			state,
+			CASE WHEN duration > 0 THEN CEIL(LN(duration) / $1)::int - 1 END as bucket,
+			COUNT(*) as count,
+			SUM(duration) as sum,
+			MIN(duration) as min,
+			MAX(duration) as max
+		FROM durations
+		GROUP BY dag_id, state, bucket
+	`
+
+	var rows *sql.Rows
+	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, "query dag run duration histograms", func() error {
+		var err error
+		rows, err = s.db.QueryContext(ctx, query, expHistogramLogBase, from, to)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	histograms := make(map[durationGroup]*expHistogram)
+	for rows.Next() {
+		var group durationGroup
+		var bucket sql.NullInt32
+		var count int64
+		var sum, minValue, maxValue float64
+		if err := rows.Scan(&group.dagID, &group.state, &bucket, &count, &sum, &minValue, &maxValue); err != nil {
+			s.drops.Record(SignalMetrics, DropReasonScanError, 1,
+				zap.String("query", "dag_run_duration_histograms"), zap.Error(err))
+			continue
+		}
+		expHistogramFor(histograms, group).add(bucket, count, sum, minValue, maxValue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return histograms, nil
+}
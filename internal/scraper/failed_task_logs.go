@@ -0,0 +1,197 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+// DefaultFailedTaskLogMaxBytes is how much of the end of a failed task's log is kept
+const DefaultFailedTaskLogMaxBytes = 16 * 1024
+
+// maxFailedTaskLogs bounds the attempts, and so the log requests, of a scrape
+const maxFailedTaskLogs = 100
+
+// taskInstanceKey identifies one attempt of a task instance
+type taskInstanceKey struct {
+	dagID    string
+	runID    string
+	taskID   string
+	mapIndex int
+	try      int
+}
+
+// FailedTaskLogWatcher polls for newly failed task instances and emits the
+// tail of each attempt's log, so the traceback reaches the log backend
+// without opening the Airflow UI
+type FailedTaskLogWatcher struct {
+	rest     *RESTAPIScraper
+	settings receiver.Settings
+	maxBytes int
+	
+	// since is the end_date watermark; seen holds attempts at or after it
+	since time.Time
+	seen  map[taskInstanceKey]time.Time
+	
+	// pending holds the attempts of the last scrape until they are consumed
+	pending      map[taskInstanceKey]time.Time
+	pendingSince time.Time
+	// pendingSkipped are the attempts past the limit the watermark skips
+	pendingSkipped int
+}
+
+func NewFailedTaskLogWatcher(rest *RESTAPIScraper, settings receiver.Settings, maxBytes int) *FailedTaskLogWatcher {
+	if maxBytes <= 0 {
+		maxBytes = DefaultFailedTaskLogMaxBytes
+	}
+	return &FailedTaskLogWatcher{
+		rest:     rest,
+		settings: settings,
+		maxBytes: maxBytes,
+		seen:     make(map[taskInstanceKey]time.Time),
+	}
+}
+
+func (w *FailedTaskLogWatcher) Start(ctx context.Context, host component.Host) error {
+	// Only report failures from now on rather than the whole history
+	w.since = time.Now()
+	return w.rest.Start(ctx, host)
+}
+
+func (w *FailedTaskLogWatcher) Scrape(ctx context.Context) (plog.Logs, error) {
+	// Resource attributes come from the shared deployment metadata
+	w.rest.LoadInfo(ctx)
+	
+	lb := NewFailedTaskLogsBuilder(w.settings)
+	lb.SetRedactor(w.rest.cfg.Redactor)
+	
+	// Attempts are emitted again until a scrape's records are committed
+	w.pending = nil
+	listedAt := time.Now()
+	failed, skipped, err := w.getFailedTaskInstances(ctx)
+	if err != nil {
+		return lb.Emit(), fmt.Errorf("failed to get failed task instances: %w", err)
+	}
+	
+	pending := make(map[taskInstanceKey]time.Time)
+	newest := w.since
+	for _, ti := range failed {
+		key := taskInstanceKey{dagID: ti.DAGID, runID: ti.DAGRunID, taskID: ti.TaskID, mapIndex: ti.MapIndex, try: ti.TryNumber}
+		if _, ok := w.seen[key]; ok {
+			continue
+		}
+		if _, ok := pending[key]; ok {
+			continue
+		}
+		pending[key] = ti.EndDate
+		if ti.EndDate.After(newest) {
+			newest = ti.EndDate
+		}
+		
+		content, truncated, err := w.getTaskLogTail(ctx, ti)
+		if err != nil {
+			// Still emit the failure so it is not lost with its log
+			w.settings.Logger.Warn("Failed to get task log",
+				zap.String("dag_id", ti.DAGID),
+				zap.String("task_id", ti.TaskID),
+				zap.Error(err))
+			content = fmt.Sprintf("log unavailable: %v", err)
+		}
+		lb.RecordFailedTaskLog(ti, content, truncated)
+	}
+	
+	// Attempts past the limit are skipped, not read again next scrape, so a
+	// burst of failures does not hold back the ones after it
+	if skipped > 0 {
+		newest = listedAt
+	}
+	w.pending = pending
+	w.pendingSince = newest
+	w.pendingSkipped = skipped
+	return lb.Emit(), nil
+}
+
+// Commit remembers the attempts of the last scrape and moves the watermark
+func (w *FailedTaskLogWatcher) Commit() {
+	if w.pending == nil {
+		return
+	}
+	for key, endDate := range w.pending {
+		w.seen[key] = endDate
+	}
+	w.pending = nil
+	w.rest.drops.Record(SignalLogs, DropReasonTruncated, int64(w.pendingSkipped),
+		zap.String("source", "failed_task_logs"), zap.Int("limit", maxFailedTaskLogs))
+	
+	// Forget attempts that the next query can no longer return
+	w.since = w.pendingSince
+	for key, endDate := range w.seen {
+		if endDate.Before(w.since) {
+			delete(w.seen, key)
+		}
+	}
+}
+
+// getFailedTaskInstances lists failed attempts across all DAGs and runs that
+// ended at or after the watermark, oldest first, and the number of attempts
+// past maxFailedTaskLogs
+func (w *FailedTaskLogWatcher) getFailedTaskInstances(ctx context.Context) ([]TaskInstance, int, error) {
+	path := fmt.Sprintf("/api/v1/dags/~/dagRuns/~/taskInstances?state=failed&order_by=end_date&end_date_gte=%s",
+		url.QueryEscape(w.since.UTC().Format(time.RFC3339)))
+	page, err := fetchPages(ctx, w.rest, path, maxFailedTaskLogs, func(body []byte) ([]TaskInstance, int, error) {
+		var response TaskInstancesResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, 0, err
+		}
+		return response.TaskInstances, response.TotalEntries, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return page.items, max(page.total-len(page.items), 0), nil
+}
+
+// getTaskLogTail fetches an attempt's log and keeps the last maxBytes, where
+// the traceback is
+func (w *FailedTaskLogWatcher) getTaskLogTail(ctx context.Context, ti TaskInstance) (string, bool, error) {
+	path := fmt.Sprintf("/api/v1/dags/%s/dagRuns/%s/taskInstances/%s/logs/%d?full_content=true",
+		url.PathEscape(ti.DAGID), url.PathEscape(ti.DAGRunID), url.PathEscape(ti.TaskID), ti.TryNumber)
+	if ti.MapIndex >= 0 {
+		path += fmt.Sprintf("&map_index=%d", ti.MapIndex)
+	}
+	body, err := w.rest.doRequest(ctx, path)
+	if err != nil {
+		return "", false, err
+	}
+	
+	var response TaskInstanceLogResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", false, err
+	}
+	
+	content := response.Content
+	if len(content) <= w.maxBytes {
+		return content, false, nil
+	}
+	// Start on a rune boundary so the record stays valid UTF-8
+	start := len(content) - w.maxBytes
+	for start < len(content) && !utf8.RuneStart(content[start]) {
+		start++
+	}
+	return content[start:], true, nil
+}
+
+func (w *FailedTaskLogWatcher) Shutdown(ctx context.Context) error {
+	return w.rest.Shutdown(ctx)
+}
@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ErrBadRequest is returned for a 400, which the webserver also sends for
+// query parameters its Airflow version does not know
+var ErrBadRequest = errors.New("bad request")
+
+// Field projections per list endpoint. Airflow versions differ in which lists
+// accept fields=, so each is probed on its own.
+const (
+	CapabilityDAGFields          = "dag_fields"
+	CapabilityDAGRunFields       = "dag_run_fields"
+	CapabilityTaskInstanceFields = "task_instance_fields"
+)
+
+// Fields decoded from each list, requested with fields= so the webserver
+// leaves out everything else
+var (
+	dagFields          = jsonFields(reflect.TypeOf(DAG{}))
+	dagRunFields       = jsonFields(reflect.TypeOf(DAGRun{}))
+	taskInstanceFields = jsonFields(reflect.TypeOf(TaskInstance{}))
+)
+
+// jsonFields returns the JSON names of a struct's fields
+func jsonFields(t reflect.Type) []string {
+	fields := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// withProjection calls fetch with path limited to fields. A 400 may mean the
+// webserver does not accept fields= on this list, so the call is repeated
+// without it. Only if that succeeds is the projection turned off for the
+// lifetime of the scraper; otherwise the 400 had another cause, and the
+// original error is returned.
+func withProjection[T any](s *RESTAPIScraper, capability, path string, fields []string, fetch func(path string) (T, error)) (T, error) {
+	if s.cfg.DisableFieldProjection || !s.caps.supported(capability) {
+		return fetch(path)
+	}
+
+	query := make([]string, len(fields))
+	for i, field := range fields {
+		query[i] = "fields=" + url.QueryEscape(field)
+	}
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	result, err := fetch(path + separator + strings.Join(query, "&"))
+	if !errors.Is(err, ErrBadRequest) {
+		return result, err
+	}
+	full, fullErr := fetch(path)
+	if fullErr != nil {
+		return result, err
+	}
+	if s.caps.disable(capability) {
+		s.settings.Logger.Info("Airflow API does not support field projection, requesting full objects",
+			zap.String("capability", capability),
+			zap.String("endpoint", s.cfg.Endpoint))
+	}
+	return full, nil
+}
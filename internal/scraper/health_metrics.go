@@ -8,7 +8,10 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/collector/component/componentstatus"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/scraper/scrapererror"
 	"go.uber.org/zap"
 )
 
@@ -17,6 +20,7 @@ type ScraperHealth struct {
 	mu                sync.RWMutex
 	scraperType       string
 	logger            *zap.Logger
+	settings          receiver.Settings
 	
 	// Success/failure counts
 	totalScrapes      int64
@@ -36,18 +40,101 @@ type ScraperHealth struct {
 	// Status
 	lastSuccessTime   time.Time
 	healthy           bool
+	
+	// status forwards health changes to the collector's component status
+	status            *StatusReporter
+	
+	// A summary of the scrapes since lastSummary is logged every
+	// summaryInterval, in place of per-scrape logs
+	summaryInterval   time.Duration
+	lastSummary       time.Time
+	window            scrapeWindow
 }
 
-func NewScraperHealth(scraperType string, logger *zap.Logger) *ScraperHealth {
+// scrapeWindow accumulates scrape results between summary logs
+type scrapeWindow struct {
+	scrapes    int64
+	failed     int64
+	partial    int64
+	dataPoints int64
+	duration   time.Duration
+}
+
+func NewScraperHealth(scraperType string, settings receiver.Settings) *ScraperHealth {
 	return &ScraperHealth{
 		scraperType: scraperType,
-		logger:      logger,
+		logger:      settings.Logger,
+		settings:    settings,
 		healthy:     true,
 	}
 }
 
+// SetStatusReporter makes health changes visible through the healthcheck
+// extension and its probes
+func (h *ScraperHealth) SetStatusReporter(status *StatusReporter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.status = status
+}
+
+// SetSummaryInterval enables a periodic Info summary of scrape results
+func (h *ScraperHealth) SetSummaryInterval(interval time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.summaryInterval = interval
+	h.lastSummary = time.Now()
+}
+
+// logSummary adds a scrape to the window and logs the window once the
+// summary interval has passed
+func (h *ScraperHealth) logSummary(metrics pmetric.Metrics, duration time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.summaryInterval <= 0 {
+		return
+	}
+	
+	h.window.scrapes++
+	h.window.duration += duration
+	h.window.dataPoints += int64(metrics.DataPointCount())
+	switch {
+	case scrapererror.IsPartialScrapeError(err):
+		h.window.partial++
+	case err != nil:
+		h.window.failed++
+	}
+	
+	now := time.Now()
+	if now.Sub(h.lastSummary) < h.summaryInterval {
+		return
+	}
+	h.logger.Info("Scrape summary",
+		zap.String("scraper_type", h.scraperType),
+		zap.Duration("period", now.Sub(h.lastSummary)),
+		zap.Int64("scrapes", h.window.scrapes),
+		zap.Int64("failed", h.window.failed),
+		zap.Int64("partially_failed", h.window.partial),
+		zap.Int64("data_points", h.window.dataPoints),
+		zap.Duration("avg_duration", h.window.duration/time.Duration(h.window.scrapes)))
+	h.window = scrapeWindow{}
+	h.lastSummary = now
+}
+
 // RecordScrape records the result of a scrape operation
 func (h *ScraperHealth) RecordScrape(duration time.Duration, err error) {
+	ev := h.recordScrape(duration, err)
+	h.status.Report(h.scraperType, ev)
+}
+
+// reportStatus forwards a status event to the reporter, if any
+func (h *ScraperHealth) reportStatus(ev *componentstatus.Event) {
+	h.mu.RLock()
+	status := h.status
+	h.mu.RUnlock()
+	status.Report(h.scraperType, ev)
+}
+
+func (h *ScraperHealth) recordScrape(duration time.Duration, err error) *componentstatus.Event {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	
@@ -86,6 +173,15 @@ func (h *ScraperHealth) RecordScrape(duration time.Duration, err error) {
 			h.maxScrapeDuration = duration
 		}
 	}
+	
+	switch {
+	case err != nil && IsPermanentError(err):
+		return componentstatus.NewPermanentErrorEvent(err)
+	case !h.healthy:
+		return componentstatus.NewRecoverableErrorEvent(err)
+	default:
+		return componentstatus.NewEvent(componentstatus.StatusOK)
+	}
 }
 
 // GetMetrics returns current health metrics
@@ -142,6 +238,13 @@ func (h *ScraperHealth) EmitMetrics(mb *MetricsBuilder, ts time.Time) {
 	mb.RecordScraperConsecutiveErrors(h.consecutiveErrors, h.scraperType, ts)
 }
 
+// AppendMetrics adds health metrics to an already emitted batch
+func (h *ScraperHealth) AppendMetrics(md pmetric.Metrics, ts time.Time) {
+	mb := NewMetricsBuilder(h.settings)
+	h.EmitMetrics(mb, ts)
+	mb.Emit().ResourceMetrics().MoveAndAppendTo(md.ResourceMetrics())
+}
+
 // IsHealthy returns current health status
 func (h *ScraperHealth) IsHealthy() bool {
 	h.mu.RLock()
@@ -178,6 +281,23 @@ func (h *ScraperHealth) WithScrapeTracking(ctx context.Context, fn func(context.
 	start := time.Now()
 	metrics, err := fn(ctx)
 	duration := time.Since(start)
+	h.logSummary(metrics, duration, err)
+	
+	// A partial failure that still produced data does not count against
+	// health, unless credentials were rejected. Without any data point, every
+	// sub-scrape failed, as when the webserver is down, so it is a failure.
+	if scrapererror.IsPartialScrapeError(err) && metrics.DataPointCount() > 0 {
+		ev := h.recordScrape(duration, nil)
+		if IsPermanentError(err) {
+			ev = componentstatus.NewPermanentErrorEvent(err)
+		}
+		h.reportStatus(ev)
+		h.logger.Warn("Scrape partially failed",
+			zap.String("scraper_type", h.scraperType),
+			zap.Duration("duration", duration),
+			zap.Error(err))
+		return metrics, err
+	}
 	
 	h.RecordScrape(duration, err)
 	
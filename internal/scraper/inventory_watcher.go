@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+// DAG inventory event names
+const (
+	InventoryEventDAGAdded           = "airflow.dag.added"
+	InventoryEventDAGRemoved         = "airflow.dag.removed"
+	InventoryEventDAGPaused          = "airflow.dag.paused"
+	InventoryEventDAGUnpaused        = "airflow.dag.unpaused"
+	InventoryEventDAGScheduleChanged = "airflow.dag.schedule_changed"
+)
+
+// dagSnapshot is the subset of DAG state whose changes produce events
+type dagSnapshot struct {
+	dag      DAG
+	schedule string
+}
+
+// InventoryWatcher polls the DAG list and emits a log event whenever a DAG
+// appears, disappears, is paused or unpaused, or changes schedule
+type InventoryWatcher struct {
+	rest     *RESTAPIScraper
+	settings receiver.Settings
+	previous map[string]dagSnapshot
+	// pending is the inventory of the last scrape, compared against once its
+	// events are consumed
+	pending map[string]dagSnapshot
+}
+
+func NewInventoryWatcher(rest *RESTAPIScraper, settings receiver.Settings) *InventoryWatcher {
+	return &InventoryWatcher{
+		rest:     rest,
+		settings: settings,
+	}
+}
+
+func (w *InventoryWatcher) Start(ctx context.Context, host component.Host) error {
+	return w.rest.Start(ctx, host)
+}
+
+func (w *InventoryWatcher) Scrape(ctx context.Context) (plog.Logs, error) {
+	// Resource attributes come from the shared deployment metadata
+	w.rest.LoadInfo(ctx)
+
+	lb := NewInventoryLogsBuilder(w.settings)
+	lb.SetRedactor(w.rest.cfg.Redactor)
+
+	w.pending = nil
+	dags, err := w.rest.getDags(ctx)
+	if err != nil {
+		return lb.Emit(), fmt.Errorf("failed to get DAGs: %w", err)
+	}
+
+	now := time.Now()
+	current := make(map[string]dagSnapshot, len(dags))
+	for _, dag := range dags {
+		current[dag.DAGID] = dagSnapshot{dag: dag, schedule: scheduleString(dag.ScheduleInterval)}
+	}
+
+	// The first poll reports the full inventory so downstream views start complete
+	initial := w.previous == nil
+	for dagID, snap := range current {
+		prev, existed := w.previous[dagID]
+		switch {
+		case !existed:
+			lb.RecordInventoryEvent(now, InventoryEventDAGAdded, snap.dag, snap.schedule, "", initial)
+		default:
+			if prev.dag.IsPaused != snap.dag.IsPaused {
+				event := InventoryEventDAGUnpaused
+				if snap.dag.IsPaused {
+					event = InventoryEventDAGPaused
+				}
+				lb.RecordInventoryEvent(now, event, snap.dag, snap.schedule, "", false)
+			}
+			if prev.schedule != snap.schedule {
+				lb.RecordInventoryEvent(now, InventoryEventDAGScheduleChanged, snap.dag, snap.schedule, prev.schedule, false)
+			}
+		}
+	}
+	for dagID, prev := range w.previous {
+		if _, ok := current[dagID]; !ok {
+			lb.RecordInventoryEvent(now, InventoryEventDAGRemoved, prev.dag, prev.schedule, "", false)
+		}
+	}
+
+	w.pending = current
+	logs := lb.Emit()
+	w.settings.Logger.Debug("Checked DAG inventory",
+		zap.Int("dag_count", len(dags)),
+		zap.Int("events", logs.LogRecordCount()))
+	return logs, nil
+}
+
+// Commit makes the last scrape's inventory the one later scrapes compare to
+func (w *InventoryWatcher) Commit() {
+	if w.pending != nil {
+		w.previous = w.pending
+	}
+}
+
+func (w *InventoryWatcher) Shutdown(ctx context.Context) error {
+	return w.rest.Shutdown(ctx)
+}
+
+// scheduleString renders the schedule_interval object Airflow returns, which
+// may be a cron expression, a timedelta or a relativedelta
+func scheduleString(schedule interface{}) string {
+	switch v := schedule.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]interface{}:
+		if expr, ok := v["value"].(string); ok {
+			return expr
+		}
+	}
+	encoded, err := json.Marshal(schedule)
+	if err != nil {
+		return fmt.Sprintf("%v", schedule)
+	}
+	return string(encoded)
+}
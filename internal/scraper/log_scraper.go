@@ -14,68 +14,145 @@ import (
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/receiver"
 	"go.uber.org/zap"
-	_ "github.com/lib/pq"
 )
 
+// Event log sources
+const (
+	// LogSourceDatabase reads the log table of the metadata database
+	LogSourceDatabase = "database"
+	// LogSourceRESTAPI reads /api/v1/eventLogs
+	LogSourceRESTAPI = "rest_api"
+)
+
+// Event log body formats
+const (
+	// LogBodyText is a formatted message with extra.* attributes
+	LogBodyText = "text"
+	// LogBodyStructured is a map body holding the event fields and parsed extra
+	LogBodyStructured = "structured"
+)
+
+// Where event log sources begin reading on first startup
+const (
+	// LogStartEarliest reads the whole log table
+	LogStartEarliest = "earliest"
+	// LogStartLatest only reads events recorded after startup
+	LogStartLatest = "latest"
+)
+
+// LogStart is where an event log source begins reading on first startup
+type LogStart struct {
+	// Position is LogStartEarliest or LogStartLatest, ignored when Time is set
+	Position string
+	// Time starts at the first event recorded at or after it
+	Time time.Time
+}
+
+// LogsCheckpointer is implemented by logs sources that only move their read
+// position past a scrape's records once the pipeline has accepted them, so
+// records are delivered at least once
+type LogsCheckpointer interface {
+	// Commit marks the records of the last scrape as delivered
+	Commit()
+}
+
 type LogScraper struct {
 	cfg              *LogScraperConfig
 	settings         receiver.Settings
 	db               *sql.DB
 	lb               *LogsBuilder
+	drops            *DropTracker
 	lastScrapedLogID int64
+	// pendingLogID is the last row of the previous scrape, committed once
+	// its records are consumed
+	pendingLogID int64
+	// pendingFiltered are the rows of the previous scrape the event filter
+	// excluded, counted as drops once committed
+	pendingFiltered int
+	// positioned is set once the watermark reflects cfg.Start
+	positioned bool
 }
 
 type LogScraperConfig struct {
-	Host               string
-	Port               int
-	Database           string
-	Username           string
-	Password           string
-	SSLMode            string
+	PostgresConnConfig
 	CollectionInterval time.Duration
+	EventFilter        EventFilter
+	BodyFormat         string
+	// Redactor rewrites sensitive attribute values, such as owner
+	Redactor *Redactor
+	// Start is where reading begins on first startup
+	Start LogStart
 }
 
-func NewLogScraper(cfg *LogScraperConfig, settings receiver.Settings) *LogScraper {
+func NewLogScraper(cfg *LogScraperConfig, settings receiver.Settings, drops *DropTracker) *LogScraper {
 	return &LogScraper{
 		cfg:              cfg,
 		settings:         settings,
-		lb:               NewLogsBuilder(),
+		lb:               NewLogsBuilder(settings),
+		drops:            drops,
 		lastScrapedLogID: 0,
 	}
 }
 
 func (s *LogScraper) Start(ctx context.Context, host component.Host) error {
-	connStr := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		s.cfg.Host,
-		s.cfg.Port,
-		s.cfg.Username,
-		s.cfg.Password,
-		s.cfg.Database,
-		s.cfg.SSLMode,
-	)
-
-	db, err := sql.Open("postgres", connStr)
+	db, err := s.cfg.OpenDB(false)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	if !s.positioned {
+		if err := s.position(ctx, db); err != nil {
+			db.Close()
+			return fmt.Errorf("failed to find the start of the log table: %w", err)
+		}
+		s.positioned = true
+	}
+
 	s.db = db
 	s.settings.Logger.Info("Log scraper database connection established",
 		zap.String("host", s.cfg.Host),
 		zap.Int("port", s.cfg.Port),
-		zap.String("database", s.cfg.Database))
+		zap.String("database", s.cfg.Database),
+		zap.Int64("start_after_log_id", s.lastScrapedLogID))
+
+	return nil
+}
 
+// position moves the watermark to the configured start, just before the
+// first row to emit
+func (s *LogScraper) position(ctx context.Context, db *sql.DB) error {
+	var id int64
+	switch {
+	case !s.cfg.Start.Time.IsZero():
+		// Without newer rows, start after the newest one
+		query := `
+			SELECT COALESCE(
+				(SELECT MIN(id) - 1 FROM log WHERE dttm >= $1),
+				(SELECT MAX(id) FROM log),
+				0)
+		`
+		if err := db.QueryRowContext(ctx, query, s.cfg.Start.Time).Scan(&id); err != nil {
+			return err
+		}
+	case s.cfg.Start.Position == LogStartLatest:
+		if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM log`).Scan(&id); err != nil {
+			return err
+		}
+	}
+	s.lastScrapedLogID = id
+	s.pendingLogID = id
 	return nil
 }
 
 func (s *LogScraper) Scrape(ctx context.Context) (plog.Logs, error) {
 	// Create fresh builder for each scrape
-	s.lb = NewLogsBuilder()
+	s.lb = NewLogsBuilder(s.settings)
+	s.lb.SetRedactor(s.cfg.Redactor)
 	
 	query := `
 		SELECT id, dttm, dag_id, task_id, event, execution_date, owner, extra
@@ -85,13 +162,18 @@ func (s *LogScraper) Scrape(ctx context.Context) (plog.Logs, error) {
 		LIMIT 1000
 	`
 
+	// Rows are read again until a scrape's records are committed
+	s.pendingLogID, s.pendingFiltered = s.lastScrapedLogID, 0
 	rows, err := s.db.QueryContext(ctx, query, s.lastScrapedLogID)
 	if err != nil {
 		return s.lb.Emit(), fmt.Errorf("failed to query logs: %w", err)
 	}
 	defer rows.Close()
+	
+	pending := s.lastScrapedLogID
 
 	logCount := 0
+	filtered := 0
 	for rows.Next() {
 		var (
 			id            int64
@@ -106,52 +188,98 @@ func (s *LogScraper) Scrape(ctx context.Context) (plog.Logs, error) {
 
 		if err := rows.Scan(&id, &dttm, &dagID, &taskID, &event, &executionDate, &owner, &extra); err != nil {
 			s.settings.Logger.Warn("Failed to scan log row", zap.Error(err))
+			s.drops.Record(SignalLogs, DropReasonScanError, 1, zap.Error(err))
 			continue
 		}
 
-		// Parse extra JSON if present
-		extraMap := make(map[string]string)
-		if extra.Valid && extra.String != "" {
-			var extraData map[string]interface{}
-			if err := json.Unmarshal([]byte(extra.String), &extraData); err == nil {
-				for key, value := range extraData {
-					if strVal, ok := value.(string); ok {
-						extraMap[key] = strVal
-					}
-				}
-			}
+		// Filtered rows still advance the watermark so they are not re-read
+		if id > pending {
+			pending = id
+		}
+		if !s.cfg.EventFilter.Allows(event.String) {
+			filtered++
+			continue
+		}
+
+		if s.cfg.BodyFormat == LogBodyStructured {
+			s.lb.RecordStructuredEventLog(
+				id,
+				dttm,
+				dagID.String,
+				taskID.String,
+				event.String,
+				owner.String,
+				executionDate.Time,
+				parseExtra(extra),
+			)
+			logCount++
+			continue
 		}
 
 		// Record the log event
 		s.lb.RecordEventLog(
+			id,
 			dttm,
 			dagID.String,
 			taskID.String,
 			event.String,
 			owner.String,
 			executionDate.Time,
-			extraMap,
+			parseExtraStrings(extra),
 		)
 
-		// Track highest log ID
-		if id > s.lastScrapedLogID {
-			s.lastScrapedLogID = id
-		}
-
 		logCount++
 	}
 
 	if err := rows.Err(); err != nil {
 		return s.lb.Emit(), fmt.Errorf("error iterating log rows: %w", err)
 	}
+	s.pendingLogID = pending
+	s.pendingFiltered = filtered
 
 	s.settings.Logger.Debug("Scraped event logs",
 		zap.Int("count", logCount),
-		zap.Int64("last_log_id", s.lastScrapedLogID))
+		zap.Int("filtered", filtered),
+		zap.Int64("last_log_id", pending))
 
 	return s.lb.Emit(), nil
 }
 
+// Commit advances the watermark past the rows of the last scrape
+func (s *LogScraper) Commit() {
+	s.lastScrapedLogID = s.pendingLogID
+	s.drops.Record(SignalLogs, DropReasonFiltered, int64(s.pendingFiltered), zap.String("source", "event_logs"))
+	s.pendingFiltered = 0
+}
+
+// parseExtraStrings decodes the extra column into its top-level string fields
+func parseExtraStrings(extra sql.NullString) map[string]string {
+	extraMap := make(map[string]string)
+	if extra.Valid && extra.String != "" {
+		var extraData map[string]interface{}
+		if err := json.Unmarshal([]byte(extra.String), &extraData); err == nil {
+			for key, value := range extraData {
+				if strVal, ok := value.(string); ok {
+					extraMap[key] = strVal
+				}
+			}
+		}
+	}
+	return extraMap
+}
+
+// parseExtra decodes the extra column, keeping non-JSON values as a string
+func parseExtra(extra sql.NullString) any {
+	if !extra.Valid || extra.String == "" {
+		return nil
+	}
+	var parsed any
+	if err := json.Unmarshal([]byte(extra.String), &parsed); err != nil {
+		return extra.String
+	}
+	return parsed
+}
+
 func (s *LogScraper) Shutdown(ctx context.Context) error {
 	if s.db != nil {
 		return s.db.Close()
@@ -9,15 +9,20 @@ import (
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver"
 )
 
 type LogsBuilder struct {
 	logs plog.Logs
 	rl   plog.ResourceLogs
 	sl   plog.ScopeLogs
+	// source is the airflow.log.source attribute of event logs
+	source string
+	// redactor rewrites sensitive attribute values on Emit
+	redactor *Redactor
 }
 
-func NewLogsBuilder() *LogsBuilder {
+func NewLogsBuilder(settings receiver.Settings) *LogsBuilder {
 	logs := plog.NewLogs()
 	rl := logs.ResourceLogs().AppendEmpty()
 	
@@ -25,22 +30,80 @@ func NewLogsBuilder() *LogsBuilder {
 	rl.Resource().Attributes().PutStr("airflow.component", "event_logs")
 	
 	sl := rl.ScopeLogs().AppendEmpty()
-	sl.Scope().SetName("github.com/npcomplete777/airflowreceiver/log_scraper")
-	sl.Scope().SetVersion("0.0.1")
+	sl.Scope().SetName(ScopeName)
+	sl.Scope().SetVersion(settings.BuildInfo.Version)
 	
 	return &LogsBuilder{
-		logs: logs,
-		rl:   rl,
-		sl:   sl,
+		logs:   logs,
+		rl:     rl,
+		sl:     sl,
+		source: "database",
 	}
 }
 
+// NewRESTEventLogsBuilder creates a builder for event logs read from the
+// REST API instead of the log table
+func NewRESTEventLogsBuilder(settings receiver.Settings) *LogsBuilder {
+	lb := NewLogsBuilder(settings)
+	lb.source = "rest_api"
+	return lb
+}
+
 func (lb *LogsBuilder) RecordEventLog(
+	id int64,
 	timestamp time.Time,
 	dagID, taskID, event, owner string,
 	executionDate time.Time,
 	extra map[string]string,
 ) {
+	lr := lb.appendEventLog(id, timestamp, dagID, taskID, event, owner, executionDate)
+	
+	// Body contains the event description
+	if event != "" {
+		lr.Body().SetStr(fmt.Sprintf("Airflow event: %s", event))
+	}
+	
+	// Add extra fields
+	attrs := lr.Attributes()
+	for key, value := range extra {
+		attrs.PutStr(fmt.Sprintf("extra.%s", key), value)
+	}
+}
+
+// RecordStructuredEventLog records an event log whose body is a map holding
+// the event fields and the parsed extra JSON, so backends need no parsing
+func (lb *LogsBuilder) RecordStructuredEventLog(
+	id int64,
+	timestamp time.Time,
+	dagID, taskID, event, owner string,
+	executionDate time.Time,
+	extra any,
+) {
+	lr := lb.appendEventLog(id, timestamp, dagID, taskID, event, owner, executionDate)
+	
+	body := lr.Body().SetEmptyMap()
+	body.PutStr("event", event)
+	body.PutStr("dag_id", dagID)
+	body.PutStr("task_id", taskID)
+	body.PutStr("owner", owner)
+	if !executionDate.IsZero() {
+		body.PutStr("execution_date", executionDate.Format(time.RFC3339))
+	}
+	if extra != nil {
+		// FromRaw only fails on types JSON decoding never produces
+		_ = body.PutEmpty("extra").FromRaw(extra)
+	}
+}
+
+// appendEventLog adds a record with the timestamp, severity and attributes
+// shared by both body formats. id is the log table row, which stays the same
+// when a record is emitted again and so serves as a deduplication key.
+func (lb *LogsBuilder) appendEventLog(
+	id int64,
+	timestamp time.Time,
+	dagID, taskID, event, owner string,
+	executionDate time.Time,
+) plog.LogRecord {
 	lr := lb.sl.LogRecords().AppendEmpty()
 	
 	lr.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
@@ -51,14 +114,10 @@ func (lb *LogsBuilder) RecordEventLog(
 	lr.SetSeverityNumber(severity)
 	lr.SetSeverityText(getSeverityText(severity))
 	
-	// Body contains the event description
-	if event != "" {
-		lr.Body().SetStr(fmt.Sprintf("Airflow event: %s", event))
-	}
-	
 	// Add structured attributes
 	attrs := lr.Attributes()
-	attrs.PutStr("airflow.log.source", "database")
+	attrs.PutStr("airflow.log.source", lb.source)
+	attrs.PutInt("airflow.log.id", id)
 	
 	if dagID != "" {
 		attrs.PutStr("dag.id", dagID)
@@ -76,9 +135,127 @@ func (lb *LogsBuilder) RecordEventLog(
 		attrs.PutStr("owner", owner)
 	}
 	
-	// Add extra fields
-	for key, value := range extra {
-		attrs.PutStr(fmt.Sprintf("extra.%s", key), value)
+	return lr
+}
+
+// NewInventoryLogsBuilder creates a builder for DAG inventory change events
+func NewInventoryLogsBuilder(settings receiver.Settings) *LogsBuilder {
+	lb := NewLogsBuilder(settings)
+	lb.rl.Resource().Attributes().PutStr("airflow.component", "inventory")
+	return lb
+}
+
+func (lb *LogsBuilder) RecordInventoryEvent(
+	timestamp time.Time,
+	eventName string,
+	dag DAG,
+	schedule, previousSchedule string,
+	initial bool,
+) {
+	lr := lb.sl.LogRecords().AppendEmpty()
+	
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	lr.SetEventName(eventName)
+	lr.SetSeverityNumber(plog.SeverityNumberInfo)
+	lr.SetSeverityText("INFO")
+	lr.Body().SetStr(fmt.Sprintf("%s: %s", eventName, dag.DAGID))
+	
+	attrs := lr.Attributes()
+	attrs.PutStr("airflow.log.source", "rest_api")
+	attrs.PutStr("dag.id", dag.DAGID)
+	attrs.PutBool("dag.is_paused", dag.IsPaused)
+	attrs.PutBool("dag.is_active", dag.IsActive)
+	if schedule != "" {
+		attrs.PutStr("dag.schedule", schedule)
+	}
+	if previousSchedule != "" {
+		attrs.PutStr("dag.schedule.previous", previousSchedule)
+	}
+	if dag.Fileloc != "" {
+		attrs.PutStr("dag.fileloc", dag.Fileloc)
+	}
+	if len(dag.Owners) > 0 {
+		owners := attrs.PutEmptySlice("dag.owners")
+		for _, owner := range dag.Owners {
+			owners.AppendEmpty().SetStr(owner)
+		}
+	}
+	if len(dag.Tags) > 0 {
+		tags := attrs.PutEmptySlice("dag.tags")
+		for _, tag := range dag.Tags {
+			tags.AppendEmpty().SetStr(tag.Name)
+		}
+	}
+	if initial {
+		attrs.PutBool("airflow.inventory.initial", true)
+	}
+}
+
+// NewDAGWarningLogsBuilder creates a builder for DAG warnings
+func NewDAGWarningLogsBuilder(settings receiver.Settings) *LogsBuilder {
+	lb := NewLogsBuilder(settings)
+	lb.rl.Resource().Attributes().PutStr("airflow.component", "dag_warnings")
+	return lb
+}
+
+func (lb *LogsBuilder) RecordDAGWarning(warning DAGWarning) {
+	lr := lb.sl.LogRecords().AppendEmpty()
+	
+	timestamp := warning.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	lr.SetEventName("airflow.dag.warning")
+	lr.SetSeverityNumber(plog.SeverityNumberWarn)
+	lr.SetSeverityText("WARN")
+	lr.Body().SetStr(warning.Message)
+	
+	attrs := lr.Attributes()
+	attrs.PutStr("airflow.log.source", "rest_api")
+	attrs.PutStr("dag.id", warning.DAGID)
+	attrs.PutStr("warning.type", warning.WarningType)
+}
+
+// NewFailedTaskLogsBuilder creates a builder for failed task log tails
+func NewFailedTaskLogsBuilder(settings receiver.Settings) *LogsBuilder {
+	lb := NewLogsBuilder(settings)
+	lb.rl.Resource().Attributes().PutStr("airflow.component", "task_logs")
+	return lb
+}
+
+func (lb *LogsBuilder) RecordFailedTaskLog(ti TaskInstance, content string, truncated bool) {
+	lr := lb.sl.LogRecords().AppendEmpty()
+	
+	timestamp := ti.EndDate
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	lr.SetSeverityNumber(plog.SeverityNumberError)
+	lr.SetSeverityText("ERROR")
+	lr.Body().SetStr(content)
+	
+	attrs := lr.Attributes()
+	attrs.PutStr("airflow.log.source", "rest_api")
+	attrs.PutStr("dag.id", ti.DAGID)
+	attrs.PutStr("task.id", ti.TaskID)
+	attrs.PutStr("dag_run.id", ti.DAGRunID)
+	attrs.PutInt("try_number", int64(ti.TryNumber))
+	if ti.MapIndex >= 0 {
+		attrs.PutInt("map_index", int64(ti.MapIndex))
+	}
+	if ti.Operator != "" {
+		attrs.PutStr("operator", ti.Operator)
+	}
+	if ti.Hostname != "" {
+		attrs.PutStr("host.name", ti.Hostname)
+	}
+	if truncated {
+		attrs.PutBool("airflow.log.truncated", true)
 	}
 }
 
@@ -115,5 +292,74 @@ func getSeverityText(severity plog.SeverityNumber) string {
 }
 
 func (lb *LogsBuilder) Emit() plog.Logs {
+	lb.redactor.redactLogs(lb.logs)
 	return lb.logs
 }
+
+// SetRedactor redacts attribute values of the records on Emit
+func (lb *LogsBuilder) SetRedactor(r *Redactor) {
+	lb.redactor = r
+}
+
+// NewStateChangeLogsBuilder creates a builder for task instance and DAG run
+// state changes pushed by the metadata database
+func NewStateChangeLogsBuilder(settings receiver.Settings) *LogsBuilder {
+	lb := NewLogsBuilder(settings)
+	lb.rl.Resource().Attributes().PutStr("airflow.component", "state_changes")
+	return lb
+}
+
+func (lb *LogsBuilder) RecordStateChange(change stateChange) {
+	lr := lb.sl.LogRecords().AppendEmpty()
+	
+	timestamp := change.ChangedAt
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	lr.SetEventName("airflow." + change.Table + ".state_change")
+	switch change.State {
+	case "failed":
+		lr.SetSeverityNumber(plog.SeverityNumberError)
+		lr.SetSeverityText("ERROR")
+	case "up_for_retry", "upstream_failed":
+		lr.SetSeverityNumber(plog.SeverityNumberWarn)
+		lr.SetSeverityText("WARN")
+	default:
+		lr.SetSeverityNumber(plog.SeverityNumberInfo)
+		lr.SetSeverityText("INFO")
+	}
+	
+	subject := change.DAGID
+	if change.TaskID != "" {
+		subject += "." + change.TaskID
+	}
+	state := change.State
+	if state == "" {
+		state = "none"
+	}
+	if change.PreviousState != "" {
+		lr.Body().SetStr(fmt.Sprintf("%s %s: %s -> %s", change.Table, subject, change.PreviousState, state))
+	} else {
+		lr.Body().SetStr(fmt.Sprintf("%s %s: %s", change.Table, subject, state))
+	}
+	
+	attrs := lr.Attributes()
+	attrs.PutStr("airflow.log.source", "database")
+	attrs.PutStr("dag.id", change.DAGID)
+	attrs.PutStr("dag_run.id", change.RunID)
+	if change.TaskID != "" {
+		attrs.PutStr("task.id", change.TaskID)
+	}
+	if change.MapIndex != nil && *change.MapIndex >= 0 {
+		attrs.PutInt("map_index", int64(*change.MapIndex))
+	}
+	if change.TryNumber != nil {
+		attrs.PutInt("try_number", int64(*change.TryNumber))
+	}
+	attrs.PutStr("state", state)
+	if change.PreviousState != "" {
+		attrs.PutStr("state.previous", change.PreviousState)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"math"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// mappedTask collects the expanded instances of one mapped task in one run.
+// Dynamic task mapping can fan out to thousands of instances, so they are
+// summarized instead of reported one data point per map index.
+type mappedTask struct {
+	dagID     string
+	taskID    string
+	runID     string
+	operator  string
+	instances []TaskInstance
+}
+
+// groupMappedTasks splits a run's task instances into unmapped ones and
+// mapped ones grouped by task
+func groupMappedTasks(tasks []TaskInstance) ([]TaskInstance, []*mappedTask) {
+	var unmapped []TaskInstance
+	var mapped []*mappedTask
+	byTask := make(map[string]*mappedTask)
+	for _, task := range tasks {
+		if task.MapIndex < 0 {
+			unmapped = append(unmapped, task)
+			continue
+		}
+		group, ok := byTask[task.TaskID]
+		if !ok {
+			group = &mappedTask{
+				dagID:    task.DAGID,
+				taskID:   task.TaskID,
+				runID:    task.DAGRunID,
+				operator: task.Operator,
+			}
+			byTask[task.TaskID] = group
+			mapped = append(mapped, group)
+		}
+		group.instances = append(group.instances, task)
+	}
+	return unmapped, mapped
+}
+
+// record emits instance counts by state and min/max/avg duration of the
+// finished instances
+func (m *mappedTask) record(mb *MetricsBuilder, ts pcommon.Timestamp) {
+	byState := make(map[string]int64)
+	durations := make(map[string][]float64)
+	for _, task := range m.instances {
+		byState[task.State]++
+		if task.Duration > 0 {
+			durations[task.State] = append(durations[task.State], task.Duration)
+		}
+	}
+
+	for state, count := range byState {
+		mb.RecordMappedTaskInstances(count, m.dagID, m.taskID, m.runID, state, ts)
+	}
+	for state, values := range durations {
+		minimum, maximum, sum := math.Inf(1), math.Inf(-1), 0.0
+		for _, v := range values {
+			minimum = math.Min(minimum, v)
+			maximum = math.Max(maximum, v)
+			sum += v
+		}
+		mb.RecordMappedTaskDuration(minimum, maximum, sum/float64(len(values)),
+			m.dagID, m.taskID, m.runID, state, m.operator, ts)
+	}
+}
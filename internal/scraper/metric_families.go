@@ -0,0 +1,78 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"sort"
+)
+
+// Sources that can own a metric family
+const (
+	MetricOwnerRESTAPI  = "rest_api"
+	MetricOwnerDatabase = "database"
+)
+
+// metricFamily lists the metrics each source emits for the same data. With
+// both the REST API and the database enabled, a family is reported twice
+// under different names unless one source owns it.
+type metricFamily struct {
+	restAPI  []string
+	database []string
+}
+
+var metricFamilies = map[string]metricFamily{
+	"dag_runs": {
+		restAPI:  []string{"airflow.dag_runs.by_state", "airflow.dag.run.duration", "airflow.dag.run.duration.histogram", "airflow.dag.run.last_state"},
+		database: []string{"airflow.dag.run.count.db", "airflow.dag.run.duration.avg", "airflow.dag.run.duration.percentile", "airflow.dag.run.duration.distribution"},
+	},
+	"task_instances": {
+		restAPI:  []string{"airflow.task_instances.by_state", "airflow.task.instance.duration", "airflow.task.instance.duration.histogram"},
+		database: []string{"airflow.task.instance.count.db", "airflow.task.instance.duration.avg", "airflow.task.instance.duration.max", "airflow.task.instance.duration.percentile", "airflow.task.instance.duration.distribution"},
+	},
+	"task_tries": {
+		restAPI:  []string{"airflow.task.instance.tries", "airflow.task.retries_per_success"},
+		database: []string{"airflow.task.instance.tries", "airflow.task.retries_per_success"},
+	},
+	"import_errors": {
+		restAPI:  []string{"airflow.import_errors.count"},
+		database: []string{"airflow.dag_processing.import_errors"},
+	},
+}
+
+// MetricFamilies returns the families emitted by both the REST API and the
+// database scrapers, sorted
+func MetricFamilies() []string {
+	names := make([]string, 0, len(metricFamilies))
+	for name := range metricFamilies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MetricFamilyMetrics returns the metrics a family is emitted as by the
+// REST API and by the database
+func MetricFamilyMetrics(family string) (restAPI, database []string) {
+	f := metricFamilies[family]
+	return f.restAPI, f.database
+}
+
+// ExcludedMetrics returns the metrics source must not emit because owners
+// assigns their family to the other source
+func ExcludedMetrics(source string, owners map[string]string) []string {
+	var excluded []string
+	for family, owner := range owners {
+		if owner == source {
+			continue
+		}
+		f := metricFamilies[family]
+		switch source {
+		case MetricOwnerRESTAPI:
+			excluded = append(excluded, f.restAPI...)
+		case MetricOwnerDatabase:
+			excluded = append(excluded, f.database...)
+		}
+	}
+	return excluded
+}
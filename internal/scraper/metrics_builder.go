@@ -4,19 +4,69 @@
 package scraper
 
 import (
+	"database/sql"
+	"math"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 	
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
 )
 
+// ScopeName is the instrumentation scope of everything the receiver builds,
+// named after the component's import path
+const ScopeName = "github.com/npcomplete777/airflowreceiver"
+
 type MetricsBuilder struct {
+	// buildInfo versions the instrumentation scope
+	buildInfo component.BuildInfo
+	
 	metrics pmetric.Metrics
 	rm      pmetric.ResourceMetrics
 	sm      pmetric.ScopeMetrics
+	
+	// byName holds one metric per name and type so data points share a
+	// definition
+	byName map[metricKey]pmetric.Metric
+	
+	// excluded metrics are removed on Emit
+	excluded map[string]bool
+	
+	// disabledAttributes are removed from every data point on Emit
+	disabledAttributes map[string]bool
+	
+	// drops accounts for points that collapsed without being merged
+	drops *DropTracker
+	
+	// declaredMerges are the gauge merges of metrics defined by
+	// configuration, such as custom queries and StatsD mappings
+	declaredMerges map[string]string
+	
+	// redactor rewrites sensitive attribute values on Emit
+	redactor *Redactor
+	
+	// resourcePerDAG moves points with a dag.id onto a resource per DAG on Emit
+	resourcePerDAG bool
+}
+
+type metricKey struct {
+	name       string
+	metricType pmetric.MetricType
 }
 
-func NewMetricsBuilder() *MetricsBuilder {
+func NewMetricsBuilder(settings receiver.Settings) *MetricsBuilder {
+	mb := &MetricsBuilder{buildInfo: settings.BuildInfo}
+	mb.reset()
+	return mb
+}
+
+// reset starts a new, empty batch
+func (mb *MetricsBuilder) reset() {
 	m := pmetric.NewMetrics()
 	rm := m.ResourceMetrics().AppendEmpty()
 	
@@ -24,24 +74,72 @@ func NewMetricsBuilder() *MetricsBuilder {
 	rm.Resource().Attributes().PutStr("airflow.component", "receiver")
 	
 	sm := rm.ScopeMetrics().AppendEmpty()
-	sm.Scope().SetName("github.com/npcomplete777/airflowreceiver")
-	sm.Scope().SetVersion("0.0.1")
+	sm.Scope().SetName(ScopeName)
+	sm.Scope().SetVersion(mb.buildInfo.Version)
 	
-	return &MetricsBuilder{
-		metrics: m,
-		rm:      rm,
-		sm:      sm,
+	mb.metrics = m
+	mb.rm = rm
+	mb.sm = sm
+	mb.byName = make(map[metricKey]pmetric.Metric)
+}
+
+// metric returns the metric with the given name and type, creating it on
+// first use. created reports whether the caller needs to set the data type.
+func (mb *MetricsBuilder) metric(name, unit, description string, metricType pmetric.MetricType) (metric pmetric.Metric, created bool) {
+	key := metricKey{name: name, metricType: metricType}
+	if metric, ok := mb.byName[key]; ok {
+		return metric, false
 	}
+	
+	metric = mb.sm.Metrics().AppendEmpty()
+	metric.SetName(name)
+	metric.SetUnit(unit)
+	metric.SetDescription(description)
+	mb.byName[key] = metric
+	return metric, true
+}
+
+// gaugeDataPoint appends a data point to the named gauge
+func (mb *MetricsBuilder) gaugeDataPoint(name, unit, description string) pmetric.NumberDataPoint {
+	metric, created := mb.metric(name, unit, description, pmetric.MetricTypeGauge)
+	if created {
+		metric.SetEmptyGauge()
+	}
+	return metric.Gauge().DataPoints().AppendEmpty()
+}
+
+// sumDataPoint appends a data point to the named cumulative sum
+func (mb *MetricsBuilder) sumDataPoint(name, unit, description string, monotonic bool) pmetric.NumberDataPoint {
+	metric, created := mb.metric(name, unit, description, pmetric.MetricTypeSum)
+	if created {
+		sum := metric.SetEmptySum()
+		sum.SetIsMonotonic(monotonic)
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	}
+	return metric.Sum().DataPoints().AppendEmpty()
+}
+
+// histogramDataPoint appends a data point to the named delta histogram
+func (mb *MetricsBuilder) histogramDataPoint(name, unit, description string) pmetric.HistogramDataPoint {
+	metric, created := mb.metric(name, unit, description, pmetric.MetricTypeHistogram)
+	if created {
+		metric.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	}
+	return metric.Histogram().DataPoints().AppendEmpty()
+}
+
+// expHistogramDataPoint appends a data point to the named delta exponential
+// histogram
+func (mb *MetricsBuilder) expHistogramDataPoint(name, unit, description string) pmetric.ExponentialHistogramDataPoint {
+	metric, created := mb.metric(name, unit, description, pmetric.MetricTypeExponentialHistogram)
+	if created {
+		metric.SetEmptyExponentialHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	}
+	return metric.ExponentialHistogram().DataPoints().AppendEmpty()
 }
 
 func (mb *MetricsBuilder) RecordDAGRunDuration(value float64, dagID, runID, runType, state string, ts pcommon.Timestamp) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.dag.run.duration")
-	metric.SetUnit("s")
-	metric.SetDescription("Duration of DAG run execution")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.dag.run.duration", "s", "Duration of DAG run execution")
 	dp.SetTimestamp(ts)
 	dp.SetDoubleValue(value)
 	
@@ -51,17 +149,9 @@ func (mb *MetricsBuilder) RecordDAGRunDuration(value float64, dagID, runID, runT
 	dp.Attributes().PutStr("state", state)
 }
 
-func (mb *MetricsBuilder) RecordDAGRunCount(value int64, dagID, state string, ts pcommon.Timestamp) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.dag.run.count")
-	metric.SetUnit("{runs}")
-	metric.SetDescription("Number of DAG runs by state")
-	
-	sum := metric.SetEmptySum()
-	sum.SetIsMonotonic(true)
-	sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
-	
-	dp := sum.DataPoints().AppendEmpty()
+func (mb *MetricsBuilder) RecordDAGRunCount(value int64, dagID, state string, start, ts pcommon.Timestamp) {
+	dp := mb.sumDataPoint("airflow.dag.run.count", "{runs}", "Number of DAG runs by state", true)
+	dp.SetStartTimestamp(start)
 	dp.SetTimestamp(ts)
 	dp.SetIntValue(value)
 	
@@ -70,13 +160,7 @@ func (mb *MetricsBuilder) RecordDAGRunCount(value int64, dagID, state string, ts
 }
 
 func (mb *MetricsBuilder) RecordTaskInstanceDuration(value float64, dagID, taskID, runID, state string, ts pcommon.Timestamp) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.task.instance.duration")
-	metric.SetUnit("s")
-	metric.SetDescription("Duration of task instance execution")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.task.instance.duration", "s", "Duration of task instance execution")
 	dp.SetTimestamp(ts)
 	dp.SetDoubleValue(value)
 	
@@ -87,13 +171,7 @@ func (mb *MetricsBuilder) RecordTaskInstanceDuration(value float64, dagID, taskI
 }
 
 func (mb *MetricsBuilder) RecordPoolSlotsOpen(value int64, poolName string, ts pcommon.Timestamp) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.pool.slots.open")
-	metric.SetUnit("{slots}")
-	metric.SetDescription("Number of open slots in pool")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.pool.slots.open", "{slots}", "Number of open slots in pool")
 	dp.SetTimestamp(ts)
 	dp.SetIntValue(value)
 	
@@ -101,13 +179,7 @@ func (mb *MetricsBuilder) RecordPoolSlotsOpen(value int64, poolName string, ts p
 }
 
 func (mb *MetricsBuilder) RecordPoolSlotsUsed(value int64, poolName string, ts pcommon.Timestamp) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.pool.slots.used")
-	metric.SetUnit("{slots}")
-	metric.SetDescription("Number of used slots in pool")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.pool.slots.used", "{slots}", "Number of used slots in pool")
 	dp.SetTimestamp(ts)
 	dp.SetIntValue(value)
 	
@@ -115,13 +187,7 @@ func (mb *MetricsBuilder) RecordPoolSlotsUsed(value int64, poolName string, ts p
 }
 
 func (mb *MetricsBuilder) RecordSchedulerHealth(status string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.scheduler.health")
-	metric.SetUnit("{status}")
-	metric.SetDescription("Scheduler health status (1=healthy, 0=unhealthy)")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.scheduler.health", "{status}", "Scheduler health status (1=healthy, 0=unhealthy)")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	
 	healthValue := int64(0)
@@ -133,13 +199,7 @@ func (mb *MetricsBuilder) RecordSchedulerHealth(status string, ts time.Time) {
 }
 
 func (mb *MetricsBuilder) RecordDatabaseHealth(status string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.database.health")
-	metric.SetUnit("{status}")
-	metric.SetDescription("Database health status (1=healthy, 0=unhealthy)")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.database.health", "{status}", "Database health status (1=healthy, 0=unhealthy)")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	
 	healthValue := int64(0)
@@ -151,75 +211,166 @@ func (mb *MetricsBuilder) RecordDatabaseHealth(status string, ts time.Time) {
 }
 
 func (mb *MetricsBuilder) RecordSchedulerHeartbeatAge(age float64, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.scheduler.heartbeat.age")
-	metric.SetUnit("s")
-	metric.SetDescription("Age of scheduler heartbeat in seconds")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.scheduler.heartbeat.age", "s", "Age of scheduler heartbeat in seconds")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetDoubleValue(age)
 }
 
 func (mb *MetricsBuilder) RecordConnectionCount(count int64, connType string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.connections.count")
-	metric.SetUnit("{connections}")
-	metric.SetDescription("Number of connections by type")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.connections.count", "{connections}", "Number of connections by type")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(count)
 	dp.Attributes().PutStr("connection.type", connType)
 }
 
 func (mb *MetricsBuilder) RecordVariableCount(count int64, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.variables.count")
-	metric.SetUnit("{variables}")
-	metric.SetDescription("Total number of Airflow variables")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.variables.count", "{variables}", "Total number of Airflow variables")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(count)
 }
 
 func (mb *MetricsBuilder) RecordImportErrorCount(count int64, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.import_errors.count")
-	metric.SetUnit("{errors}")
-	metric.SetDescription("Number of DAG import errors")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.import_errors.count", "{errors}", "Number of DAG import errors")
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetIntValue(count)
+}
+
+func (mb *MetricsBuilder) RecordDAGProcessingDagbagSize(count int64, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.dag_processing.dagbag.size", "{dags}", "Active DAGs known to the metadata database")
+	dp.SetTimestamp(ts)
+	dp.SetIntValue(count)
+}
+
+func (mb *MetricsBuilder) RecordDAGProcessingImportErrors(count int64, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.dag_processing.import_errors", "{errors}", "DAG files with import errors recorded in the metadata database")
+	dp.SetTimestamp(ts)
+	dp.SetIntValue(count)
+}
+
+func (mb *MetricsBuilder) RecordDAGFileParseAge(seconds float64, fileloc string, dags int64, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.dag_processing.file.parse.age", "s", "Time since a DAG file was last parsed")
+	dp.SetTimestamp(ts)
+	dp.SetDoubleValue(seconds)
+	dp.Attributes().PutStr("dag.file", fileloc)
+	dp.Attributes().PutInt("dag.count", dags)
+}
+
+func (mb *MetricsBuilder) RecordJobHeartbeatAge(seconds float64, jobID int64, jobType, hostname string, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.job.heartbeat.age", "s", "Time since a running scheduler, triggerer or DAG processor job last heartbeated")
+	dp.SetTimestamp(ts)
+	dp.SetDoubleValue(seconds)
+	dp.Attributes().PutInt("job.id", jobID)
+	dp.Attributes().PutStr("job.type", jobType)
+	dp.Attributes().PutStr("hostname", hostname)
+}
+
+func (mb *MetricsBuilder) RecordJobsAlive(count int64, jobType string, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.jobs.alive", "{jobs}", "Running jobs of a type whose heartbeat is within the health check threshold")
+	dp.SetTimestamp(ts)
+	dp.SetIntValue(count)
+	dp.Attributes().PutStr("job.type", jobType)
+}
+
+func (mb *MetricsBuilder) RecordDAGSerializationAge(seconds float64, dagID string, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.dag.serialization.age", "s", "Time since the DAG's serialized_dag row was last updated")
+	dp.SetTimestamp(ts)
+	dp.SetDoubleValue(seconds)
+	dp.Attributes().PutStr("dag.id", dagID)
+}
+
+func (mb *MetricsBuilder) RecordDatasetEvents(count int64, uri string, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.dataset.events", "{events}", "Dataset events in the last 24 hours")
+	dp.SetTimestamp(ts)
+	dp.SetIntValue(count)
+	dp.Attributes().PutStr("dataset.uri", uri)
+}
+
+func (mb *MetricsBuilder) RecordDatasetEventAge(seconds float64, uri string, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.dataset.event.age", "s", "Time since the dataset's last event")
+	dp.SetTimestamp(ts)
+	dp.SetDoubleValue(seconds)
+	dp.Attributes().PutStr("dataset.uri", uri)
+}
+
+func (mb *MetricsBuilder) RecordDatabaseTableRows(rows int64, schema, table string, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.database.table.rows", "{rows}", "Estimated live rows in a metadata database table")
+	dp.SetTimestamp(ts)
+	dp.SetIntValue(rows)
+	dp.Attributes().PutStr("db.schema", schema)
+	dp.Attributes().PutStr("db.table", table)
+}
+
+func (mb *MetricsBuilder) RecordDatabaseTableExpiredRows(rows int64, table string, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.database.table.rows.expired", "{rows}", "Rows in a metadata database table older than the retention period")
+	dp.SetTimestamp(ts)
+	dp.SetIntValue(rows)
+	dp.Attributes().PutStr("db.table", table)
+}
+
+func (mb *MetricsBuilder) RecordDBLogGrowthRate(rowsPerSecond float64, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.db.log.growth_rate", "{rows}/s", "Rows added to the log table per second since the previous scrape")
+	dp.SetTimestamp(ts)
+	dp.SetDoubleValue(rowsPerSecond)
+}
+
+func (mb *MetricsBuilder) RecordDatabaseTableSize(bytes int64, schema, table string, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.database.table.size", "By", "On-disk size of a metadata database table including indexes and TOAST")
+	dp.SetTimestamp(ts)
+	dp.SetIntValue(bytes)
+	dp.Attributes().PutStr("db.schema", schema)
+	dp.Attributes().PutStr("db.table", table)
+}
+
+func (mb *MetricsBuilder) RecordDatasetCount(count int64, ts time.Time) {
+	dp := mb.gaugeDataPoint("airflow.datasets.count", "{datasets}", "Number of datasets (Airflow 2.4+)")
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetIntValue(count)
+}
+
+func (mb *MetricsBuilder) RecordProviderCount(count int64, ts time.Time) {
+	dp := mb.gaugeDataPoint("airflow.providers.count", "{providers}", "Number of installed provider packages")
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetIntValue(count)
+}
+
+func (mb *MetricsBuilder) RecordProviderInfo(packageName, version string, ts time.Time) {
+	dp := mb.gaugeDataPoint("airflow.provider.info", "1", "Installed provider package, always 1")
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetIntValue(1)
+	dp.Attributes().PutStr("provider.name", packageName)
+	dp.Attributes().PutStr("provider.version", version)
+}
+
+func (mb *MetricsBuilder) RecordDAGWarningCount(count int64, warningType string, ts time.Time) {
+	dp := mb.gaugeDataPoint("airflow.dag.warnings", "{warnings}", "Number of DAG warnings by warning type")
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetIntValue(count)
+	dp.Attributes().PutStr("warning.type", warningType)
+}
+
+func (mb *MetricsBuilder) RecordPluginCount(count int64, ts time.Time) {
+	dp := mb.gaugeDataPoint("airflow.plugins.count", "{plugins}", "Number of loaded Airflow plugins")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(count)
 }
 
+func (mb *MetricsBuilder) RecordPluginInfo(name, source string, ts time.Time) {
+	dp := mb.gaugeDataPoint("airflow.plugin.info", "1", "Loaded Airflow plugin, always 1")
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetIntValue(1)
+	dp.Attributes().PutStr("plugin.name", name)
+	dp.Attributes().PutStr("plugin.source", source)
+}
+
 func (mb *MetricsBuilder) RecordDAGCount(count int64, status string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.dags.count")
-	metric.SetUnit("{dags}")
-	metric.SetDescription("Total number of DAGs by status")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.dags.count", "{dags}", "Total number of DAGs by status")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(count)
 	dp.Attributes().PutStr("status", status)
 }
 
 func (mb *MetricsBuilder) RecordTaskInstancesByState(count int64, dagID, state string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.task_instances.by_state")
-	metric.SetUnit("{tasks}")
-	metric.SetDescription("Number of task instances by state")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.task_instances.by_state", "{tasks}", "Number of task instances by state")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(count)
 	dp.Attributes().PutStr("dag.id", dagID)
@@ -227,13 +378,7 @@ func (mb *MetricsBuilder) RecordTaskInstancesByState(count int64, dagID, state s
 }
 
 func (mb *MetricsBuilder) RecordDAGRunsByState(count int64, dagID, state string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.dag_runs.by_state")
-	metric.SetUnit("{runs}")
-	metric.SetDescription("Number of DAG runs by state")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.dag_runs.by_state", "{runs}", "Number of DAG runs by state")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(count)
 	dp.Attributes().PutStr("dag.id", dagID)
@@ -241,26 +386,14 @@ func (mb *MetricsBuilder) RecordDAGRunsByState(count int64, dagID, state string,
 }
 
 func (mb *MetricsBuilder) RecordPoolQueuedSlots(value int64, poolName string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.pool.slots.queued")
-	metric.SetUnit("{slots}")
-	metric.SetDescription("Number of queued slots in pool")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.pool.slots.queued", "{slots}", "Number of queued slots in pool")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(value)
 	dp.Attributes().PutStr("pool.name", poolName)
 }
 
 func (mb *MetricsBuilder) RecordPoolRunningSlots(value int64, poolName string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.pool.slots.running")
-	metric.SetUnit("{slots}")
-	metric.SetDescription("Number of running slots in pool")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.pool.slots.running", "{slots}", "Number of running slots in pool")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(value)
 	dp.Attributes().PutStr("pool.name", poolName)
@@ -269,14 +402,8 @@ func (mb *MetricsBuilder) RecordPoolRunningSlots(value int64, poolName string, t
 
 // Additional dimensional metrics
 
-func (mb *MetricsBuilder) RecordTaskInstanceDurationWithDimensions(value float64, dagID, taskID, dagRunID, state, operator, pool, queue string, tryNumber int, ts pcommon.Timestamp) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.task.instance.duration")
-	metric.SetUnit("s")
-	metric.SetDescription("Duration of task instance execution with full dimensions")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+func (mb *MetricsBuilder) RecordTaskInstanceDurationWithDimensions(value float64, dagID, taskID, dagRunID, state, operator, pool, queue, hostname string, tryNumber, mapIndex int, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.task.instance.duration", "s", "Duration of task instance execution with full dimensions")
 	dp.SetTimestamp(ts)
 	dp.SetDoubleValue(value)
 	
@@ -288,16 +415,46 @@ func (mb *MetricsBuilder) RecordTaskInstanceDurationWithDimensions(value float64
 	dp.Attributes().PutStr("pool", pool)
 	dp.Attributes().PutStr("queue", queue)
 	dp.Attributes().PutInt("try_number", int64(tryNumber))
+	if mapIndex >= 0 {
+		dp.Attributes().PutInt("map_index", int64(mapIndex))
+	}
+	if hostname != "" {
+		dp.Attributes().PutStr("hostname", hostname)
+	}
+}
+
+func (mb *MetricsBuilder) RecordMappedTaskInstances(count int64, dagID, taskID, dagRunID, state string, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.task.mapped.instances", "{task_instances}", "Expanded instances of a mapped task by state")
+	dp.SetTimestamp(ts)
+	dp.SetIntValue(count)
+	dp.Attributes().PutStr("dag.id", dagID)
+	dp.Attributes().PutStr("task.id", taskID)
+	dp.Attributes().PutStr("dag_run.id", dagRunID)
+	dp.Attributes().PutStr("state", state)
+}
+
+func (mb *MetricsBuilder) RecordMappedTaskDuration(minimum, maximum, avg float64, dagID, taskID, dagRunID, state, operator string, ts pcommon.Timestamp) {
+	for _, stat := range []struct {
+		name, description string
+		value             float64
+	}{
+		{"airflow.task.mapped.duration.min", "Shortest duration among the expanded instances of a mapped task", minimum},
+		{"airflow.task.mapped.duration.max", "Longest duration among the expanded instances of a mapped task", maximum},
+		{"airflow.task.mapped.duration.avg", "Average duration of the expanded instances of a mapped task", avg},
+	} {
+		dp := mb.gaugeDataPoint(stat.name, "s", stat.description)
+		dp.SetTimestamp(ts)
+		dp.SetDoubleValue(stat.value)
+		dp.Attributes().PutStr("dag.id", dagID)
+		dp.Attributes().PutStr("task.id", taskID)
+		dp.Attributes().PutStr("dag_run.id", dagRunID)
+		dp.Attributes().PutStr("state", state)
+		dp.Attributes().PutStr("operator", operator)
+	}
 }
 
 func (mb *MetricsBuilder) RecordDAGRunDurationWithDimensions(value float64, dagID, dagRunID, runType, state string, externalTrigger bool, ts pcommon.Timestamp) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.dag.run.duration")
-	metric.SetUnit("s")
-	metric.SetDescription("Duration of DAG run execution with full dimensions")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.dag.run.duration", "s", "Duration of DAG run execution with full dimensions")
 	dp.SetTimestamp(ts)
 	dp.SetDoubleValue(value)
 	
@@ -308,14 +465,133 @@ func (mb *MetricsBuilder) RecordDAGRunDurationWithDimensions(value float64, dagI
 	dp.Attributes().PutBool("external_trigger", externalTrigger)
 }
 
+func (mb *MetricsBuilder) RecordDAGActiveRunsUtilization(ratio float64, dagID string, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.dag.active_runs.utilization", "1", "Running DAG runs as a fraction of the DAG's max_active_runs")
+	dp.SetTimestamp(ts)
+	dp.SetDoubleValue(ratio)
+	dp.Attributes().PutStr("dag.id", dagID)
+}
+
+func (mb *MetricsBuilder) RecordDAGActiveTasksUtilization(ratio float64, dagID string, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.dag.active_tasks.utilization", "1", "Running task instances as a fraction of the DAG's max_active_tasks")
+	dp.SetTimestamp(ts)
+	dp.SetDoubleValue(ratio)
+	dp.Attributes().PutStr("dag.id", dagID)
+}
+
+func (mb *MetricsBuilder) RecordOperatorDurationHistogram(operator, state string, h *durationHistogram, start, ts pcommon.Timestamp) {
+	dp := mb.histogramDataPoint("airflow.operator.duration", "s", "Durations of task instances that finished since the previous scrape, by operator")
+	setDurationHistogram(dp, h, start, ts)
+	dp.Attributes().PutStr("operator", operator)
+	dp.Attributes().PutStr("state", state)
+}
+
+func setDurationHistogram(dp pmetric.HistogramDataPoint, h *durationHistogram, start, ts pcommon.Timestamp) {
+	dp.SetStartTimestamp(start)
+	dp.SetTimestamp(ts)
+	dp.SetCount(h.count)
+	dp.SetSum(h.sum)
+	dp.SetMin(h.min)
+	dp.SetMax(h.max)
+	dp.ExplicitBounds().FromRaw(operatorDurationBounds)
+	dp.BucketCounts().FromRaw(h.counts)
+}
+
+func (mb *MetricsBuilder) RecordDAGRunDurationHistogram(dagID, state string, h *durationHistogram, start, ts pcommon.Timestamp) {
+	dp := mb.histogramDataPoint("airflow.dag.run.duration.histogram", "s", "Durations of DAG runs that finished since the previous scrape")
+	setDurationHistogram(dp, h, start, ts)
+	dp.Attributes().PutStr("dag.id", dagID)
+	dp.Attributes().PutStr("state", state)
+}
+
+func (mb *MetricsBuilder) RecordDAGTaskDurationHistogram(dagID, state string, h *durationHistogram, start, ts pcommon.Timestamp) {
+	dp := mb.histogramDataPoint("airflow.task.instance.duration.histogram", "s", "Durations of task instances that finished since the previous scrape, by DAG")
+	setDurationHistogram(dp, h, start, ts)
+	dp.Attributes().PutStr("dag.id", dagID)
+	dp.Attributes().PutStr("state", state)
+}
+
+func (mb *MetricsBuilder) RecordTaskInstanceDurationDistribution(h *expHistogram, dagID, taskID, state string, start, ts time.Time) {
+	dp := mb.expHistogramDataPoint("airflow.task.instance.duration.distribution", "s", "Durations of task instances that finished since the previous scrape, from the database")
+	dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	h.copyTo(dp)
+	dp.Attributes().PutStr("dag.id", dagID)
+	dp.Attributes().PutStr("task.id", taskID)
+	dp.Attributes().PutStr("state", state)
+}
+
+func (mb *MetricsBuilder) RecordDAGRunDurationDistribution(h *expHistogram, dagID, state string, start, ts time.Time) {
+	dp := mb.expHistogramDataPoint("airflow.dag.run.duration.distribution", "s", "Durations of DAG runs that finished since the previous scrape, from the database")
+	dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	h.copyTo(dp)
+	dp.Attributes().PutStr("dag.id", dagID)
+	dp.Attributes().PutStr("state", state)
+}
+
+func (mb *MetricsBuilder) RecordDAGLastRunState(dagID, state, runType string, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.dag.run.last_state", "1", "Always 1, with the state of the DAG's most recent run")
+	dp.SetTimestamp(ts)
+	dp.SetIntValue(1)
+	dp.Attributes().PutStr("dag.id", dagID)
+	dp.Attributes().PutStr("state", state)
+	dp.Attributes().PutStr("run.type", runType)
+}
+
+func (mb *MetricsBuilder) RecordWorkerTaskInstances(count int64, hostname, state string, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.worker.task_instances", "{task_instances}", "Task instances of the fetched DAG runs by the worker that ran them")
+	dp.SetTimestamp(ts)
+	dp.SetIntValue(count)
+	dp.Attributes().PutStr("hostname", hostname)
+	dp.Attributes().PutStr("state", state)
+}
+
+func (mb *MetricsBuilder) RecordWorkerTaskDurationAvg(avg float64, hostname string, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.worker.task.duration.avg", "s", "Average duration of the finished task instances each worker ran, over the fetched DAG runs")
+	dp.SetTimestamp(ts)
+	dp.SetDoubleValue(avg)
+	dp.Attributes().PutStr("hostname", hostname)
+}
+
+func (mb *MetricsBuilder) RecordTaskInstanceTries(count int64, dagID, taskID string, try int, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.task.instance.tries", "{task_instances}", "Finished task instances by the attempt they finished on")
+	dp.SetTimestamp(ts)
+	dp.SetIntValue(count)
+	dp.Attributes().PutStr("dag.id", dagID)
+	dp.Attributes().PutStr("task.id", taskID)
+	dp.Attributes().PutInt("try_number", int64(try))
+}
+
+func (mb *MetricsBuilder) RecordTaskRetriesPerSuccess(ratio float64, dagID, taskID string, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.task.retries_per_success", "1", "Retries of finished task instances divided by successful task instances")
+	dp.SetTimestamp(ts)
+	dp.SetDoubleValue(ratio)
+	dp.Attributes().PutStr("dag.id", dagID)
+	dp.Attributes().PutStr("task.id", taskID)
+}
+
+func (mb *MetricsBuilder) RecordPoolStarved(starved bool, poolName string, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.pool.starved", "1", "Whether the pool has no open slots while tasks are queued for it (1) or not (0)")
+	dp.SetTimestamp(ts)
+	if starved {
+		dp.SetIntValue(1)
+	} else {
+		dp.SetIntValue(0)
+	}
+	dp.Attributes().PutStr("pool.name", poolName)
+}
+
+func (mb *MetricsBuilder) RecordPoolStarvedTime(seconds float64, poolName string, start, ts pcommon.Timestamp) {
+	dp := mb.sumDataPoint("airflow.pool.starved.time", "s", "Total time the pool has been starved since the receiver started", true)
+	dp.SetStartTimestamp(start)
+	dp.SetTimestamp(ts)
+	dp.SetDoubleValue(seconds)
+	dp.Attributes().PutStr("pool.name", poolName)
+}
+
 func (mb *MetricsBuilder) RecordPoolTotalSlots(value int64, poolName, description string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.pool.slots.total")
-	metric.SetUnit("{slots}")
-	metric.SetDescription("Total capacity of pool")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.pool.slots.total", "{slots}", "Total capacity of pool")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(value)
 	dp.Attributes().PutStr("pool.name", poolName)
@@ -325,39 +601,21 @@ func (mb *MetricsBuilder) RecordPoolTotalSlots(value int64, poolName, descriptio
 }
 
 func (mb *MetricsBuilder) RecordPoolDeferredSlots(value int64, poolName string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.pool.slots.deferred")
-	metric.SetUnit("{slots}")
-	metric.SetDescription("Number of deferred slots in pool")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.pool.slots.deferred", "{slots}", "Number of deferred slots in pool")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(value)
 	dp.Attributes().PutStr("pool.name", poolName)
 }
 
 func (mb *MetricsBuilder) RecordPoolScheduledSlots(value int64, poolName string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.pool.slots.scheduled")
-	metric.SetUnit("{slots}")
-	metric.SetDescription("Number of scheduled slots in pool")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.pool.slots.scheduled", "{slots}", "Number of scheduled slots in pool")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(value)
 	dp.Attributes().PutStr("pool.name", poolName)
 }
 
 func (mb *MetricsBuilder) RecordDAGWithTags(count int64, dagID string, tags []string, isPaused bool, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.dag.info")
-	metric.SetUnit("{dag}")
-	metric.SetDescription("DAG information with tags")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.dag.info", "{dag}", "DAG information with tags")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(count)
 	dp.Attributes().PutStr("dag.id", dagID)
@@ -376,16 +634,28 @@ func (mb *MetricsBuilder) RecordDAGWithTags(count int64, dagID string, tags []st
 	}
 }
 
+func (mb *MetricsBuilder) RecordTaskInfo(info TaskInfo, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.task.info", "{task}", "Task definition with operator and dependency metadata")
+	dp.SetTimestamp(ts)
+	dp.SetIntValue(1)
+	attrs := dp.Attributes()
+	attrs.PutStr("dag.id", info.DAGID)
+	attrs.PutStr("task.id", info.TaskID)
+	attrs.PutStr("operator", info.Operator)
+	attrs.PutStr("operator.module", info.OperatorModule)
+	attrs.PutStr("owner", info.Owner)
+	attrs.PutInt("retries", info.Retries)
+	attrs.PutStr("trigger_rule", info.TriggerRule)
+	attrs.PutStr("pool", info.Pool)
+	attrs.PutStr("queue", info.Queue)
+	attrs.PutStr("upstream_task_ids", strings.Join(info.UpstreamTaskIDs, ","))
+	attrs.PutStr("downstream_task_ids", strings.Join(info.DownstreamTaskIDs, ","))
+}
+
 // Database-sourced metrics
 
 func (mb *MetricsBuilder) RecordTaskInstanceCountDB(count int64, dagID, taskID, state, operator, pool string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.task.instance.count.db")
-	metric.SetUnit("{tasks}")
-	metric.SetDescription("Task instance count from database aggregation (24h)")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.task.instance.count.db", "{tasks}", "Task instance count from database aggregation (24h)")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(count)
 	dp.Attributes().PutStr("dag.id", dagID)
@@ -396,13 +666,7 @@ func (mb *MetricsBuilder) RecordTaskInstanceCountDB(count int64, dagID, taskID,
 }
 
 func (mb *MetricsBuilder) RecordTaskInstanceAvgDuration(avg float64, dagID, taskID, state string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.task.instance.duration.avg")
-	metric.SetUnit("s")
-	metric.SetDescription("Average task instance duration (24h)")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.task.instance.duration.avg", "s", "Average task instance duration (24h)")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetDoubleValue(avg)
 	dp.Attributes().PutStr("dag.id", dagID)
@@ -411,13 +675,7 @@ func (mb *MetricsBuilder) RecordTaskInstanceAvgDuration(avg float64, dagID, task
 }
 
 func (mb *MetricsBuilder) RecordTaskInstanceMaxDuration(max float64, dagID, taskID, state string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.task.instance.duration.max")
-	metric.SetUnit("s")
-	metric.SetDescription("Maximum task instance duration (24h)")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.task.instance.duration.max", "s", "Maximum task instance duration (24h)")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetDoubleValue(max)
 	dp.Attributes().PutStr("dag.id", dagID)
@@ -425,14 +683,18 @@ func (mb *MetricsBuilder) RecordTaskInstanceMaxDuration(max float64, dagID, task
 	dp.Attributes().PutStr("state", state)
 }
 
+func (mb *MetricsBuilder) RecordTaskInstanceDurationPercentile(value, percentile float64, dagID, taskID, state string, ts time.Time) {
+	dp := mb.gaugeDataPoint("airflow.task.instance.duration.percentile", "s", "Task instance duration percentile (24h)")
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetDoubleValue(value)
+	dp.Attributes().PutStr("dag.id", dagID)
+	dp.Attributes().PutStr("task.id", taskID)
+	dp.Attributes().PutStr("state", state)
+	dp.Attributes().PutDouble("percentile", percentile)
+}
+
 func (mb *MetricsBuilder) RecordDAGRunCountDB(count int64, dagID, state string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.dag.run.count.db")
-	metric.SetUnit("{runs}")
-	metric.SetDescription("DAG run count from database (24h)")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.dag.run.count.db", "{runs}", "DAG run count from database (24h)")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(count)
 	dp.Attributes().PutStr("dag.id", dagID)
@@ -440,99 +702,109 @@ func (mb *MetricsBuilder) RecordDAGRunCountDB(count int64, dagID, state string,
 }
 
 func (mb *MetricsBuilder) RecordDAGRunAvgDuration(avg float64, dagID, state string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.dag.run.duration.avg")
-	metric.SetUnit("s")
-	metric.SetDescription("Average DAG run duration (24h)")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.dag.run.duration.avg", "s", "Average DAG run duration (24h)")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetDoubleValue(avg)
 	dp.Attributes().PutStr("dag.id", dagID)
 	dp.Attributes().PutStr("state", state)
 }
 
+func (mb *MetricsBuilder) RecordDAGRunDurationPercentile(value, percentile float64, dagID, state string, ts time.Time) {
+	dp := mb.gaugeDataPoint("airflow.dag.run.duration.percentile", "s", "DAG run duration percentile (24h)")
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetDoubleValue(value)
+	dp.Attributes().PutStr("dag.id", dagID)
+	dp.Attributes().PutStr("state", state)
+	dp.Attributes().PutDouble("percentile", percentile)
+}
+
+func (mb *MetricsBuilder) RecordDAGRunQueueDuration(avg float64, dagID string, ts time.Time) {
+	dp := mb.gaugeDataPoint("airflow.dag.run.queue_duration", "s", "Average time DAG runs waited between queued and started (24h)")
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetDoubleValue(avg)
+	dp.Attributes().PutStr("dag.id", dagID)
+}
+
+func (mb *MetricsBuilder) RecordTaskInstanceFailures(total int64, dagID string, start, ts time.Time) {
+	dp := mb.sumDataPoint("airflow.task.instance.failures", "{failures}", "Task instances that ended in the failed state since the receiver started", true)
+	dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetIntValue(total)
+	dp.Attributes().PutStr("dag.id", dagID)
+}
+
+func (mb *MetricsBuilder) RecordTaskInstanceRetries(total int64, dagID string, start, ts time.Time) {
+	dp := mb.sumDataPoint("airflow.task.instance.retries", "{retries}", "Task retry attempts started since the receiver started", true)
+	dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetIntValue(total)
+	dp.Attributes().PutStr("dag.id", dagID)
+}
+
+func (mb *MetricsBuilder) RecordTaskReschedules(total int64, dagID, taskID string, start, ts time.Time) {
+	dp := mb.sumDataPoint("airflow.task.reschedules", "{reschedules}", "Sensor reschedules since the receiver started", true)
+	dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetIntValue(total)
+	dp.Attributes().PutStr("dag.id", dagID)
+	dp.Attributes().PutStr("task.id", taskID)
+}
+
+func (mb *MetricsBuilder) RecordTaskRescheduleDelay(total float64, dagID, taskID string, start, ts time.Time) {
+	dp := mb.sumDataPoint("airflow.task.reschedule.delay", "s", "Time sensors asked to wait between pokes since the receiver started", true)
+	dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetDoubleValue(total)
+	dp.Attributes().PutStr("dag.id", dagID)
+	dp.Attributes().PutStr("task.id", taskID)
+}
+
 func (mb *MetricsBuilder) RecordSchedulerTasksScheduled(count int64, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.scheduler.tasks.scheduled")
-	metric.SetUnit("{tasks}")
-	metric.SetDescription("Number of scheduled tasks")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.scheduler.tasks.scheduled", "{tasks}", "Number of scheduled tasks")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(count)
 }
 
 func (mb *MetricsBuilder) RecordSchedulerTasksQueued(count int64, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.scheduler.tasks.queued")
-	metric.SetUnit("{tasks}")
-	metric.SetDescription("Number of queued tasks")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.scheduler.tasks.queued", "{tasks}", "Number of queued tasks")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(count)
 }
 
 func (mb *MetricsBuilder) RecordSchedulerTasksRunning(count int64, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.scheduler.tasks.running")
-	metric.SetUnit("{tasks}")
-	metric.SetDescription("Number of running tasks")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.scheduler.tasks.running", "{tasks}", "Number of running tasks")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(count)
 }
 
 func (mb *MetricsBuilder) RecordSchedulerTasksSuccess24h(count int64, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.scheduler.tasks.success.24h")
-	metric.SetUnit("{tasks}")
-	metric.SetDescription("Number of successful tasks in last 24 hours")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.scheduler.tasks.success.24h", "{tasks}", "Number of successful tasks in last 24 hours")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(count)
 }
 
 func (mb *MetricsBuilder) RecordSchedulerTasksFailed24h(count int64, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.scheduler.tasks.failed.24h")
-	metric.SetUnit("{tasks}")
-	metric.SetDescription("Number of failed tasks in last 24 hours")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.scheduler.tasks.failed.24h", "{tasks}", "Number of failed tasks in last 24 hours")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(count)
 }
 
+func (mb *MetricsBuilder) RecordDAGTasksOrphaned(count int64, dagID, pool string, ts pcommon.Timestamp) {
+	dp := mb.gaugeDataPoint("airflow.dag.tasks.orphaned", "{tasks}", "Task instances running longer than the orphaned task threshold, by DAG and pool")
+	dp.SetTimestamp(ts)
+	dp.SetIntValue(count)
+	dp.Attributes().PutStr("dag.id", dagID)
+	dp.Attributes().PutStr("pool.name", pool)
+}
+
 func (mb *MetricsBuilder) RecordSchedulerTasksOrphaned(count int64, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.scheduler.tasks.orphaned")
-	metric.SetUnit("{tasks}")
-	metric.SetDescription("Number of orphaned tasks (running >1 hour)")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.scheduler.tasks.orphaned", "{tasks}", "Number of task instances running longer than the orphaned task threshold")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(count)
 }
 
 func (mb *MetricsBuilder) RecordSLAMissCount(count int64, dagID string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName("airflow.sla.miss.count")
-	metric.SetUnit("{misses}")
-	metric.SetDescription("Number of SLA misses (24h)")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint("airflow.sla.miss.count", "{misses}", "Number of SLA misses (24h)")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(count)
 	dp.Attributes().PutStr("dag.id", dagID)
@@ -540,17 +812,9 @@ func (mb *MetricsBuilder) RecordSLAMissCount(count int64, dagID string, ts time.
 
 // Generic metrics for StatsD (dynamic metric names)
 
-func (mb *MetricsBuilder) RecordGenericCounter(value int64, metricName string, tags map[string]string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName(metricName)
-	metric.SetUnit("{count}")
-	metric.SetDescription("StatsD counter metric")
-	
-	sum := metric.SetEmptySum()
-	sum.SetIsMonotonic(true)
-	sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
-	
-	dp := sum.DataPoints().AppendEmpty()
+func (mb *MetricsBuilder) RecordGenericCounter(value int64, metricName string, tags map[string]string, start, ts time.Time) {
+	dp := mb.sumDataPoint(metricName, "{count}", "StatsD counter metric", true)
+	dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetIntValue(value)
 	
@@ -560,13 +824,7 @@ func (mb *MetricsBuilder) RecordGenericCounter(value int64, metricName string, t
 }
 
 func (mb *MetricsBuilder) RecordGenericGauge(value float64, metricName string, tags map[string]string, ts time.Time) {
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName(metricName)
-	metric.SetUnit("{value}")
-	metric.SetDescription("StatsD gauge metric")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint(metricName, "{value}", "StatsD gauge metric")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetDoubleValue(value)
 	
@@ -577,13 +835,7 @@ func (mb *MetricsBuilder) RecordGenericGauge(value float64, metricName string, t
 
 func (mb *MetricsBuilder) RecordGenericTimer(avg, min, max float64, metricName string, tags map[string]string, ts time.Time) {
 	// Average
-	metric := mb.sm.Metrics().AppendEmpty()
-	metric.SetName(metricName + ".avg")
-	metric.SetUnit("ms")
-	metric.SetDescription("StatsD timer average")
-	
-	gauge := metric.SetEmptyGauge()
-	dp := gauge.DataPoints().AppendEmpty()
+	dp := mb.gaugeDataPoint(metricName+".avg", "ms", "StatsD timer average")
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.SetDoubleValue(avg)
 	
@@ -592,12 +844,8 @@ func (mb *MetricsBuilder) RecordGenericTimer(avg, min, max float64, metricName s
 	}
 	
 	// Min
-	metricMin := mb.sm.Metrics().AppendEmpty()
-	metricMin.SetName(metricName + ".min")
-	metricMin.SetUnit("ms")
-	
-	gaugeMin := metricMin.SetEmptyGauge()
-	dpMin := gaugeMin.DataPoints().AppendEmpty()
+	mb.declareGaugeMerge(metricName+".min", GaugeMergeMin)
+	dpMin := mb.gaugeDataPoint(metricName+".min", "ms", "StatsD timer minimum")
 	dpMin.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dpMin.SetDoubleValue(min)
 	
@@ -606,12 +854,8 @@ func (mb *MetricsBuilder) RecordGenericTimer(avg, min, max float64, metricName s
 	}
 	
 	// Max
-	metricMax := mb.sm.Metrics().AppendEmpty()
-	metricMax.SetName(metricName + ".max")
-	metricMax.SetUnit("ms")
-	
-	gaugeMax := metricMax.SetEmptyGauge()
-	dpMax := gaugeMax.DataPoints().AppendEmpty()
+	mb.declareGaugeMerge(metricName+".max", GaugeMergeMax)
+	dpMax := mb.gaugeDataPoint(metricName+".max", "ms", "StatsD timer maximum")
 	dpMax.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dpMax.SetDoubleValue(max)
 	
@@ -620,142 +864,596 @@ func (mb *MetricsBuilder) RecordGenericTimer(avg, min, max float64, metricName s
 	}
 }
 
+// RecordStatsDCounter emits a mapped StatsD counter as a cumulative sum
+func (mb *MetricsBuilder) RecordStatsDCounter(value float64, mapping statsDMapping, tags map[string]string, start, ts time.Time) {
+	dp := mb.sumDataPoint(mapping.name, mapping.unit, mapping.description, true)
+	dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetDoubleValue(value)
+	for k, v := range tags {
+		dp.Attributes().PutStr(k, v)
+	}
+}
 
-// Emit returns the accumulated metrics
+// RecordStatsDGauge emits a mapped StatsD gauge
+func (mb *MetricsBuilder) RecordStatsDGauge(value float64, mapping statsDMapping, tags map[string]string, ts time.Time) {
+	mb.declareGaugeMerge(mapping.name, mapping.gaugeMerge)
+	dp := mb.gaugeDataPoint(mapping.name, mapping.unit, mapping.description)
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetDoubleValue(value)
+	for k, v := range tags {
+		dp.Attributes().PutStr(k, v)
+	}
+}
+
+// RecordStatsDTimer emits the avg/min/max of a mapped StatsD timer over the
+// aggregation interval
+func (mb *MetricsBuilder) RecordStatsDTimer(avg, min, max float64, mapping statsDMapping, tags map[string]string, ts time.Time) {
+	for _, stat := range []struct {
+		suffix string
+		value  float64
+		merge  string
+	}{{"avg", avg, ""}, {"min", min, GaugeMergeMin}, {"max", max, GaugeMergeMax}} {
+		mb.declareGaugeMerge(mapping.name+"."+stat.suffix, stat.merge)
+		dp := mb.gaugeDataPoint(mapping.name+"."+stat.suffix, mapping.unit, mapping.description+" ("+stat.suffix+")")
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+		dp.SetDoubleValue(stat.value)
+		for k, v := range tags {
+			dp.Attributes().PutStr(k, v)
+		}
+	}
+}
+
+// RecordStatsDTimerCount emits the estimated number of timer samples, scaled
+// up for sampled clients, as a cumulative sum
+func (mb *MetricsBuilder) RecordStatsDTimerCount(count float64, name, description string, tags map[string]string, start, ts time.Time) {
+	if description == "" {
+		description = "StatsD timer"
+	}
+	dp := mb.sumDataPoint(name+".count", "{samples}", description+" (count)", true)
+	dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetIntValue(int64(math.Round(count)))
+	for k, v := range tags {
+		dp.Attributes().PutStr(k, v)
+	}
+}
+
+// RecordStatsDSet emits the number of unique values a StatsD set received
+// during the interval
+func (mb *MetricsBuilder) RecordStatsDSet(cardinality int, name, description string, tags map[string]string, ts time.Time) {
+	if description == "" {
+		description = "StatsD set"
+	}
+	dp := mb.gaugeDataPoint(name, "{values}", description+" (unique values per interval)")
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetIntValue(int64(cardinality))
+	for k, v := range tags {
+		dp.Attributes().PutStr(k, v)
+	}
+}
+
+// Custom query metrics
+
+func (mb *MetricsBuilder) RecordCustomIntMetric(def CustomQueryMetric, value int64, attrs map[string]string, start, ts time.Time) {
+	mb.appendCustomDataPoint(def, attrs, start, ts).SetIntValue(value)
+}
+
+func (mb *MetricsBuilder) RecordCustomDoubleMetric(def CustomQueryMetric, value float64, attrs map[string]string, start, ts time.Time) {
+	mb.appendCustomDataPoint(def, attrs, start, ts).SetDoubleValue(value)
+}
+
+func (mb *MetricsBuilder) appendCustomDataPoint(def CustomQueryMetric, attrs map[string]string, start, ts time.Time) pmetric.NumberDataPoint {
+	var dp pmetric.NumberDataPoint
+	if def.DataType == CustomDataTypeSum {
+		dp = mb.sumDataPoint(def.MetricName, def.Unit, def.Description, def.Monotonic)
+		dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	} else {
+		mb.declareGaugeMerge(def.MetricName, def.Merge)
+		dp = mb.gaugeDataPoint(def.MetricName, def.Unit, def.Description)
+	}
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	
+	for k, v := range attrs {
+		dp.Attributes().PutStr(k, v)
+	}
+	return dp
+}
+
+// Receiver self-telemetry
+
+func (mb *MetricsBuilder) RecordReceiverDropped(count int64, signal, reason string, start, ts time.Time) {
+	dp := mb.sumDataPoint("airflow.receiver.dropped", "{items}", "Items the receiver intentionally did not emit", true)
+	dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetIntValue(count)
+	dp.Attributes().PutStr("signal", signal)
+	dp.Attributes().PutStr("reason", reason)
+}
+
+// RecordReceiverStatsDListener reports packets read by the StatsD listener and
+// the lines in them by outcome
+func (mb *MetricsBuilder) RecordReceiverStatsDListener(packets int64, lines map[string]int64, start, ts time.Time) {
+	dp := mb.sumDataPoint("airflow.receiver.statsd.packets", "{packets}", "StatsD packets received by the listener", true)
+	dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	dp.SetIntValue(packets)
+	
+	for outcome, count := range lines {
+		dp := mb.sumDataPoint("airflow.receiver.statsd.lines", "{lines}", "StatsD lines received by the listener, by outcome", true)
+		dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+		dp.SetIntValue(count)
+		dp.Attributes().PutStr("outcome", outcome)
+	}
+}
+
+// RecordReceiverDBPoolStats reports the receiver's own database/sql pool
+func (mb *MetricsBuilder) RecordReceiverDBPoolStats(stats sql.DBStats, scraper string, start, ts time.Time) {
+	now := pcommon.NewTimestampFromTime(ts)
+	gauges := []struct {
+		name, description string
+		value             int
+	}{
+		{"airflow.receiver.db.pool.connections.open", "Open connections in the receiver's pool, in use or idle", stats.OpenConnections},
+		{"airflow.receiver.db.pool.connections.in_use", "Connections in the receiver's pool currently running a query", stats.InUse},
+		{"airflow.receiver.db.pool.connections.idle", "Idle connections in the receiver's pool", stats.Idle},
+		{"airflow.receiver.db.pool.connections.max", "Maximum open connections allowed by max_open_conns", stats.MaxOpenConnections},
+	}
+	for _, g := range gauges {
+		dp := mb.gaugeDataPoint(g.name, "{connections}", g.description)
+		dp.SetTimestamp(now)
+		dp.SetIntValue(int64(g.value))
+		dp.Attributes().PutStr("scraper", scraper)
+	}
+	
+	dp := mb.sumDataPoint("airflow.receiver.db.pool.wait.count", "{waits}", "Queries that waited for a free connection in the receiver's pool", true)
+	dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	dp.SetTimestamp(now)
+	dp.SetIntValue(stats.WaitCount)
+	dp.Attributes().PutStr("scraper", scraper)
+	
+	dp = mb.sumDataPoint("airflow.receiver.db.pool.wait.duration", "s", "Total time queries waited for a free connection in the receiver's pool", true)
+	dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	dp.SetTimestamp(now)
+	dp.SetDoubleValue(stats.WaitDuration.Seconds())
+	dp.Attributes().PutStr("scraper", scraper)
+}
+
+// Emit returns the accumulated metrics and resets the builder, so each
+// scrape only reports what it recorded
 func (mb *MetricsBuilder) Emit() pmetric.Metrics {
-	return mb.metrics
+	metrics := mb.metrics
+	mb.reset()
+	if len(mb.excluded) > 0 {
+		mb.removeExcluded(metrics)
+	}
+	// Values are redacted first, since truncated values can collide too
+	mb.redactor.redactMetrics(metrics)
+	if keys := mb.strippedAttributes(); len(keys) > 0 || mb.redactor.truncates() {
+		mb.removeAttributes(metrics, keys)
+	}
+	if mb.resourcePerDAG {
+		metrics = splitByDAG(metrics)
+	}
+	return metrics
+}
+
+// SetResourcePerDAG reports each DAG's data points on its own resource with
+// an airflow.dag.id attribute instead of a dag.id point attribute
+func (mb *MetricsBuilder) SetResourcePerDAG(enabled bool) {
+	mb.resourcePerDAG = enabled
+}
+
+// SetRedactor redacts attribute values of every batch
+func (mb *MetricsBuilder) SetRedactor(r *Redactor) {
+	mb.redactor = r
+}
+
+// SetExcludedMetrics drops the named metrics from every batch, for metrics
+// another scraper owns
+func (mb *MetricsBuilder) SetExcludedMetrics(names []string) {
+	mb.excluded = make(map[string]bool, len(names))
+	for _, name := range names {
+		mb.excluded[name] = true
+	}
+}
+
+func (mb *MetricsBuilder) removeExcluded(metrics pmetric.Metrics) {
+	rms := metrics.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			sms.At(j).Metrics().RemoveIf(func(m pmetric.Metric) bool {
+				return mb.excluded[m.Name()]
+			})
+		}
+	}
+}
+
+// OptionalAttributes can be disabled with SetDisabledAttributes. Each adds a
+// series per DAG run, task instance or worker.
+var OptionalAttributes = []string{"run.id", "dag_run.id", "hostname", "map_index"}
+
+// SetDisabledAttributes drops the given attribute keys from every data point.
+// Points that only differed by a dropped attribute are merged: sums and
+// histograms add up, and gauges combine as declared in gaugeMerges or by
+// their configuration. Other gauges, such as averages, collapse into the one
+// recorded last and are counted as collapsed drops.
+func (mb *MetricsBuilder) SetDisabledAttributes(keys []string) {
+	mb.disabledAttributes = make(map[string]bool, len(keys))
+	for _, key := range keys {
+		mb.disabledAttributes[key] = true
+	}
+}
+
+// SetDropTracker records the points lost when disabled attributes collapse
+// points that cannot be merged
+func (mb *MetricsBuilder) SetDropTracker(drops *DropTracker) {
+	mb.drops = drops
+}
+
+// strippedAttributes are the disabled attributes and those redaction drops
+func (mb *MetricsBuilder) strippedAttributes() map[string]bool {
+	dropped := mb.redactor.droppedKeys()
+	if len(dropped) == 0 {
+		return mb.disabledAttributes
+	}
+	keys := make(map[string]bool, len(mb.disabledAttributes)+len(dropped))
+	for key := range mb.disabledAttributes {
+		keys[key] = true
+	}
+	for _, key := range dropped {
+		keys[key] = true
+	}
+	return keys
+}
+
+// removeAttributes removes keys from every data point and merges the points
+// that no longer differ
+func (mb *MetricsBuilder) removeAttributes(metrics pmetric.Metrics, keys map[string]bool) {
+	rms := metrics.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			ms := sms.At(j).Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				m := ms.At(k)
+				var collapsed int
+				switch m.Type() {
+				case pmetric.MetricTypeGauge:
+					collapsed = stripPoints(m.Gauge().DataPoints(), keys, mb.gaugeMerger(m.Name()))
+				case pmetric.MetricTypeSum:
+					collapsed = stripPoints(m.Sum().DataPoints(), keys, mergeSumPoints)
+				case pmetric.MetricTypeHistogram:
+					collapsed = stripPoints(m.Histogram().DataPoints(), keys, mergeHistogramPoints)
+				case pmetric.MetricTypeExponentialHistogram:
+					collapsed = stripPoints(m.ExponentialHistogram().DataPoints(), keys, mergeExponentialHistogramPoints)
+				}
+				if collapsed > 0 {
+					mb.drops.Record(SignalMetrics, DropReasonCollapsed, int64(collapsed),
+						zap.String("metric", m.Name()))
+				}
+			}
+		}
+	}
+}
+
+// dataPointSlice is implemented by the pmetric data point slices
+type dataPointSlice[P any] interface {
+	Len() int
+	At(i int) P
+	RemoveIf(f func(P) bool)
+}
+
+// dataPoint is implemented by the pmetric data points
+type dataPoint[P any] interface {
+	Attributes() pcommon.Map
+	CopyTo(dest P)
+}
+
+// stripPoints removes keys from every point, then merges the points of each
+// attribute set into the first one. A point merge cannot combine replaces it
+// instead, so the last one recorded wins; the number of those is returned.
+// A nil merge combines nothing.
+func stripPoints[P dataPoint[P]](dps dataPointSlice[P], keys map[string]bool, merge func(dst, src P) bool) int {
+	series := make([]string, dps.Len())
+	for i := range series {
+		attrs := dps.At(i).Attributes()
+		for key := range keys {
+			attrs.Remove(key)
+		}
+		series[i] = attributeSetKey(attrs)
+	}
+	
+	first := make(map[string]int, len(series))
+	collapsed := 0
+	for i, key := range series {
+		j, ok := first[key]
+		if !ok {
+			first[key] = i
+			continue
+		}
+		if merge == nil || !merge(dps.At(j), dps.At(i)) {
+			dps.At(i).CopyTo(dps.At(j))
+			collapsed++
+		}
+	}
+	i := -1
+	dps.RemoveIf(func(P) bool {
+		i++
+		return first[series[i]] != i
+	})
+	return collapsed
+}
+
+// Ways colliding points of a gauge can be combined
+const (
+	GaugeMergeSum = "sum"
+	GaugeMergeMax = "max"
+	GaugeMergeMin = "min"
+)
+
+// gaugeMerges declares how the builder's own gauges combine. Gauges not
+// listed, such as averages, percentiles, ages, ratios and states, collapse.
+var gaugeMerges = map[string]string{
+	"airflow.connections.count":            GaugeMergeSum,
+	"airflow.dag.info":                     GaugeMergeSum,
+	"airflow.dag.run.count.db":             GaugeMergeSum,
+	"airflow.dag.tasks.orphaned":           GaugeMergeSum,
+	"airflow.dag.warnings":                 GaugeMergeSum,
+	"airflow.dag_processing.dagbag.size":   GaugeMergeSum,
+	"airflow.dag_processing.import_errors": GaugeMergeSum,
+	"airflow.dag_runs.by_state":            GaugeMergeSum,
+	"airflow.dags.count":                   GaugeMergeSum,
+	"airflow.database.table.rows":          GaugeMergeSum,
+	"airflow.database.table.rows.expired":  GaugeMergeSum,
+	"airflow.database.table.size":          GaugeMergeSum,
+	"airflow.dataset.events":               GaugeMergeSum,
+	"airflow.datasets.count":               GaugeMergeSum,
+	"airflow.import_errors.count":          GaugeMergeSum,
+	"airflow.jobs.alive":                   GaugeMergeSum,
+	"airflow.plugins.count":                GaugeMergeSum,
+	"airflow.pool.slots.deferred":          GaugeMergeSum,
+	"airflow.pool.slots.open":              GaugeMergeSum,
+	"airflow.pool.slots.queued":            GaugeMergeSum,
+	"airflow.pool.slots.running":           GaugeMergeSum,
+	"airflow.pool.slots.scheduled":         GaugeMergeSum,
+	"airflow.pool.slots.total":             GaugeMergeSum,
+	"airflow.pool.slots.used":              GaugeMergeSum,
+	"airflow.providers.count":              GaugeMergeSum,
+	"airflow.scheduler.tasks.failed.24h":   GaugeMergeSum,
+	"airflow.scheduler.tasks.orphaned":     GaugeMergeSum,
+	"airflow.scheduler.tasks.queued":       GaugeMergeSum,
+	"airflow.scheduler.tasks.running":      GaugeMergeSum,
+	"airflow.scheduler.tasks.scheduled":    GaugeMergeSum,
+	"airflow.scheduler.tasks.success.24h":  GaugeMergeSum,
+	"airflow.sla.miss.count":               GaugeMergeSum,
+	"airflow.task.info":                    GaugeMergeSum,
+	"airflow.task.instance.count.db":       GaugeMergeSum,
+	"airflow.task.instance.duration.max":   GaugeMergeMax,
+	"airflow.task.instance.tries":          GaugeMergeSum,
+	"airflow.task.mapped.instances":        GaugeMergeSum,
+	"airflow.task_instances.by_state":      GaugeMergeSum,
+	"airflow.variables.count":              GaugeMergeSum,
+	"airflow.worker.task_instances":        GaugeMergeSum,
+}
+
+// declareGaugeMerge records how a configured gauge combines; an empty merge
+// leaves it to collapse
+func (mb *MetricsBuilder) declareGaugeMerge(name, merge string) {
+	if merge == "" {
+		return
+	}
+	if mb.declaredMerges == nil {
+		mb.declaredMerges = make(map[string]string)
+	}
+	mb.declaredMerges[name] = merge
+}
+
+// gaugeMerger returns how colliding points of a gauge combine, or nil when
+// they cannot be combined
+func (mb *MetricsBuilder) gaugeMerger(name string) func(dst, src pmetric.NumberDataPoint) bool {
+	merge, ok := gaugeMerges[name]
+	if !ok {
+		merge = mb.declaredMerges[name]
+	}
+	switch merge {
+	case GaugeMergeSum:
+		return mergeSumPoints
+	case GaugeMergeMax:
+		return mergeNumberPoints(math.Max)
+	case GaugeMergeMin:
+		return mergeNumberPoints(math.Min)
+	}
+	return nil
+}
+
+// mergeNumberPoints combines two number points with f, keeping integer
+// values when both are integers
+func mergeNumberPoints(f func(a, b float64) float64) func(dst, src pmetric.NumberDataPoint) bool {
+	return func(dst, src pmetric.NumberDataPoint) bool {
+		if dst.ValueType() == pmetric.NumberDataPointValueTypeInt && src.ValueType() == pmetric.NumberDataPointValueTypeInt {
+			dst.SetIntValue(int64(f(float64(dst.IntValue()), float64(src.IntValue()))))
+		} else {
+			dst.SetDoubleValue(f(numberValue(dst), numberValue(src)))
+		}
+		mergeTimestamps(dst, src)
+		return true
+	}
+}
+
+func mergeSumPoints(dst, src pmetric.NumberDataPoint) bool {
+	if dst.ValueType() == pmetric.NumberDataPointValueTypeInt && src.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		dst.SetIntValue(dst.IntValue() + src.IntValue())
+	} else {
+		dst.SetDoubleValue(numberValue(dst) + numberValue(src))
+	}
+	mergeTimestamps(dst, src)
+	return true
+}
+
+func numberValue(dp pmetric.NumberDataPoint) float64 {
+	if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		return float64(dp.IntValue())
+	}
+	return dp.DoubleValue()
+}
+
+// timestamped is implemented by the pmetric data points
+type timestamped interface {
+	StartTimestamp() pcommon.Timestamp
+	SetStartTimestamp(pcommon.Timestamp)
+	Timestamp() pcommon.Timestamp
+	SetTimestamp(pcommon.Timestamp)
+}
+
+// mergeTimestamps widens dst's time range to cover src's
+func mergeTimestamps(dst, src timestamped) {
+	if start := src.StartTimestamp(); start != 0 && (dst.StartTimestamp() == 0 || start < dst.StartTimestamp()) {
+		dst.SetStartTimestamp(start)
+	}
+	if src.Timestamp() > dst.Timestamp() {
+		dst.SetTimestamp(src.Timestamp())
+	}
+}
+
+// mergeHistogramPoints adds src's buckets to dst's when both share bounds
+func mergeHistogramPoints(dst, src pmetric.HistogramDataPoint) bool {
+	if !slices.Equal(dst.ExplicitBounds().AsRaw(), src.ExplicitBounds().AsRaw()) ||
+		dst.BucketCounts().Len() != src.BucketCounts().Len() {
+		return false
+	}
+	for i := 0; i < src.BucketCounts().Len(); i++ {
+		dst.BucketCounts().SetAt(i, dst.BucketCounts().At(i)+src.BucketCounts().At(i))
+	}
+	mergeHistogramSummary(dst, src)
+	mergeTimestamps(dst, src)
+	return true
+}
+
+// mergeExponentialHistogramPoints adds src's buckets to dst's when both share
+// scale and zero threshold
+func mergeExponentialHistogramPoints(dst, src pmetric.ExponentialHistogramDataPoint) bool {
+	if dst.Scale() != src.Scale() || dst.ZeroThreshold() != src.ZeroThreshold() {
+		return false
+	}
+	dst.SetZeroCount(dst.ZeroCount() + src.ZeroCount())
+	mergeBuckets(dst.Positive(), src.Positive())
+	mergeBuckets(dst.Negative(), src.Negative())
+	mergeHistogramSummary(dst, src)
+	mergeTimestamps(dst, src)
+	return true
+}
+
+// mergeBuckets adds src's bucket counts to dst's, aligned by index
+func mergeBuckets(dst, src pmetric.ExponentialHistogramDataPointBuckets) {
+	if src.BucketCounts().Len() == 0 {
+		return
+	}
+	if dst.BucketCounts().Len() == 0 {
+		src.CopyTo(dst)
+		return
+	}
+	offset := min(dst.Offset(), src.Offset())
+	end := max(dst.Offset()+int32(dst.BucketCounts().Len()), src.Offset()+int32(src.BucketCounts().Len()))
+	counts := make([]uint64, end-offset)
+	for i, c := range dst.BucketCounts().AsRaw() {
+		counts[int(dst.Offset()-offset)+i] += c
+	}
+	for i, c := range src.BucketCounts().AsRaw() {
+		counts[int(src.Offset()-offset)+i] += c
+	}
+	dst.SetOffset(offset)
+	dst.BucketCounts().FromRaw(counts)
+}
+
+// histogramSummary is implemented by both histogram data points
+type histogramSummary interface {
+	Count() uint64
+	SetCount(uint64)
+	Sum() float64
+	HasSum() bool
+	SetSum(float64)
+	Min() float64
+	HasMin() bool
+	SetMin(float64)
+	Max() float64
+	HasMax() bool
+	SetMax(float64)
+}
+
+// mergeHistogramSummary adds counts and sums and keeps the extremes
+func mergeHistogramSummary(dst, src histogramSummary) {
+	dst.SetCount(dst.Count() + src.Count())
+	if src.HasSum() {
+		dst.SetSum(dst.Sum() + src.Sum())
+	}
+	if src.HasMin() && (!dst.HasMin() || src.Min() < dst.Min()) {
+		dst.SetMin(src.Min())
+	}
+	if src.HasMax() && (!dst.HasMax() || src.Max() > dst.Max()) {
+		dst.SetMax(src.Max())
+	}
+}
+
+// attributeSetKey identifies an attribute set independent of insertion order
+func attributeSetKey(attrs pcommon.Map) string {
+	pairs := make([]string, 0, attrs.Len())
+	attrs.Range(func(k string, v pcommon.Value) bool {
+		pairs = append(pairs, k+"="+v.AsString())
+		return true
+	})
+	sort.Strings(pairs)
+	return strings.Join(pairs, "\x00")
 }
 
 // Scraper health metrics
 func (mb *MetricsBuilder) RecordScraperTotalScrapes(value int64, scraperType string, ts time.Time) {
-	dp := mb.metrics.ResourceMetrics().AppendEmpty().
-		ScopeMetrics().AppendEmpty().
-		Metrics().AppendEmpty().
-		SetEmptyGauge().
-		DataPoints().AppendEmpty()
-	
+	dp := mb.gaugeDataPoint("airflow.scraper.scrapes.total", "{scrapes}", "Total number of scrapes attempted")
 	dp.SetIntValue(value)
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.Attributes().PutStr("scraper.type", scraperType)
-	
-	metric := mb.metrics.ResourceMetrics().At(mb.metrics.ResourceMetrics().Len() - 1).
-		ScopeMetrics().At(0).
-		Metrics().At(mb.metrics.ResourceMetrics().At(mb.metrics.ResourceMetrics().Len() - 1).ScopeMetrics().At(0).Metrics().Len() - 1)
-	metric.SetName("airflow.scraper.scrapes.total")
-	metric.SetDescription("Total number of scrapes attempted")
-	metric.SetUnit("{scrapes}")
 }
 
 func (mb *MetricsBuilder) RecordScraperSuccessfulScrapes(value int64, scraperType string, ts time.Time) {
-	dp := mb.metrics.ResourceMetrics().AppendEmpty().
-		ScopeMetrics().AppendEmpty().
-		Metrics().AppendEmpty().
-		SetEmptyGauge().
-		DataPoints().AppendEmpty()
-	
+	dp := mb.gaugeDataPoint("airflow.scraper.scrapes.successful", "{scrapes}", "Number of successful scrapes")
 	dp.SetIntValue(value)
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.Attributes().PutStr("scraper.type", scraperType)
-	
-	metric := mb.metrics.ResourceMetrics().At(mb.metrics.ResourceMetrics().Len() - 1).
-		ScopeMetrics().At(0).
-		Metrics().At(mb.metrics.ResourceMetrics().At(mb.metrics.ResourceMetrics().Len() - 1).ScopeMetrics().At(0).Metrics().Len() - 1)
-	metric.SetName("airflow.scraper.scrapes.successful")
-	metric.SetDescription("Number of successful scrapes")
-	metric.SetUnit("{scrapes}")
 }
 
 func (mb *MetricsBuilder) RecordScraperFailedScrapes(value int64, scraperType string, ts time.Time) {
-	dp := mb.metrics.ResourceMetrics().AppendEmpty().
-		ScopeMetrics().AppendEmpty().
-		Metrics().AppendEmpty().
-		SetEmptyGauge().
-		DataPoints().AppendEmpty()
-	
+	dp := mb.gaugeDataPoint("airflow.scraper.scrapes.failed", "{scrapes}", "Number of failed scrapes")
 	dp.SetIntValue(value)
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.Attributes().PutStr("scraper.type", scraperType)
-	
-	metric := mb.metrics.ResourceMetrics().At(mb.metrics.ResourceMetrics().Len() - 1).
-		ScopeMetrics().At(0).
-		Metrics().At(mb.metrics.ResourceMetrics().At(mb.metrics.ResourceMetrics().Len() - 1).ScopeMetrics().At(0).Metrics().Len() - 1)
-	metric.SetName("airflow.scraper.scrapes.failed")
-	metric.SetDescription("Number of failed scrapes")
-	metric.SetUnit("{scrapes}")
 }
 
 func (mb *MetricsBuilder) RecordScraperHealthStatus(value int64, scraperType string, ts time.Time) {
-	dp := mb.metrics.ResourceMetrics().AppendEmpty().
-		ScopeMetrics().AppendEmpty().
-		Metrics().AppendEmpty().
-		SetEmptyGauge().
-		DataPoints().AppendEmpty()
-	
+	dp := mb.gaugeDataPoint("airflow.scraper.health", "{status}", "Scraper health status (1=healthy, 0=unhealthy)")
 	dp.SetIntValue(value)
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.Attributes().PutStr("scraper.type", scraperType)
-	
-	metric := mb.metrics.ResourceMetrics().At(mb.metrics.ResourceMetrics().Len() - 1).
-		ScopeMetrics().At(0).
-		Metrics().At(mb.metrics.ResourceMetrics().At(mb.metrics.ResourceMetrics().Len() - 1).ScopeMetrics().At(0).Metrics().Len() - 1)
-	metric.SetName("airflow.scraper.health")
-	metric.SetDescription("Scraper health status (1=healthy, 0=unhealthy)")
-	metric.SetUnit("{status}")
 }
 
 func (mb *MetricsBuilder) RecordScraperLastDuration(value float64, scraperType string, ts time.Time) {
-	dp := mb.metrics.ResourceMetrics().AppendEmpty().
-		ScopeMetrics().AppendEmpty().
-		Metrics().AppendEmpty().
-		SetEmptyGauge().
-		DataPoints().AppendEmpty()
-	
+	dp := mb.gaugeDataPoint("airflow.scraper.duration.last", "s", "Duration of last scrape")
 	dp.SetDoubleValue(value)
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.Attributes().PutStr("scraper.type", scraperType)
-	
-	metric := mb.metrics.ResourceMetrics().At(mb.metrics.ResourceMetrics().Len() - 1).
-		ScopeMetrics().At(0).
-		Metrics().At(mb.metrics.ResourceMetrics().At(mb.metrics.ResourceMetrics().Len() - 1).ScopeMetrics().At(0).Metrics().Len() - 1)
-	metric.SetName("airflow.scraper.duration.last")
-	metric.SetDescription("Duration of last scrape")
-	metric.SetUnit("s")
 }
 
 func (mb *MetricsBuilder) RecordScraperAvgDuration(value float64, scraperType string, ts time.Time) {
-	dp := mb.metrics.ResourceMetrics().AppendEmpty().
-		ScopeMetrics().AppendEmpty().
-		Metrics().AppendEmpty().
-		SetEmptyGauge().
-		DataPoints().AppendEmpty()
-	
+	dp := mb.gaugeDataPoint("airflow.scraper.duration.avg", "s", "Average scrape duration")
 	dp.SetDoubleValue(value)
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.Attributes().PutStr("scraper.type", scraperType)
-	
-	metric := mb.metrics.ResourceMetrics().At(mb.metrics.ResourceMetrics().Len() - 1).
-		ScopeMetrics().At(0).
-		Metrics().At(mb.metrics.ResourceMetrics().At(mb.metrics.ResourceMetrics().Len() - 1).ScopeMetrics().At(0).Metrics().Len() - 1)
-	metric.SetName("airflow.scraper.duration.avg")
-	metric.SetDescription("Average scrape duration")
-	metric.SetUnit("s")
 }
 
 func (mb *MetricsBuilder) RecordScraperConsecutiveErrors(value int64, scraperType string, ts time.Time) {
-	dp := mb.metrics.ResourceMetrics().AppendEmpty().
-		ScopeMetrics().AppendEmpty().
-		Metrics().AppendEmpty().
-		SetEmptyGauge().
-		DataPoints().AppendEmpty()
-	
+	dp := mb.gaugeDataPoint("airflow.scraper.errors.consecutive", "{errors}", "Number of consecutive scrape errors")
 	dp.SetIntValue(value)
 	dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	dp.Attributes().PutStr("scraper.type", scraperType)
-	
-	metric := mb.metrics.ResourceMetrics().At(mb.metrics.ResourceMetrics().Len() - 1).
-		ScopeMetrics().At(0).
-		Metrics().At(mb.metrics.ResourceMetrics().At(mb.metrics.ResourceMetrics().Len() - 1).ScopeMetrics().At(0).Metrics().Len() - 1)
-	metric.SetName("airflow.scraper.errors.consecutive")
-	metric.SetDescription("Number of consecutive scrape errors")
-	metric.SetUnit("{errors}")
 }
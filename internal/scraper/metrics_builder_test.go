@@ -0,0 +1,179 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+// recordScrape records the same input a scrape of an unchanged deployment
+// would
+func recordScrape(mb *MetricsBuilder, now time.Time) {
+	ts := pcommon.NewTimestampFromTime(now)
+	for _, dagID := range []string{"etl", "reporting"} {
+		mb.RecordDAGRunDuration(12.5, dagID, "scheduled__1", "scheduled", "success", ts)
+		mb.RecordDAGRunCount(3, dagID, "success", ts, ts)
+		mb.RecordTaskInstanceDuration(4.2, dagID, "extract", "scheduled__1", "success", ts)
+	}
+	mb.RecordPoolSlotsOpen(8, "default_pool", ts)
+	mb.RecordPoolSlotsUsed(2, "default_pool", ts)
+	mb.RecordSchedulerHealth("healthy", now)
+	mb.RecordVariableCount(5, now)
+}
+
+func TestMetricsBuilderEmitResets(t *testing.T) {
+	mb := NewMetricsBuilder(receiver.Settings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}})
+
+	var wantMetrics, wantPoints int
+	for i := 0; i < 5; i++ {
+		recordScrape(mb, time.Now())
+		md := mb.Emit()
+
+		if i == 0 {
+			wantMetrics, wantPoints = md.MetricCount(), md.DataPointCount()
+			if wantPoints == 0 {
+				t.Fatal("first scrape emitted no data points")
+			}
+		}
+		if got := md.MetricCount(); got != wantMetrics {
+			t.Errorf("scrape %d: got %d metrics, want %d", i, got, wantMetrics)
+		}
+		if got := md.DataPointCount(); got != wantPoints {
+			t.Errorf("scrape %d: got %d data points, want %d", i, got, wantPoints)
+		}
+
+		// Nothing of the emitted batch is left on the builder
+		if got := mb.metrics.MetricCount(); got != 0 {
+			t.Errorf("scrape %d: builder holds %d metrics after Emit", i, got)
+		}
+		if got := len(mb.byName); got != 0 {
+			t.Errorf("scrape %d: builder indexes %d metrics after Emit", i, got)
+		}
+		if got := mb.metrics.ResourceMetrics().Len(); got != 1 {
+			t.Errorf("scrape %d: builder holds %d resources after Emit, want 1", i, got)
+		}
+	}
+
+	if got := mb.Emit().DataPointCount(); got != 0 {
+		t.Errorf("Emit without records returned %d data points", got)
+	}
+}
+
+func TestMetricsBuilderMergesDisabledAttributes(t *testing.T) {
+	drops := NewDropTracker(zap.NewNop())
+	mb := NewMetricsBuilder(receiver.Settings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}})
+	mb.SetDisabledAttributes([]string{"hostname"})
+	mb.SetDropTracker(drops)
+
+	ts := pcommon.NewTimestampFromTime(time.Now())
+	mb.RecordWorkerTaskInstances(3, "worker-1", "running", ts)
+	mb.RecordWorkerTaskInstances(4, "worker-2", "running", ts)
+	mb.RecordWorkerTaskDurationAvg(2, "worker-1", ts)
+	mb.RecordWorkerTaskDurationAvg(5, "worker-2", ts)
+	md := mb.Emit()
+
+	values := make(map[string]pmetric.NumberDataPointSlice)
+	ms := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < ms.Len(); i++ {
+		values[ms.At(i).Name()] = ms.At(i).Gauge().DataPoints()
+	}
+
+	// Counts add up
+	if dps := values["airflow.worker.task_instances"]; dps.Len() != 1 || numberValue(dps.At(0)) != 7 {
+		t.Errorf("task instances: got %d points, want one point of 7", dps.Len())
+	}
+	// Averages cannot be combined, so the last one wins and the other is a drop
+	if dps := values["airflow.worker.task.duration.avg"]; dps.Len() != 1 || numberValue(dps.At(0)) != 5 {
+		t.Errorf("duration avg: got %d points, want one point of 5", dps.Len())
+	}
+	if got := drops.counts[dropKey{signal: SignalMetrics, reason: DropReasonCollapsed}]; got != 1 {
+		t.Errorf("got %d collapsed drops, want 1", got)
+	}
+}
+
+func TestMergeExponentialHistogramBuckets(t *testing.T) {
+	dst := pmetric.NewExponentialHistogramDataPointBuckets()
+	dst.SetOffset(2)
+	dst.BucketCounts().FromRaw([]uint64{1, 1})
+	src := pmetric.NewExponentialHistogramDataPointBuckets()
+	src.SetOffset(1)
+	src.BucketCounts().FromRaw([]uint64{5, 5, 5, 5})
+
+	mergeBuckets(dst, src)
+
+	if dst.Offset() != 1 {
+		t.Errorf("got offset %d, want 1", dst.Offset())
+	}
+	if got, want := dst.BucketCounts().AsRaw(), []uint64{5, 6, 6, 5}; !slices.Equal(got, want) {
+		t.Errorf("got buckets %v, want %v", got, want)
+	}
+}
+
+func TestMetricsBuilderMergesRedactedAttributes(t *testing.T) {
+	mb := NewMetricsBuilder(receiver.Settings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}})
+	mb.SetRedactor(NewRedactor("", map[string]RedactionRule{
+		"hostname": {Action: RedactionDrop},
+		"state":    {Action: RedactionTruncate, Length: 3},
+	}))
+
+	ts := pcommon.NewTimestampFromTime(time.Now())
+	mb.RecordWorkerTaskInstances(3, "worker-1", "running", ts)
+	mb.RecordWorkerTaskInstances(4, "worker-2", "running", ts)
+	mb.RecordWorkerTaskInstances(5, "worker-1", "runnable", ts)
+	md := mb.Emit()
+
+	dps := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints()
+	if dps.Len() != 1 || numberValue(dps.At(0)) != 12 {
+		t.Fatalf("got %d points, want one point of 12", dps.Len())
+	}
+	if _, ok := dps.At(0).Attributes().Get("hostname"); ok {
+		t.Error("dropped attribute is still present")
+	}
+}
+
+func TestMetricsBuilderMergesDeclaredGaugesOnly(t *testing.T) {
+	drops := NewDropTracker(zap.NewNop())
+	mb := NewMetricsBuilder(receiver.Settings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}})
+	mb.SetDisabledAttributes([]string{"hostname"})
+	mb.SetDropTracker(drops)
+
+	now := time.Now()
+	summed := CustomQueryMetric{MetricName: "custom.summed", Unit: "{runs}", DataType: CustomDataTypeGauge, Merge: GaugeMergeSum}
+	undeclared := CustomQueryMetric{MetricName: "custom.percent", Unit: "{percent}", DataType: CustomDataTypeGauge}
+	for i, host := range []string{"worker-1", "worker-2"} {
+		attrs := map[string]string{"hostname": host}
+		mb.RecordCustomDoubleMetric(summed, float64(i+1), attrs, now, now)
+		mb.RecordCustomDoubleMetric(undeclared, float64(10*(i+1)), attrs, now, now)
+		mb.RecordGenericGauge(float64(i+1), "statsd.gauge", attrs, now)
+	}
+	md := mb.Emit()
+
+	values := make(map[string]float64)
+	ms := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < ms.Len(); i++ {
+		dps := ms.At(i).Gauge().DataPoints()
+		if dps.Len() != 1 {
+			t.Fatalf("%s: got %d points, want 1", ms.At(i).Name(), dps.Len())
+		}
+		values[ms.At(i).Name()] = numberValue(dps.At(0))
+	}
+
+	want := map[string]float64{"custom.summed": 3, "custom.percent": 20, "statsd.gauge": 2}
+	for name, value := range want {
+		if values[name] != value {
+			t.Errorf("%s: got %v, want %v", name, values[name], value)
+		}
+	}
+	if got := drops.counts[dropKey{signal: SignalMetrics, reason: DropReasonCollapsed}]; got != 2 {
+		t.Errorf("got %d collapsed drops, want 2", got)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"go.uber.org/zap"
+)
+
+// Sessions are renewed well before MWAA expires them so a scrape rarely has to
+// log in again after a rejected request
+const mwaaSessionLifetime = time.Hour
+
+// emptyPayloadHash is the SigV4 payload hash of a request without a body
+var emptyPayloadHash = func() string {
+	sum := sha256.Sum256(nil)
+	return hex.EncodeToString(sum[:])
+}()
+
+// MWAAConfig identifies an Amazon MWAA environment whose webserver only
+// accepts sessions created from an IAM-signed web login token
+type MWAAConfig struct {
+	EnvironmentName string
+	Region          string
+	// RoleARN is assumed before requesting tokens when set
+	RoleARN string
+}
+
+type mwaaWebToken struct {
+	WebServerHostname string `json:"WebServerHostname"`
+	WebToken          string `json:"WebToken"`
+}
+
+// mwaaLogin creates webserver sessions from IAM-signed web login tokens
+type mwaaLogin struct {
+	base        http.RoundTripper
+	cfg         MWAAConfig
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	awsClient   *http.Client
+	logger      *zap.Logger
+}
+
+// newMWAATransport resolves AWS credentials from the default chain, assuming
+// RoleARN when configured, and sends requests to the environment's webserver
+// with an MWAA session
+func newMWAATransport(ctx context.Context, base http.RoundTripper, cfg MWAAConfig, logger *zap.Logger) (*sessionTransport, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	credentials, err := loadAWSCredentials(ctx, cfg.Region, cfg.RoleARN)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &mwaaLogin{
+		base:        base,
+		cfg:         cfg,
+		credentials: credentials,
+		signer:      v4.NewSigner(),
+		awsClient:   &http.Client{Timeout: 30 * time.Second},
+		logger:      logger,
+	}
+	return newSessionTransport(base, mwaaSessionLifetime, l.newSession), nil
+}
+
+func (l *mwaaLogin) newSession(ctx context.Context) (*webSession, error) {
+	token, err := l.createWebLoginToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	session, err := l.login(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	l.logger.Debug("Created MWAA webserver session",
+		zap.String("environment", l.cfg.EnvironmentName),
+		zap.String("webserver", token.WebServerHostname))
+	return session, nil
+}
+
+// createWebLoginToken calls the MWAA CreateWebLoginToken API, signed with
+// the receiver's AWS credentials
+func (l *mwaaLogin) createWebLoginToken(ctx context.Context) (*mwaaWebToken, error) {
+	endpoint := fmt.Sprintf("https://env.airflow.%s.amazonaws.com/webtoken/%s",
+		l.cfg.Region, url.PathEscape(l.cfg.EnvironmentName))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := l.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+	if err := l.signer.SignHTTP(ctx, creds, req, emptyPayloadHash, "airflow", l.cfg.Region, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to sign MWAA request: %w", err)
+	}
+
+	resp, err := l.awsClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("%w: CreateWebLoginToken for %s returned %d: %s",
+			ErrAuthFailed, l.cfg.EnvironmentName, resp.StatusCode, strings.TrimSpace(string(body)))
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("CreateWebLoginToken for %s returned %d: %s",
+			l.cfg.EnvironmentName, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var token mwaaWebToken
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to decode web login token: %w", err)
+	}
+	if token.WebServerHostname == "" || token.WebToken == "" {
+		return nil, fmt.Errorf("CreateWebLoginToken for %s returned an empty token", l.cfg.EnvironmentName)
+	}
+	return &token, nil
+}
+
+// login exchanges a web login token for a webserver session cookie
+func (l *mwaaLogin) login(ctx context.Context, token *mwaaWebToken) (*webSession, error) {
+	form := url.Values{"token": {token.WebToken}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		"https://"+token.WebServerHostname+"/aws_mwaa/login", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := noRedirectClient(l.base, nil).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("%w: MWAA login returned %d", ErrAuthFailed, resp.StatusCode)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("MWAA login returned %d", resp.StatusCode)
+	}
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == webSessionCookie && cookie.Value != "" {
+			return &webSession{
+				host:    token.WebServerHostname,
+				cookies: []*http.Cookie{{Name: webSessionCookie, Value: cookie.Value}},
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("MWAA login for %s did not return a session cookie", l.cfg.EnvironmentName)
+}
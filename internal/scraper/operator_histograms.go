@@ -0,0 +1,107 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// operatorDurationBounds are the histogram bucket bounds in seconds, spanning
+// quick sensors and Python callables up to multi-hour Kubernetes pods
+var operatorDurationBounds = []float64{1, 5, 10, 30, 60, 120, 300, 600, 1800, 3600, 7200, 14400}
+
+type operatorStateKey struct {
+	operator string
+	state    string
+}
+
+// durationHistogram is the finished durations of one series in a scrape
+type durationHistogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+	min    float64
+	max    float64
+}
+
+func (h *durationHistogram) observe(value float64) {
+	h.counts[sort.SearchFloat64s(operatorDurationBounds, value)]++
+	h.count++
+	h.sum += value
+	h.min = math.Min(h.min, value)
+	h.max = math.Max(h.max, value)
+}
+
+// histogramFor returns the histogram for key, adding an empty one if needed
+func histogramFor[K comparable](histograms map[K]*durationHistogram, key K) *durationHistogram {
+	h, ok := histograms[key]
+	if !ok {
+		h = &durationHistogram{
+			counts: make([]uint64, len(operatorDurationBounds)+1),
+			min:    math.Inf(1),
+			max:    math.Inf(-1),
+		}
+		histograms[key] = h
+	}
+	return h
+}
+
+// operatorHistograms aggregates finished task durations by operator class.
+// Task instances of running runs are fetched on every scrape, so finished
+// attempts are remembered to count each one only once.
+type operatorHistograms struct {
+	seen     map[taskInstanceKey]time.Time
+	current  map[operatorStateKey]*durationHistogram
+	lastEmit time.Time
+}
+
+func newOperatorHistograms() *operatorHistograms {
+	return &operatorHistograms{
+		seen:     make(map[taskInstanceKey]time.Time),
+		current:  make(map[operatorStateKey]*durationHistogram),
+		lastEmit: time.Now(),
+	}
+}
+
+// observe adds a task instance if it finished and has not been counted yet
+func (o *operatorHistograms) observe(task TaskInstance) {
+	if (task.State != "success" && task.State != "failed") || task.Duration <= 0 {
+		return
+	}
+	key := taskInstanceKey{dagID: task.DAGID, runID: task.DAGRunID, taskID: task.TaskID, mapIndex: task.MapIndex, try: task.TryNumber}
+	if _, ok := o.seen[key]; ok {
+		return
+	}
+	o.seen[key] = task.EndDate
+
+	operator := task.Operator
+	if operator == "" {
+		operator = "unknown"
+	}
+	hk := operatorStateKey{operator: operator, state: task.State}
+	histogramFor(o.current, hk).observe(task.Duration)
+}
+
+// emit records the durations observed since the previous emit as delta
+// histograms and forgets attempts that finished before retention
+func (o *operatorHistograms) emit(mb *MetricsBuilder, now time.Time, retention time.Duration) {
+	start := pcommon.NewTimestampFromTime(o.lastEmit)
+	ts := pcommon.NewTimestampFromTime(now)
+	for key, h := range o.current {
+		mb.RecordOperatorDurationHistogram(key.operator, key.state, h, start, ts)
+	}
+	o.current = make(map[operatorStateKey]*durationHistogram)
+	o.lastEmit = now
+
+	cutoff := now.Add(-retention)
+	for key, endDate := range o.seen {
+		if endDate.Before(cutoff) {
+			delete(o.seen, key)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+// OTLPEnricherConfig for the OTLP passthrough enricher
+type OTLPEnricherConfig struct {
+	InstanceName    string
+	RefreshInterval time.Duration
+}
+
+// OTLPEnricher decorates metrics pushed by Airflow's native OpenTelemetry
+// integration with context only the REST API knows about: the Airflow
+// version and the tags of the DAG a data point belongs to
+type OTLPEnricher struct {
+	cfg      *OTLPEnricherConfig
+	settings receiver.Settings
+	rest     *RESTAPIScraper
+
+	mu      sync.RWMutex
+	version string
+	dagTags map[string]string
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewOTLPEnricher creates an enricher. rest may be nil, in which case only
+// the static instance name is added.
+func NewOTLPEnricher(cfg *OTLPEnricherConfig, rest *RESTAPIScraper, settings receiver.Settings) *OTLPEnricher {
+	return &OTLPEnricher{
+		cfg:      cfg,
+		settings: settings,
+		rest:     rest,
+		dagTags:  make(map[string]string),
+	}
+}
+
+// Start loads Airflow metadata and keeps it fresh in the background
+func (e *OTLPEnricher) Start(ctx context.Context, host component.Host) error {
+	if e.rest == nil {
+		return nil
+	}
+	if err := e.rest.Start(ctx, host); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.refresh(ctx)
+
+		ticker := time.NewTicker(e.cfg.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				e.refresh(ctx)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown stops the background refresh and the REST API client
+func (e *OTLPEnricher) Shutdown(ctx context.Context) error {
+	if e.cancel != nil {
+		e.cancel()
+		e.cancel = nil
+	}
+	e.wg.Wait()
+	if e.rest == nil {
+		return nil
+	}
+	return e.rest.Shutdown(ctx)
+}
+
+func (e *OTLPEnricher) refresh(ctx context.Context) {
+	if version, err := e.rest.getVersion(ctx); err == nil {
+		e.mu.Lock()
+		e.version = version.Version
+		e.mu.Unlock()
+	} else {
+		e.settings.Logger.Warn("Failed to refresh Airflow version for OTLP enrichment", zap.Error(err))
+	}
+
+	dags, err := e.rest.getDags(ctx)
+	if err != nil {
+		e.settings.Logger.Warn("Failed to refresh DAG tags for OTLP enrichment", zap.Error(err))
+		return
+	}
+
+	dagTags := make(map[string]string, len(dags))
+	for _, dag := range dags {
+		if len(dag.Tags) == 0 {
+			continue
+		}
+		names := make([]string, len(dag.Tags))
+		for i, tag := range dag.Tags {
+			names[i] = tag.Name
+		}
+		dagTags[dag.DAGID] = strings.Join(names, ",")
+	}
+
+	e.mu.Lock()
+	e.dagTags = dagTags
+	e.mu.Unlock()
+}
+
+// Enrich adds resource attributes and DAG tags to md in place
+func (e *OTLPEnricher) Enrich(md pmetric.Metrics) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		attrs := rm.Resource().Attributes()
+		if e.cfg.InstanceName != "" {
+			attrs.PutStr("airflow.instance.name", e.cfg.InstanceName)
+		}
+		if e.version != "" {
+			if _, ok := attrs.Get("airflow.version"); !ok {
+				attrs.PutStr("airflow.version", e.version)
+			}
+		}
+
+		if len(e.dagTags) == 0 {
+			continue
+		}
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				forEachDataPointAttributes(metrics.At(k), e.addDAGTags)
+			}
+		}
+	}
+}
+
+func (e *OTLPEnricher) addDAGTags(attrs pcommon.Map) {
+	dagID, ok := attrs.Get("dag_id")
+	if !ok {
+		dagID, ok = attrs.Get("dag.id")
+	}
+	if !ok {
+		return
+	}
+	if tags, found := e.dagTags[dagID.AsString()]; found {
+		attrs.PutStr("dag.tags", tags)
+	}
+}
+
+// forEachDataPointAttributes calls fn with the attributes of every data point
+// of the metric, whatever its type
+func forEachDataPointAttributes(metric pmetric.Metric, fn func(pcommon.Map)) {
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Defaults for paged list requests, matching the limit the receiver used
+// before they were configurable
+const (
+	DefaultPageSize         = 100
+	DefaultMaxDAGRuns       = 100
+	DefaultMaxTaskInstances = 100
+)
+
+// maxListItems bounds how many pools, connections or variables one scrape
+// reads, so a runaway install cannot make a scrape unbounded
+const maxListItems = 10000
+
+// pagedResult is the items read from a paged list and the total the API
+// reported for it
+type pagedResult[T any] struct {
+	items []T
+	total int
+}
+
+// fetchPages requests path with limit and offset query parameters until
+// maxResults items were read or the list is exhausted
+func fetchPages[T any](ctx context.Context, s *RESTAPIScraper, path string, maxResults int, decode func([]byte) ([]T, int, error)) (pagedResult[T], error) {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return readPages(s, maxResults, func(limit, offset int) ([]T, int, error) {
+		body, err := s.doRequest(ctx, fmt.Sprintf("%s%slimit=%d&offset=%d", path, separator, limit, offset))
+		if err != nil {
+			return nil, 0, err
+		}
+		return decode(body)
+	})
+}
+
+// fetchConditionalPages is fetchPages for endpoints that are fetched with
+// conditional requests. Each page is validated on its own, so an unchanged
+// list costs one 304 per page.
+func fetchConditionalPages[T any](ctx context.Context, s *RESTAPIScraper, path string, maxResults int, decode func([]byte) ([]T, int, error)) (pagedResult[T], error) {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return readPages(s, maxResults, func(limit, offset int) ([]T, int, error) {
+		page, err := getConditional(ctx, s, fmt.Sprintf("%s%slimit=%d&offset=%d", path, separator, limit, offset), func(body []byte) (pagedResult[T], error) {
+			items, total, err := decode(body)
+			return pagedResult[T]{items: items, total: total}, err
+		})
+		return page.items, page.total, err
+	})
+}
+
+// recordTruncated records the items of a list that were not read because it
+// hit limit
+func (s *RESTAPIScraper) recordTruncated(signal, endpoint string, total, read, limit int) {
+	if total > read {
+		s.drops.Record(signal, DropReasonTruncated, int64(total-read),
+			zap.String("endpoint", endpoint), zap.Int("limit", limit))
+	}
+}
+
+// readPages calls fetchPage with a growing offset until maxResults items were
+// read or the list is exhausted. The webserver may cap limit at its
+// maximum_page_limit, so the offset advances by the number of items actually
+// returned.
+func readPages[T any](s *RESTAPIScraper, maxResults int, fetchPage func(limit, offset int) ([]T, int, error)) (pagedResult[T], error) {
+	pageSize := s.cfg.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	var items []T
+	total := 0
+	for len(items) < maxResults {
+		page, pageTotal, err := fetchPage(min(pageSize, maxResults-len(items)), len(items))
+		if err != nil {
+			return pagedResult[T]{}, err
+		}
+		items = append(items, page...)
+		total = max(pageTotal, len(items))
+
+		if len(page) == 0 || len(items) >= total {
+			break
+		}
+	}
+	return pagedResult[T]{items: items, total: total}, nil
+}
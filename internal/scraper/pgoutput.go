@@ -0,0 +1,189 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// pgEpoch is the origin of the replication protocol's timestamps
+var pgEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// errShortMessage is returned for a replication message that ends early
+var errShortMessage = errors.New("replication message too short")
+
+// lsn is a position in the write-ahead log
+type lsn uint64
+
+func (l lsn) String() string {
+	return fmt.Sprintf("%X/%X", uint32(l>>32), uint32(l))
+}
+
+// wireReader reads the big-endian fields of a replication message. The
+// first read past the end sets err and returns zero values from then on.
+type wireReader struct {
+	buf []byte
+	err error
+}
+
+func (r *wireReader) next(n int) []byte {
+	if r.err != nil || len(r.buf) < n {
+		r.err = errShortMessage
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *wireReader) byte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *wireReader) uint16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *wireReader) uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *wireReader) uint64() uint64 {
+	if b := r.next(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *wireReader) time() time.Time {
+	return pgEpoch.Add(time.Duration(int64(r.uint64())) * time.Microsecond)
+}
+
+// cstring reads a NUL terminated string
+func (r *wireReader) cstring() string {
+	for i, c := range r.buf {
+		if c == 0 {
+			s := string(r.buf[:i])
+			r.buf = r.buf[i+1:]
+			return s
+		}
+	}
+	r.err = errShortMessage
+	return ""
+}
+
+// Replication stream messages, wrapped in CopyData
+const (
+	msgXLogData            = 'w'
+	msgPrimaryKeepalive    = 'k'
+	msgStandbyStatusUpdate = 'r'
+)
+
+// xLogData is a chunk of decoded WAL, here one pgoutput message
+type xLogData struct {
+	walStart lsn
+	data     []byte
+}
+
+func parseXLogData(buf []byte) (xLogData, error) {
+	r := &wireReader{buf: buf}
+	msg := xLogData{walStart: lsn(r.uint64())}
+	r.uint64() // server WAL end
+	r.uint64() // send time
+	msg.data = r.buf
+	return msg, r.err
+}
+
+// primaryKeepalive reports the server's WAL position between changes
+type primaryKeepalive struct {
+	walEnd         lsn
+	replyRequested bool
+}
+
+func parsePrimaryKeepalive(buf []byte) (primaryKeepalive, error) {
+	r := &wireReader{buf: buf}
+	msg := primaryKeepalive{walEnd: lsn(r.uint64())}
+	r.uint64() // send time
+	msg.replyRequested = r.byte() == 1
+	return msg, r.err
+}
+
+// standbyStatusUpdate reports flushed as received, flushed and applied; the
+// server may then discard the slot's WAL before it
+func standbyStatusUpdate(received, flushed lsn, now time.Time) []byte {
+	buf := make([]byte, 0, 34)
+	buf = append(buf, msgStandbyStatusUpdate)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(received))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(flushed))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(flushed))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(now.Sub(pgEpoch).Microseconds()))
+	return append(buf, 0)
+}
+
+// pgoutput logical replication messages
+const (
+	pgoutputBegin    = 'B'
+	pgoutputCommit   = 'C'
+	pgoutputRelation = 'R'
+	pgoutputInsert   = 'I'
+	pgoutputUpdate   = 'U'
+)
+
+// pgoutputRelationInfo describes the columns of a replicated table. It is
+// sent before the table's first change and again when the table changes.
+type pgoutputRelationInfo struct {
+	name    string
+	columns []string
+}
+
+func parsePgoutputRelation(r *wireReader) (uint32, *pgoutputRelationInfo) {
+	id := r.uint32()
+	r.cstring() // namespace
+	rel := &pgoutputRelationInfo{name: r.cstring()}
+	r.byte() // replica identity
+	n := int(r.uint16())
+	for i := 0; i < n && r.err == nil; i++ {
+		r.byte() // flags
+		rel.columns = append(rel.columns, r.cstring())
+		r.uint32() // type OID
+		r.uint32() // type modifier
+	}
+	return id, rel
+}
+
+// tupleValue is a column of a replicated row in text format
+type tupleValue struct {
+	// kind is 'n' for NULL, 'u' for an unchanged TOASTed value and 't' for
+	// text
+	kind byte
+	text string
+}
+
+// parseTuple reads a row's columns by name
+func (rel *pgoutputRelationInfo) parseTuple(r *wireReader) map[string]tupleValue {
+	n := int(r.uint16())
+	tuple := make(map[string]tupleValue, n)
+	for i := 0; i < n && r.err == nil; i++ {
+		value := tupleValue{kind: r.byte()}
+		if value.kind == 't' {
+			value.text = string(r.next(int(r.uint32())))
+		}
+		if i < len(rel.columns) {
+			tuple[rel.columns[i]] = value
+		}
+	}
+	return tuple
+}
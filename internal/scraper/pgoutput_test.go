@@ -0,0 +1,345 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+// Messages captured from a PostgreSQL 16 pgoutput stream of Airflow's
+// task_instance table, changed at 2026-10-18 12:00:00 UTC
+const (
+	// Begin of the transaction ending at 0/16B3748, xid 771
+	capturedBegin = "4200000000016b37480003011ae7ced00000000303"
+	// Commit at 0/16B3748, ending at 0/16B3778
+	capturedCommit = "430000000000016b374800000000016b37780003011ae7ced000"
+	// Relation 16390, public.task_instance with the key columns task_id,
+	// dag_id, run_id and map_index, then state and try_number
+	capturedRelation = "52000040067075626c6963007461736b5f696e7374616e636500640006017461736b5f69640000000019ffffffff016461675f69640000000019ffffffff0172756e5f69640000000019ffffffff016d61705f696e6465780000000017ffffffff0073746174650000000413ffffffff007472795f6e756d6265720000000017ffffffff"
+	// Insert of extract/etl/scheduled__1/-1 with a NULL state
+	capturedInsert = "49000040064e0006740000000765787472616374740000000365746c740000000c7363686564756c65645f5f3174000000022d316e740000000130"
+	// Update to success without the old row
+	capturedUpdate = "55000040064e0006740000000765787472616374740000000365746c740000000c7363686564756c65645f5f3174000000022d31740000000773756363657373740000000131"
+	// Update to success with the full old row, REPLICA IDENTITY FULL, in
+	// state running
+	capturedUpdateOld = "55000040064f0006740000000765787472616374740000000365746c740000000c7363686564756c65645f5f3174000000022d31740000000772756e6e696e677400000001314e0006740000000765787472616374740000000365746c740000000c7363686564756c65645f5f3174000000022d31740000000773756363657373740000000131"
+	// Update to success with the old key columns only, after the key changed
+	// under the default replica identity: state and try_number are NULL
+	capturedUpdateKey = "55000040064b0006740000000765787472616374740000000365746c740000000c7363686564756c65645f5f3174000000022d316e6e4e0006740000000765787472616374740000000365746c740000000c7363686564756c65645f5f3174000000022d31740000000773756363657373740000000131"
+)
+
+// capturedTime is the send and commit time of the captured messages
+var capturedTime = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestParseXLogData(t *testing.T) {
+	tests := []struct {
+		name      string
+		msg       string
+		wantStart lsn
+		wantData  string
+		wantErr   error
+	}{
+		{
+			name:      "begin",
+			msg:       "00000000016b374800000000016b37780003011ae7ced000" + capturedBegin,
+			wantStart: 0x16B3748,
+			wantData:  capturedBegin,
+		},
+		{
+			name:      "empty",
+			msg:       "00000000016b374800000000016b37780003011ae7ced000",
+			wantStart: 0x16B3748,
+		},
+		{
+			name:    "short",
+			msg:     "00000000016b374800000000016b3778",
+			wantErr: errShortMessage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := parseXLogData(decodeHex(t, tt.msg))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if msg.walStart != tt.wantStart {
+				t.Errorf("walStart = %s, want %s", msg.walStart, tt.wantStart)
+			}
+			if got := hex.EncodeToString(msg.data); got != tt.wantData {
+				t.Errorf("data = %s, want %s", got, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestParsePrimaryKeepalive(t *testing.T) {
+	tests := []struct {
+		name      string
+		msg       string
+		wantEnd   lsn
+		wantReply bool
+		wantErr   error
+	}{
+		{
+			name:      "reply requested",
+			msg:       "00000000016b37780003011ae7ced00001",
+			wantEnd:   0x16B3778,
+			wantReply: true,
+		},
+		{
+			name:    "no reply",
+			msg:     "00000000016b37780003011ae7ced00000",
+			wantEnd: 0x16B3778,
+		},
+		{
+			name:    "short",
+			msg:     "00000000016b37780003011ae7ced000",
+			wantErr: errShortMessage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := parsePrimaryKeepalive(decodeHex(t, tt.msg))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if msg.walEnd != tt.wantEnd || msg.replyRequested != tt.wantReply {
+				t.Errorf("got %s/%t, want %s/%t", msg.walEnd, msg.replyRequested, tt.wantEnd, tt.wantReply)
+			}
+		})
+	}
+}
+
+func TestStandbyStatusUpdate(t *testing.T) {
+	got := standbyStatusUpdate(0x16B3778, 0x16B3748, capturedTime)
+	want := decodeHex(t, "7200000000016b377800000000016b374800000000016b37480003011ae7ced00000")
+	if !bytes.Equal(got, want) {
+		t.Errorf("standbyStatusUpdate = %x, want %x", got, want)
+	}
+}
+
+func TestParsePgoutputRelation(t *testing.T) {
+	data := decodeHex(t, capturedRelation)
+	r := &wireReader{buf: data[1:]}
+	id, rel := parsePgoutputRelation(r)
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if id != 16390 || rel.name != "task_instance" {
+		t.Errorf("relation = %d %s, want 16390 task_instance", id, rel.name)
+	}
+	wantColumns := []string{"task_id", "dag_id", "run_id", "map_index", "state", "try_number"}
+	if !slices.Equal(rel.columns, wantColumns) {
+		t.Errorf("columns = %v, want %v", rel.columns, wantColumns)
+	}
+
+	r = &wireReader{buf: data[1 : len(data)-4]}
+	parsePgoutputRelation(r)
+	if !errors.Is(r.err, errShortMessage) {
+		t.Errorf("truncated relation err = %v, want %v", r.err, errShortMessage)
+	}
+}
+
+func TestParseTuple(t *testing.T) {
+	rel := &pgoutputRelationInfo{
+		name:    "task_instance",
+		columns: []string{"task_id", "dag_id", "run_id", "map_index", "state", "try_number"},
+	}
+	tests := []struct {
+		name    string
+		tuple   string
+		want    map[string]tupleValue
+		wantErr error
+	}{
+		{
+			name:  "new row",
+			tuple: capturedUpdate[12:],
+			want: map[string]tupleValue{
+				"task_id":    {kind: 't', text: "extract"},
+				"dag_id":     {kind: 't', text: "etl"},
+				"run_id":     {kind: 't', text: "scheduled__1"},
+				"map_index":  {kind: 't', text: "-1"},
+				"state":      {kind: 't', text: "success"},
+				"try_number": {kind: 't', text: "1"},
+			},
+		},
+		{
+			name:  "key columns only",
+			tuple: capturedUpdateKey[12:108],
+			want: map[string]tupleValue{
+				"task_id":    {kind: 't', text: "extract"},
+				"dag_id":     {kind: 't', text: "etl"},
+				"run_id":     {kind: 't', text: "scheduled__1"},
+				"map_index":  {kind: 't', text: "-1"},
+				"state":      {kind: 'n'},
+				"try_number": {kind: 'n'},
+			},
+		},
+		{
+			name:  "unchanged TOASTed value",
+			tuple: "000274000000076578747261637475",
+			want: map[string]tupleValue{
+				"task_id": {kind: 't', text: "extract"},
+				"dag_id":  {kind: 'u'},
+			},
+		},
+		{
+			name:    "short",
+			tuple:   "0001740000000765787472",
+			wantErr: errShortMessage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &wireReader{buf: decodeHex(t, tt.tuple)}
+			got := rel.parseTuple(r)
+			if !errors.Is(r.err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", r.err, tt.wantErr)
+			}
+			if r.err != nil {
+				return
+			}
+			if len(r.buf) != 0 {
+				t.Errorf("%d bytes left over", len(r.buf))
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("got %d columns, want %d", len(got), len(tt.want))
+			}
+			for column, want := range tt.want {
+				if got[column] != want {
+					t.Errorf("%s = %+v, want %+v", column, got[column], want)
+				}
+			}
+		})
+	}
+}
+
+func TestStateCDCListenerDecode(t *testing.T) {
+	key := stateRowKey{table: "task_instance", dagID: "etl", runID: "scheduled__1", taskID: "extract", mapIndex: -1}
+	tests := []struct {
+		name string
+		// last is the last state seen for the row, if any
+		last         *string
+		change       string
+		wantChange   bool
+		wantState    string
+		wantPrevious string
+	}{
+		{
+			name:       "insert",
+			change:     capturedInsert,
+			wantChange: true,
+		},
+		{
+			name:         "update without old row",
+			last:         ptr("running"),
+			change:       capturedUpdate,
+			wantChange:   true,
+			wantState:    "success",
+			wantPrevious: "running",
+		},
+		{
+			name:   "update without old row, state unchanged",
+			last:   ptr("success"),
+			change: capturedUpdate,
+		},
+		{
+			name:         "full old row",
+			last:         ptr("queued"),
+			change:       capturedUpdateOld,
+			wantChange:   true,
+			wantState:    "success",
+			wantPrevious: "running",
+		},
+		{
+			name:         "key-only old row",
+			last:         ptr("running"),
+			change:       capturedUpdateKey,
+			wantChange:   true,
+			wantState:    "success",
+			wantPrevious: "running",
+		},
+		{
+			name:   "key-only old row, state unchanged",
+			last:   ptr("success"),
+			change: capturedUpdateKey,
+		},
+		{
+			name:       "key-only old row, state unknown",
+			change:     capturedUpdateKey,
+			wantChange: true,
+			wantState:  "success",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewStateCDCListener(&StateCDCConfig{}, receiver.Settings{TelemetrySettings: component.TelemetrySettings{Logger: zap.NewNop()}})
+			l.relations = make(map[uint32]*pgoutputRelationInfo)
+			if tt.last != nil {
+				l.states[key] = *tt.last
+			}
+			for _, msg := range []string{capturedRelation, capturedBegin, tt.change, capturedCommit} {
+				if err := l.decode(decodeHex(t, msg)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if l.readLSN != 0x16B3778 {
+				t.Errorf("readLSN = %s, want 0/16B3778", l.readLSN)
+			}
+			if !tt.wantChange {
+				if len(l.buffer) != 0 {
+					t.Errorf("got %d changes, want none: %+v", len(l.buffer), l.buffer)
+				}
+				return
+			}
+			if len(l.buffer) != 1 {
+				t.Fatalf("got %d changes, want 1", len(l.buffer))
+			}
+			change := l.buffer[0]
+			if change.Table != "task_instance" || change.DAGID != "etl" || change.RunID != "scheduled__1" || change.TaskID != "extract" {
+				t.Errorf("row = %s %s %s %s", change.Table, change.DAGID, change.RunID, change.TaskID)
+			}
+			if change.MapIndex == nil || *change.MapIndex != -1 {
+				t.Errorf("MapIndex = %v, want -1", change.MapIndex)
+			}
+			if change.State != tt.wantState || change.PreviousState != tt.wantPrevious {
+				t.Errorf("state = %q from %q, want %q from %q", change.State, change.PreviousState, tt.wantState, tt.wantPrevious)
+			}
+			if !change.ChangedAt.Equal(capturedTime) {
+				t.Errorf("ChangedAt = %s, want %s", change.ChangedAt, capturedTime)
+			}
+			if got := l.states[key]; got != tt.wantState {
+				t.Errorf("last state = %q, want %q", got, tt.wantState)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,59 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"time"
+)
+
+// poolStarvation is the starvation history of one pool
+type poolStarvation struct {
+	start    time.Time
+	lastSeen time.Time
+	starved  bool
+	seconds  float64
+}
+
+// poolStarvationTracker accumulates how long each pool has had no open slots
+// while tasks were queued for it. Starvation is sampled once per scrape, so
+// the interval after a starved sample is counted as starved.
+type poolStarvationTracker struct {
+	pools map[string]*poolStarvation
+}
+
+func newPoolStarvationTracker() *poolStarvationTracker {
+	return &poolStarvationTracker{pools: make(map[string]*poolStarvation)}
+}
+
+// isStarved reports whether queued work is waiting on a pool with no free slots
+func isStarved(pool Pool) bool {
+	return pool.OpenSlots == 0 && pool.QueuedSlots > 0
+}
+
+// observe records the pool's current state and returns its history
+func (t *poolStarvationTracker) observe(pool Pool, now time.Time) *poolStarvation {
+	state, ok := t.pools[pool.Name]
+	if !ok {
+		state = &poolStarvation{start: now}
+		t.pools[pool.Name] = state
+	} else if state.starved {
+		state.seconds += now.Sub(state.lastSeen).Seconds()
+	}
+	state.starved = isStarved(pool)
+	state.lastSeen = now
+	return state
+}
+
+// prune forgets pools that no longer exist
+func (t *poolStarvationTracker) prune(pools []Pool) {
+	current := make(map[string]bool, len(pools))
+	for _, pool := range pools {
+		current[pool.Name] = true
+	}
+	for name := range t.pools {
+		if !current[name] {
+			delete(t.pools, name)
+		}
+	}
+}
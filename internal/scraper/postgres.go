@@ -0,0 +1,168 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// Postgres authentication modes
+const (
+	DBAuthModePassword = "password"
+	DBAuthModeRDSIAM   = "rds_iam"
+)
+
+// DefaultApplicationName identifies receiver sessions in pg_stat_activity
+const DefaultApplicationName = "otel-airflowreceiver"
+
+// PostgresConnConfig holds the connection settings shared by the database
+// and log scrapers
+type PostgresConnConfig struct {
+	Host        string
+	Port        int
+	Database    string
+	Username    string
+	Password    string
+	SSLMode     string
+	SSLRootCert string
+	SSLCert     string
+	SSLKey      string
+	// StatementTimeout is enforced server side when set
+	StatementTimeout time.Duration
+	// ApplicationName is reported to the server, DefaultApplicationName when empty
+	ApplicationName string
+	// Hosts replaces Host and Port with a list of host:port candidates, of
+	// which TargetSessionAttrs selects the first acceptable one
+	Hosts              []string
+	TargetSessionAttrs string
+	// RDSIAM authenticates with RDS IAM auth tokens instead of Password. The
+	// token is signed for the single host the connection string resolves to.
+	RDSIAM *RDSIAMConfig
+}
+
+// ConnString renders the settings as a libpq keyword/value connection string
+func (c PostgresConnConfig) ConnString() string {
+	hosts, ports := c.Host, strconv.Itoa(c.Port)
+	if len(c.Hosts) > 0 {
+		hostList := make([]string, 0, len(c.Hosts))
+		portList := make([]string, 0, len(c.Hosts))
+		for _, hostPort := range c.Hosts {
+			host, port, err := net.SplitHostPort(hostPort)
+			if err != nil {
+				host, port = hostPort, strconv.Itoa(c.Port)
+			}
+			hostList = append(hostList, host)
+			portList = append(portList, port)
+		}
+		hosts, ports = strings.Join(hostList, ","), strings.Join(portList, ",")
+	}
+	params := []string{
+		"host=" + quoteConnValue(hosts),
+		"port=" + quoteConnValue(ports),
+		"user=" + quoteConnValue(c.Username),
+		"password=" + quoteConnValue(c.Password),
+		"dbname=" + quoteConnValue(c.Database),
+		"sslmode=" + quoteConnValue(c.SSLMode),
+	}
+	if c.SSLRootCert != "" {
+		params = append(params, "sslrootcert="+quoteConnValue(c.SSLRootCert))
+	}
+	if c.SSLCert != "" {
+		params = append(params, "sslcert="+quoteConnValue(c.SSLCert))
+	}
+	if c.SSLKey != "" {
+		params = append(params, "sslkey="+quoteConnValue(c.SSLKey))
+	}
+	if c.TargetSessionAttrs != "" {
+		params = append(params, "target_session_attrs="+quoteConnValue(c.TargetSessionAttrs))
+	}
+	return strings.Join(params, " ")
+}
+
+// quoteConnValue quotes a value so spaces and quotes in passwords or file
+// paths survive connection string parsing
+func quoteConnValue(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `'`, `\'`)
+	return "'" + v + "'"
+}
+
+// OpenDB opens a pgx-backed database handle. Prepared statements are cached
+// per connection only when cacheStatements is set, since the cache does not
+// survive transaction-pooling proxies such as PgBouncer.
+func (c PostgresConnConfig) OpenDB(cacheStatements bool) (*sql.DB, error) {
+	connConfig, err := c.parseConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.StatementTimeout > 0 {
+		connConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10)
+	}
+
+	if cacheStatements {
+		connConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	} else {
+		connConfig.DefaultQueryExecMode = pgx.QueryExecModeExec
+	}
+
+	var opts []stdlib.OptionOpenDB
+	if c.RDSIAM != nil {
+		tokens, err := newRDSTokenSource(context.Background(), connConfig.Host, int(connConfig.Port), connConfig.User, *c.RDSIAM)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, stdlib.OptionBeforeConnect(tokens.beforeConnect))
+	}
+
+	return stdlib.OpenDB(*connConfig, opts...), nil
+}
+
+// ConnectReplication opens a logical replication session, which accepts
+// replication commands such as START_REPLICATION besides simple queries.
+// The role needs the REPLICATION attribute, or rds_replication on RDS.
+func (c PostgresConnConfig) ConnectReplication(ctx context.Context) (*pgconn.PgConn, error) {
+	connConfig, err := c.parseConfig()
+	if err != nil {
+		return nil, err
+	}
+	connConfig.RuntimeParams["replication"] = "database"
+
+	if c.RDSIAM != nil {
+		tokens, err := newRDSTokenSource(ctx, connConfig.Host, int(connConfig.Port), connConfig.User, *c.RDSIAM)
+		if err != nil {
+			return nil, err
+		}
+		if err := tokens.beforeConnect(ctx, connConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	return pgconn.ConnectConfig(ctx, &connConfig.Config)
+}
+
+// parseConfig parses the connection string and names the session
+func (c PostgresConnConfig) parseConfig() (*pgx.ConnConfig, error) {
+	connConfig, err := pgx.ParseConfig(c.ConnString())
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection settings: %w", err)
+	}
+
+	applicationName := c.ApplicationName
+	if applicationName == "" {
+		applicationName = DefaultApplicationName
+	}
+	connConfig.RuntimeParams["application_name"] = applicationName
+	return connConfig, nil
+}
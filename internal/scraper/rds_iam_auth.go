@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// RDS accepts an auth token for 15 minutes after it is signed
+	rdsTokenExpiry = 15 * time.Minute
+	// New connections get a fresh token well before that
+	rdsTokenRefresh = 10 * time.Minute
+)
+
+// RDSIAMConfig replaces the static password with RDS IAM auth tokens
+type RDSIAMConfig struct {
+	Region string
+	// RoleARN is assumed before signing tokens when set
+	RoleARN string
+}
+
+// rdsTokenSource signs RDS IAM auth tokens for one database user and endpoint
+// and reuses each token until it is due for refresh. Tokens only matter when
+// a connection is opened, so pooled connections outlive them.
+type rdsTokenSource struct {
+	endpoint    string
+	user        string
+	region      string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func newRDSTokenSource(ctx context.Context, host string, port int, user string, cfg RDSIAMConfig) (*rdsTokenSource, error) {
+	credentials, err := loadAWSCredentials(ctx, cfg.Region, cfg.RoleARN)
+	if err != nil {
+		return nil, err
+	}
+	return &rdsTokenSource{
+		endpoint:    net.JoinHostPort(host, strconv.Itoa(port)),
+		user:        user,
+		region:      cfg.Region,
+		credentials: credentials,
+		signer:      v4.NewSigner(),
+	}, nil
+}
+
+// beforeConnect sets the connection's password to a current token
+func (t *rdsTokenSource) beforeConnect(ctx context.Context, connConfig *pgx.ConnConfig) error {
+	token, err := t.current(ctx)
+	if err != nil {
+		return err
+	}
+	connConfig.Password = token
+	return nil
+}
+
+func (t *rdsTokenSource) current(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if t.token != "" && now.Before(t.expires) {
+		return t.token, nil
+	}
+	token, err := t.sign(ctx, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to create RDS IAM auth token: %w", err)
+	}
+	t.token = token
+	t.expires = now.Add(rdsTokenRefresh)
+	return token, nil
+}
+
+// sign presigns an rds-db:connect request; the token is the presigned URL
+// without its scheme
+func (t *rdsTokenSource) sign(ctx context.Context, now time.Time) (string, error) {
+	query := url.Values{
+		"Action":        {"connect"},
+		"DBUser":        {t.user},
+		"X-Amz-Expires": {strconv.Itoa(int(rdsTokenExpiry.Seconds()))},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+t.endpoint+"/?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	creds, err := t.credentials.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+	signed, _, err := t.signer.PresignHTTP(ctx, creds, req, emptyPayloadHash, "rds-db", t.region, now)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(signed, "https://"), nil
+}
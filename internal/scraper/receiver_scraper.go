@@ -0,0 +1,32 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver"
+)
+
+// ReceiverScraper emits telemetry about the receiver itself rather than
+// about Airflow, such as the data it intentionally dropped
+type ReceiverScraper struct {
+	settings receiver.Settings
+	drops    *DropTracker
+}
+
+func NewReceiverScraper(drops *DropTracker, settings receiver.Settings) *ReceiverScraper {
+	return &ReceiverScraper{
+		settings: settings,
+		drops:    drops,
+	}
+}
+
+func (s *ReceiverScraper) Scrape(ctx context.Context) (pmetric.Metrics, error) {
+	mb := NewMetricsBuilder(s.settings)
+	s.drops.EmitMetrics(mb, time.Now())
+	return mb.Emit(), nil
+}
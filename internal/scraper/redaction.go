@@ -0,0 +1,198 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// Ways of redacting an attribute value
+const (
+	// RedactionHash replaces the value with a hex digest that still groups
+	// equal values together
+	RedactionHash = "hash"
+	// RedactionTruncate keeps the first Length characters
+	RedactionTruncate = "truncate"
+	// RedactionDrop removes the attribute
+	RedactionDrop = "drop"
+)
+
+// redactedHashLength is the number of hex characters kept of a digest
+const redactedHashLength = 16
+
+// RedactionRule is how the values of one attribute key are redacted
+type RedactionRule struct {
+	Action string
+	// Length is the number of characters truncate keeps
+	Length int
+}
+
+// Redactor rewrites sensitive attribute values before emission. A nil
+// Redactor leaves data unchanged.
+type Redactor struct {
+	salt  []byte
+	rules map[string]RedactionRule
+}
+
+// NewRedactor returns nil when there are no rules. Hashes are HMAC-SHA256
+// keyed with salt, or plain SHA-256 without one.
+func NewRedactor(salt string, rules map[string]RedactionRule) *Redactor {
+	if len(rules) == 0 {
+		return nil
+	}
+	return &Redactor{salt: []byte(salt), rules: rules}
+}
+
+// redactMetrics rewrites the attribute values of every data point. Keys
+// with the drop action are left in place: the metrics builder removes them
+// together with the disabled attributes, merging the points they told apart.
+func (r *Redactor) redactMetrics(metrics pmetric.Metrics) {
+	if r == nil {
+		return
+	}
+	rms := metrics.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			ms := sms.At(j).Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				m := ms.At(k)
+				switch m.Type() {
+				case pmetric.MetricTypeGauge:
+					dps := m.Gauge().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						r.rewrite(dps.At(l).Attributes())
+					}
+				case pmetric.MetricTypeSum:
+					dps := m.Sum().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						r.rewrite(dps.At(l).Attributes())
+					}
+				case pmetric.MetricTypeHistogram:
+					dps := m.Histogram().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						r.rewrite(dps.At(l).Attributes())
+					}
+				case pmetric.MetricTypeExponentialHistogram:
+					dps := m.ExponentialHistogram().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						r.rewrite(dps.At(l).Attributes())
+					}
+				}
+			}
+		}
+	}
+}
+
+// redactLogs rewrites the attributes of every record, and the top-level
+// fields of map bodies so structured event logs do not repeat the value
+func (r *Redactor) redactLogs(logs plog.Logs) {
+	if r == nil {
+		return
+	}
+	rls := logs.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			records := sls.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				lr := records.At(k)
+				r.redact(lr.Attributes())
+				if lr.Body().Type() == pcommon.ValueTypeMap {
+					r.redact(lr.Body().Map())
+				}
+			}
+		}
+	}
+}
+
+func (r *Redactor) redact(attrs pcommon.Map) {
+	for key, rule := range r.rules {
+		if rule.Action == RedactionDrop {
+			attrs.Remove(key)
+		}
+	}
+	r.rewrite(attrs)
+}
+
+// rewrite hashes and truncates values, leaving the keys to drop alone
+func (r *Redactor) rewrite(attrs pcommon.Map) {
+	for key, rule := range r.rules {
+		v, ok := attrs.Get(key)
+		if !ok || rule.Action == RedactionDrop {
+			continue
+		}
+		switch v.Type() {
+		case pcommon.ValueTypeStr:
+			v.SetStr(r.redactValue(rule, v.Str()))
+		case pcommon.ValueTypeSlice:
+			// Lists such as dag.owners are redacted element by element
+			s := v.Slice()
+			for i := 0; i < s.Len(); i++ {
+				if s.At(i).Type() == pcommon.ValueTypeStr {
+					s.At(i).SetStr(r.redactValue(rule, s.At(i).Str()))
+				}
+			}
+		}
+	}
+}
+
+// droppedKeys returns the keys with the drop action
+func (r *Redactor) droppedKeys() []string {
+	if r == nil {
+		return nil
+	}
+	var keys []string
+	for key, rule := range r.rules {
+		if rule.Action == RedactionDrop {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// truncates reports whether a rule truncates, which can give points that
+// differed in a value the same attributes
+func (r *Redactor) truncates() bool {
+	if r == nil {
+		return false
+	}
+	for _, rule := range r.rules {
+		if rule.Action == RedactionTruncate {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) redactValue(rule RedactionRule, value string) string {
+	if value == "" {
+		return value
+	}
+	switch rule.Action {
+	case RedactionHash:
+		var sum []byte
+		if len(r.salt) > 0 {
+			mac := hmac.New(sha256.New, r.salt)
+			mac.Write([]byte(value))
+			sum = mac.Sum(nil)
+		} else {
+			digest := sha256.Sum256([]byte(value))
+			sum = digest[:]
+		}
+		return hex.EncodeToString(sum)[:redactedHashLength]
+	case RedactionTruncate:
+		runes := []rune(value)
+		if len(runes) > rule.Length {
+			return string(runes[:rule.Length])
+		}
+	}
+	return value
+}
@@ -0,0 +1,189 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+const (
+	eventLogsPageSize = 100
+	// maxEventLogsPerScrape matches the row limit of the database log scraper
+	maxEventLogsPerScrape = 1000
+)
+
+// RESTEventLogScraper reads the audit log from /api/v1/eventLogs for
+// deployments without metadata database access. Pages are read newest first
+// until the last seen event_log_id, which works on every Airflow 2.x version.
+type RESTEventLogScraper struct {
+	rest        *RESTAPIScraper
+	settings    receiver.Settings
+	drops       *DropTracker
+	eventFilter EventFilter
+	bodyFormat  string
+	lastID      int64
+	// pendingID is the newest entry of the previous scrape, committed once
+	// its records are consumed
+	pendingID int64
+	// pendingFiltered are the entries of the previous scrape the event
+	// filter excluded, counted as drops once committed
+	pendingFiltered int
+	// start is where reading begins; positioned is set once lastID reflects it
+	start      LogStart
+	positioned bool
+}
+
+func NewRESTEventLogScraper(rest *RESTAPIScraper, settings receiver.Settings, drops *DropTracker, filter EventFilter, bodyFormat string, start LogStart) *RESTEventLogScraper {
+	return &RESTEventLogScraper{
+		rest:        rest,
+		settings:    settings,
+		drops:       drops,
+		eventFilter: filter,
+		bodyFormat:  bodyFormat,
+		start:       start,
+	}
+}
+
+func (s *RESTEventLogScraper) Start(ctx context.Context, host component.Host) error {
+	if err := s.rest.Start(ctx, host); err != nil {
+		return err
+	}
+	if s.positioned {
+		return nil
+	}
+	
+	// Starting at the latest entry skips everything recorded before startup.
+	// A start time is applied while paging instead.
+	if s.start.Position == LogStartLatest && s.start.Time.IsZero() {
+		page, _, err := s.getEventLogs(ctx, 0)
+		if err != nil {
+			return fmt.Errorf("failed to find the latest event log: %w", err)
+		}
+		if len(page) > 0 {
+			s.lastID = page[0].EventLogID
+			s.pendingID = s.lastID
+		}
+	}
+	s.positioned = true
+	return nil
+}
+
+func (s *RESTEventLogScraper) Scrape(ctx context.Context) (plog.Logs, error) {
+	// Resource attributes come from the shared deployment metadata
+	s.rest.LoadInfo(ctx)
+
+	lb := NewRESTEventLogsBuilder(s.settings)
+	lb.SetRedactor(s.rest.cfg.Redactor)
+
+	// Entries are read again until a scrape's records are committed
+	s.pendingID, s.pendingFiltered = s.lastID, 0
+	entries, err := s.fetchNewEntries(ctx)
+	if err != nil {
+		return lb.Emit(), err
+	}
+
+	// Entries arrive newest first; emit them in the order they happened
+	filtered := 0
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.EventLogID > s.pendingID {
+			s.pendingID = entry.EventLogID
+		}
+		if !s.eventFilter.Allows(entry.Event) {
+			filtered++
+			continue
+		}
+		s.record(lb, entry)
+	}
+	s.pendingFiltered = filtered
+
+	s.settings.Logger.Debug("Scraped event logs from REST API",
+		zap.Int("count", len(entries)-filtered),
+		zap.Int("filtered", filtered),
+		zap.Int64("last_log_id", s.pendingID))
+
+	return lb.Emit(), nil
+}
+
+// Commit moves past the entries of the last scrape
+func (s *RESTEventLogScraper) Commit() {
+	s.lastID = s.pendingID
+	s.drops.Record(SignalLogs, DropReasonFiltered, int64(s.pendingFiltered), zap.String("source", "rest_event_logs"))
+	s.pendingFiltered = 0
+}
+
+// fetchNewEntries pages through the audit log until it reaches an entry that
+// was already emitted
+func (s *RESTEventLogScraper) fetchNewEntries(ctx context.Context) ([]EventLog, error) {
+	var entries []EventLog
+	for offset := 0; offset < maxEventLogsPerScrape; offset += eventLogsPageSize {
+		page, total, err := s.getEventLogs(ctx, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get event logs: %w", err)
+		}
+
+		for _, entry := range page {
+			if entry.EventLogID <= s.lastID || entry.When.Before(s.start.Time) {
+				return entries, nil
+			}
+			entries = append(entries, entry)
+		}
+
+		if len(page) < eventLogsPageSize || offset+len(page) >= total {
+			return entries, nil
+		}
+	}
+
+	// Older unseen entries are skipped. The first scrape only backfills the
+	// newest entries, so that is not counted as a drop.
+	if s.lastID > 0 && len(entries) > 0 {
+		if skipped := entries[len(entries)-1].EventLogID - s.lastID - 1; skipped > 0 {
+			s.drops.Record(SignalLogs, DropReasonTruncated, skipped,
+				zap.Int("limit", maxEventLogsPerScrape))
+		}
+	}
+	return entries, nil
+}
+
+func (s *RESTEventLogScraper) getEventLogs(ctx context.Context, offset int) ([]EventLog, int, error) {
+	path := fmt.Sprintf("/api/v1/eventLogs?order_by=-event_log_id&limit=%d&offset=%d", eventLogsPageSize, offset)
+	body, err := s.rest.doRequest(ctx, path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var response EventLogsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, 0, err
+	}
+
+	return response.EventLogs, response.TotalEntries, nil
+}
+
+func (s *RESTEventLogScraper) record(lb *LogsBuilder, entry EventLog) {
+	var executionDate time.Time
+	if entry.ExecutionDate != nil {
+		executionDate = *entry.ExecutionDate
+	}
+	extra := sql.NullString{String: entry.Extra, Valid: entry.Extra != ""}
+
+	if s.bodyFormat == LogBodyStructured {
+		lb.RecordStructuredEventLog(entry.EventLogID, entry.When, entry.DAGID, entry.TaskID, entry.Event, entry.Owner, executionDate, parseExtra(extra))
+		return
+	}
+	lb.RecordEventLog(entry.EventLogID, entry.When, entry.DAGID, entry.TaskID, entry.Event, entry.Owner, executionDate, parseExtraStrings(extra))
+}
+
+func (s *RESTEventLogScraper) Shutdown(ctx context.Context) error {
+	return s.rest.Shutdown(ctx)
+}
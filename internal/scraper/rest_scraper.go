@@ -4,19 +4,80 @@
 package scraper
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/receiver"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
+// Ways of authenticating to the webserver
+const (
+	AuthModeBasic = "basic"
+	AuthModeMWAA  = "mwaa"
+	AuthModeAstro = "astro"
+	// AuthModeSession logs in through the webserver's /login form
+	AuthModeSession = "session"
+)
+
+// API groups that can be selected with RESTAPIConfig.Endpoints
+const (
+	EndpointHealth        = "health"
+	EndpointDAGs          = "dags"
+	EndpointDAGRuns       = "dag_runs"
+	EndpointTaskInstances = "task_instances"
+	EndpointPools         = "pools"
+	EndpointConnections   = "connections"
+	EndpointVariables     = "variables"
+	EndpointImportErrors  = "import_errors"
+	EndpointDatasets      = "datasets"
+	EndpointProviders     = "providers"
+	EndpointPlugins       = "plugins"
+	EndpointDAGWarnings   = "dag_warnings"
+)
+
+// AllEndpoints returns every API group the REST scraper knows how to collect
+func AllEndpoints() []string {
+	return []string{
+		EndpointHealth,
+		EndpointDAGs,
+		EndpointDAGRuns,
+		EndpointTaskInstances,
+		EndpointPools,
+		EndpointConnections,
+		EndpointVariables,
+		EndpointImportErrors,
+		EndpointDatasets,
+		EndpointProviders,
+		EndpointPlugins,
+		EndpointDAGWarnings,
+	}
+}
+
+// IsKnownEndpoint reports whether name is a valid API group
+func IsKnownEndpoint(name string) bool {
+	for _, endpoint := range AllEndpoints() {
+		if endpoint == name {
+			return true
+		}
+	}
+	return false
+}
+
 type RESTAPIScraper struct {
 	cfg         *RESTAPIConfig
 	settings    receiver.Settings
@@ -24,150 +85,570 @@ type RESTAPIScraper struct {
 	mb          *MetricsBuilder
 	retryConfig RetryConfig
 	health      *ScraperHealth
+	drops       *DropTracker
+	endpoints   map[string]bool
+	
+	// DAG inventory cache, refreshed after DAGCacheTTL
+	dagCacheMu      sync.Mutex
+	dagCache        []DAG
+	dagCacheExpires time.Time
+	
+	// Validated responses of /dags, /pools, /connections, /providers and
+	// /plugins
+	conditional *conditionalCache
+	
+	// Per-DAG run state for incremental scraping, keyed by DAG ID
+	runTrackers map[string]*dagRunTracker
+	
+	// Run counts by state from /dagStats at the last successful fetch of
+	// each DAG's runs, keyed by DAG ID
+	runStats map[string]map[string]int64
+	
+	// Task definitions for task metadata, keyed by DAG ID
+	taskCache map[string]taskDefinitions
+	
+	// Optional endpoints found missing on this webserver
+	caps *apiCapabilities
+	
+	// Time each pool has spent starved across scrapes
+	starvation *poolStarvationTracker
+	
+	// Finished task durations by operator, emitted once per scrape
+	operatorDurations *operatorHistograms
+	
+	// Per-DAG duration histograms, used instead of per-run and per-task
+	// instance points in aggregated output mode
+	dagAggregates *dagAggregates
 }
 
 type RESTAPIConfig struct {
+	ClientConfig       confighttp.ClientConfig
+	Name               string
 	Endpoint           string
 	Username           string
 	Password           string
 	CollectionInterval time.Duration
 	IncludePastRuns    bool
 	PastRunsLookback   time.Duration
+	Endpoints          []string
+	Concurrency        int
+	ScrapeTimeout      time.Duration
+	DAGCacheTTL        time.Duration
+	IncrementalRuns    bool
+	TaskMetadata       bool
+	// Limiter is shared by every scraper talking to the same webserver
+	Limiter *rate.Limiter
+	// Status combines the health of every scraper in the receiver
+	Status *StatusReporter
+	// StrictStartup fails Start when the API is unreachable or rejects the credentials
+	StrictStartup bool
+	// SummaryLogInterval logs a summary of scrape results periodically when set
+	SummaryLogInterval time.Duration
+	// ExcludeMetrics are not emitted, for metric families the database owns
+	ExcludeMetrics []string
+	// DisabledAttributes are dropped from every data point
+	DisabledAttributes []string
+	// Redactor rewrites sensitive attribute values of metrics and logs
+	Redactor *Redactor
+	// ResourcePerDAG reports each DAG on its own resource
+	ResourcePerDAG bool
+	// Info is filled with the version and executor on the first scrape
+	Info *AirflowInfo
+	// MWAA replaces basic auth with IAM-issued webserver sessions when set
+	MWAA *MWAAConfig
+	// BearerToken replaces basic auth with an Authorization: Bearer header
+	BearerToken string
+	// SessionLogin replaces basic auth with a session from the /login form
+	SessionLogin bool
+	// MappedTaskRawMaxFanOut keeps per-map-index duration points for mapped
+	// tasks with at most this many instances in a run
+	MappedTaskRawMaxFanOut int
+	// WorkerHostname adds the worker hostname to task instance durations and
+	// reports task instances per worker
+	WorkerHostname bool
+	// OutputMode is OutputModeDetailed or OutputModeAggregated
+	OutputMode string
+	// DisableResponseCompression requests uncompressed responses
+	DisableResponseCompression bool
+	// PageSize is the limit of each paged list request
+	PageSize int
+	// MaxDAGRuns caps the runs read per DAG and scrape
+	MaxDAGRuns int
+	// MaxTaskInstances caps the task instances read per run and scrape
+	MaxTaskInstances int
+	// ConfigOptions are "section.key" options read from /config and attached
+	// as airflow.config.<section>.<key> resource attributes
+	ConfigOptions []string
+	// BatchDAGRuns lists the runs of all DAGs with POST /dags/~/dagRuns/list
+	// instead of one GET per DAG
+	BatchDAGRuns bool
+	// BatchTaskInstances lists the task instances of all runs with one
+	// paginated request instead of one GET per run
+	BatchTaskInstances bool
+	// DisableFieldProjection requests full objects instead of only the
+	// fields the scraper decodes
+	DisableFieldProjection bool
+	// OnlyActiveDAGs and ExcludePausedDAGs filter the DAGs that are scraped
+	OnlyActiveDAGs    bool
+	ExcludePausedDAGs bool
+}
+
+// NewRateLimiter returns a token bucket for REST API requests, or nil when
+// requestsPerSecond is zero
+func NewRateLimiter(requestsPerSecond float64, burst int) *rate.Limiter {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
 }
 
-func NewRESTAPIScraper(cfg *RESTAPIConfig, settings receiver.Settings) *RESTAPIScraper {
+// HasEndpoint reports whether the API group is selected, treating an empty
+// selection as all groups
+func (c *RESTAPIConfig) HasEndpoint(name string) bool {
+	if len(c.Endpoints) == 0 {
+		return IsKnownEndpoint(name)
+	}
+	for _, endpoint := range c.Endpoints {
+		if endpoint == name {
+			return true
+		}
+	}
+	return false
+}
+
+// EndpointsExcept returns the selected API groups without the given one
+func (c *RESTAPIConfig) EndpointsExcept(name string) []string {
+	endpoints := c.Endpoints
+	if len(endpoints) == 0 {
+		endpoints = AllEndpoints()
+	}
+	
+	remaining := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		if endpoint != name {
+			remaining = append(remaining, endpoint)
+		}
+	}
+	return remaining
+}
+
+func NewRESTAPIScraper(cfg *RESTAPIConfig, settings receiver.Settings, drops *DropTracker) *RESTAPIScraper {
+	endpoints := cfg.Endpoints
+	if len(endpoints) == 0 {
+		endpoints = AllEndpoints()
+	}
+	enabled := make(map[string]bool, len(endpoints))
+	for _, endpoint := range endpoints {
+		enabled[endpoint] = true
+	}
+	
+	name := cfg.Name
+	if name == "" {
+		name = "rest_api"
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	
+	health := NewScraperHealth(name, settings)
+	health.SetStatusReporter(cfg.Status)
+	health.SetSummaryInterval(cfg.SummaryLogInterval)
+	
+	mb := NewMetricsBuilder(settings)
+	mb.SetExcludedMetrics(cfg.ExcludeMetrics)
+	mb.SetDisabledAttributes(cfg.DisabledAttributes)
+	mb.SetDropTracker(drops)
+	mb.SetRedactor(cfg.Redactor)
+	mb.SetResourcePerDAG(cfg.ResourcePerDAG)
+	
 	return &RESTAPIScraper{
-		cfg:         cfg,
-		settings:    settings,
-		client:      &http.Client{Timeout: 30 * time.Second},
-		mb:          NewMetricsBuilder(),
-		retryConfig: DefaultRetryConfig(),
-		health:      NewScraperHealth("rest_api", settings.Logger),
+		cfg:               cfg,
+		settings:          settings,
+		client:            &http.Client{Timeout: 30 * time.Second},
+		mb:                mb,
+		retryConfig:       DefaultRetryConfig(),
+		health:            health,
+		drops:             drops,
+		endpoints:         enabled,
+		runTrackers:       make(map[string]*dagRunTracker),
+		taskCache:         make(map[string]taskDefinitions),
+		caps:              newAPICapabilities(),
+		starvation:        newPoolStarvationTracker(),
+		operatorDurations: newOperatorHistograms(),
+		dagAggregates:     newDAGAggregates(),
+		conditional:       newConditionalCache(),
 	}
 }
 
+// endpointEnabled reports whether an API group should be scraped
+func (s *RESTAPIScraper) endpointEnabled(name string) bool {
+	return s.endpoints[name]
+}
+
 func (s *RESTAPIScraper) Start(ctx context.Context, host component.Host) error {
 	s.settings.Logger.Info("Starting REST API scraper", zap.String("endpoint", s.cfg.Endpoint))
+	s.cfg.Status.SetHost(host)
+	
+	// Proxy, TLS, timeout, compression, headers and auth extensions all come
+	// from the collector's HTTP client settings
+	client, err := s.cfg.ClientConfig.ToClient(ctx, host, s.settings.TelemetrySettings)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	s.client = client
+	
+	if s.cfg.MWAA != nil {
+		transport, err := newMWAATransport(ctx, client.Transport, *s.cfg.MWAA, s.settings.Logger)
+		if err != nil {
+			return err
+		}
+		s.client.Transport = transport
+	}
+	if s.cfg.SessionLogin {
+		s.client.Transport = newLoginTransport(client.Transport, s.cfg.Endpoint, s.cfg.Username, s.cfg.Password)
+	}
+	
+	if s.cfg.StrictStartup {
+		if _, err := s.getVersion(ctx); err != nil {
+			return fmt.Errorf("failed to reach Airflow REST API at %s: %w", s.cfg.Endpoint, err)
+		}
+	}
+	
 	return nil
 }
 
 func (s *RESTAPIScraper) Scrape(ctx context.Context) (pmetric.Metrics, error) {
+	// Bound the whole scrape so a slow webserver cannot push it past the next interval
+	if s.cfg.ScrapeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.ScrapeTimeout)
+		defer cancel()
+	}
+	
+	// Deployment metadata is best effort and does not affect scrape health
+	s.cfg.Info.load(ctx, s)
+	
 	// Use health tracking wrapper
 	metrics, err := s.health.WithScrapeTracking(ctx, func(ctx context.Context) (pmetric.Metrics, error) {
 		now := time.Now()
-		s.scrapeComprehensive(ctx, now)
-		return s.mb.Emit(), nil
+		err := s.scrapeComprehensive(ctx, now)
+		return s.mb.Emit(), err
 	})
 	
 	// Add health metrics to output
-	s.health.EmitMetrics(s.mb, time.Now())
+	s.health.AppendMetrics(metrics, time.Now())
 	
 	return metrics, err
 }
 
 func (s *RESTAPIScraper) Shutdown(ctx context.Context) error {
 	s.settings.Logger.Info("Shutting down REST API scraper")
+	s.client.CloseIdleConnections()
 	return nil
 }
 
+// apiResponse is a webserver response that was accepted by fetch
+type apiResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
 func (s *RESTAPIScraper) doRequest(ctx context.Context, path string) ([]byte, error) {
+	resp, err := s.fetch(ctx, http.MethodGet, path, nil, nil)
+	return resp.body, err
+}
+
+// doPost sends payload as JSON to one of the API's POST list endpoints
+func (s *RESTAPIScraper) doPost(ctx context.Context, path string, payload any) ([]byte, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.fetch(ctx, http.MethodPost, path, encoded, nil)
+	return resp.body, err
+}
+
+// fetch performs a request with retries. A 304 is only accepted when header
+// carries conditional validators; its body is empty.
+func (s *RESTAPIScraper) fetch(ctx context.Context, method, path string, payload []byte, header http.Header) (apiResponse, error) {
 	url := s.cfg.Endpoint + path
 	
-	var body []byte
-	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, fmt.Sprintf("GET %s", path), func() error {
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	var result apiResponse
+	err := RetryWithBackoff(ctx, s.retryConfig, s.settings.Logger, fmt.Sprintf("%s %s", method, path), func() error {
+		if s.cfg.Limiter != nil {
+			if err := s.cfg.Limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+		
+		var reqBody io.Reader
+		if payload != nil {
+			reqBody = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 		if err != nil {
 			return err
 		}
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
 		
-		req.SetBasicAuth(s.cfg.Username, s.cfg.Password)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		s.authorize(req)
 		req.Header.Set("Accept", "application/json")
+		if s.cfg.DisableResponseCompression {
+			req.Header.Set("Accept-Encoding", "identity")
+		} else {
+			req.Header.Set("Accept-Encoding", "gzip")
+		}
 		
 		resp, err := s.client.Do(req)
 		if err != nil {
+			// A login flow rejected the credentials before the API was reached
+			if errors.Is(err, ErrAuthFailed) {
+				return Permanent(err)
+			}
 			return err
 		}
 		defer resp.Body.Close()
 		
+		if resp.StatusCode == http.StatusNotModified && len(header) > 0 {
+			result = apiResponse{status: resp.StatusCode, header: resp.Header}
+			return nil
+		}
 		if resp.StatusCode != http.StatusOK {
-			// Don't retry authentication failures
+			// Don't retry authentication failures or missing endpoints
 			if resp.StatusCode == 401 || resp.StatusCode == 403 {
-				body = nil
-				return fmt.Errorf("authentication failed: status code %d", resp.StatusCode)
+				result = apiResponse{}
+				return Permanent(fmt.Errorf("%w: status code %d", ErrAuthFailed, resp.StatusCode))
+			}
+			if resp.StatusCode == http.StatusNotFound {
+				return Permanent(fmt.Errorf("%w: %s", ErrNotFound, path))
+			}
+			// The same request would be rejected again
+			if resp.StatusCode == http.StatusBadRequest {
+				return Permanent(fmt.Errorf("%w: %s", ErrBadRequest, path))
 			}
 			// Retry server errors
 			return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 		}
 		
-		body, err = io.ReadAll(resp.Body)
+		body, err := readBody(resp)
+		result = apiResponse{status: resp.StatusCode, header: resp.Header, body: body}
 		return err
 	})
 	
-	return body, err
+	return result, err
+}
+
+// readBody reads a response body, decompressing it when the webserver gzipped
+// it. fetch sets Accept-Encoding itself, which turns off the transport's own
+// transparent decompression.
+func readBody(resp *http.Response) ([]byte, error) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return io.ReadAll(resp.Body)
+	}
+	reader, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress response: %w", err)
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
 }
 
+// authorize adds the configured credentials to a webserver request. MWAA,
+// login sessions and auth extensions are attached by the client's transport
+// instead.
+func (s *RESTAPIScraper) authorize(req *http.Request) {
+	switch {
+	case s.cfg.MWAA != nil, s.cfg.SessionLogin, s.cfg.ClientConfig.Auth.HasValue():
+	case s.cfg.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+s.cfg.BearerToken)
+	default:
+		req.SetBasicAuth(s.cfg.Username, s.cfg.Password)
+	}
+}
+
+// getDags lists every DAG the webserver reports, regardless of the scrape
+// filters, for callers that track the whole inventory
 func (s *RESTAPIScraper) getDags(ctx context.Context) ([]DAG, error) {
-	body, err := s.doRequest(ctx, "/api/v1/dags")
+	return s.listDags(ctx, "/api/v1/dags")
+}
+
+// scrapedDAGsPath applies the only_active and paused filters to /dags so
+// filtered DAGs never cost a run query
+func (s *RESTAPIScraper) scrapedDAGsPath() string {
+	var filters []string
+	if s.cfg.OnlyActiveDAGs {
+		filters = append(filters, "only_active=true")
+	}
+	if s.cfg.ExcludePausedDAGs {
+		filters = append(filters, "paused=false")
+	}
+	if len(filters) == 0 {
+		return "/api/v1/dags"
+	}
+	return "/api/v1/dags?" + strings.Join(filters, "&")
+}
+
+// listDags pages through path, up to maxListItems DAGs
+func (s *RESTAPIScraper) listDags(ctx context.Context, path string) ([]DAG, error) {
+	page, err := withProjection(s, CapabilityDAGFields, path, dagFields, func(path string) (pagedResult[DAG], error) {
+		return fetchConditionalPages(ctx, s, path, maxListItems, func(body []byte) ([]DAG, int, error) {
+			var response DAGResponse
+			if err := json.Unmarshal(body, &response); err != nil {
+				return nil, 0, err
+			}
+			return response.DAGs, response.TotalEntries, nil
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
+	s.recordTruncated(SignalMetrics, EndpointDAGs, page.total, len(page.items), maxListItems)
+	return page.items, nil
+}
+
+// getDagsCached returns the DAG inventory, reusing the previous response until
+// DAGCacheTTL has elapsed
+func (s *RESTAPIScraper) getDagsCached(ctx context.Context) ([]DAG, error) {
+	if s.cfg.DAGCacheTTL <= 0 {
+		return s.listDags(ctx, s.scrapedDAGsPath())
+	}
 	
-	var response DAGResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, err
+	s.dagCacheMu.Lock()
+	defer s.dagCacheMu.Unlock()
+	
+	if s.dagCache != nil && time.Now().Before(s.dagCacheExpires) {
+		return s.dagCache, nil
 	}
 	
-	return response.DAGs, nil
+	dags, err := s.listDags(ctx, s.scrapedDAGsPath())
+	if err != nil {
+		return nil, err
+	}
+	s.dagCache = dags
+	s.dagCacheExpires = time.Now().Add(s.cfg.DAGCacheTTL)
+	return dags, nil
 }
 
-func (s *RESTAPIScraper) getDAGRuns(ctx context.Context, dagID string) ([]DAGRun, error) {
-	path := fmt.Sprintf("/api/v1/dags/%s/dagRuns?limit=100", dagID)
+func (s *RESTAPIScraper) runTracker(dagID string) *dagRunTracker {
+	tracker, ok := s.runTrackers[dagID]
+	if !ok {
+		tracker = newDAGRunTracker()
+		s.runTrackers[dagID] = tracker
+	}
+	return tracker
+}
+
+// pruneRunTrackers drops state for DAGs that no longer exist
+func (s *RESTAPIScraper) pruneRunTrackers(dags []DAG) {
+	current := make(map[string]bool, len(dags))
+	for _, dag := range dags {
+		current[dag.DAGID] = true
+	}
+	for dagID := range s.runTrackers {
+		if !current[dagID] {
+			delete(s.runTrackers, dagID)
+		}
+	}
+}
+
+// runRetention is how long finished runs are kept for state counts
+func (s *RESTAPIScraper) runRetention() time.Duration {
+	if s.cfg.IncludePastRuns && s.cfg.PastRunsLookback > 0 {
+		return s.cfg.PastRunsLookback
+	}
+	return 24 * time.Hour
+}
+
+// getDAGRuns lists recent runs, newest first so max_dag_runs keeps the
+// runs still in progress. A non-zero since only returns runs updated after it
+// (Airflow 2.6+).
+func (s *RESTAPIScraper) getDAGRuns(ctx context.Context, dagID string, since time.Time) ([]DAGRun, error) {
+	path := fmt.Sprintf("/api/v1/dags/%s/dagRuns", dagID)
+	filters := []string{"order_by=-execution_date"}
 	if s.cfg.IncludePastRuns {
 		startDate := time.Now().Add(-s.cfg.PastRunsLookback)
-		path += fmt.Sprintf("&start_date_gte=%s", startDate.Format(time.RFC3339))
+		filters = append(filters, "start_date_gte="+url.QueryEscape(startDate.Format(time.RFC3339)))
+	}
+	if !since.IsZero() {
+		filters = append(filters, "updated_at_gte="+url.QueryEscape(since.UTC().Format(time.RFC3339)))
 	}
+	path += "?" + strings.Join(filters, "&")
 	
-	body, err := s.doRequest(ctx, path)
+	maxRuns := s.cfg.MaxDAGRuns
+	if maxRuns <= 0 {
+		maxRuns = DefaultMaxDAGRuns
+	}
+	page, err := withProjection(s, CapabilityDAGRunFields, path, dagRunFields, func(path string) (pagedResult[DAGRun], error) {
+		return fetchPages(ctx, s, path, maxRuns, func(body []byte) ([]DAGRun, int, error) {
+			var response DAGRunsResponse
+			if err := json.Unmarshal(body, &response); err != nil {
+				return nil, 0, err
+			}
+			return response.DAGRuns, response.TotalEntries, nil
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
-	
-	var response DAGRunsResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, err
+	runs, total := page.items, page.total
+	if total > len(runs) {
+		s.drops.Record(SignalMetrics, DropReasonTruncated, int64(total-len(runs)),
+			zap.String("dag_id", dagID), zap.Int("limit", maxRuns))
 	}
 	
-	return response.DAGRuns, nil
+	return runs, nil
 }
 
 func (s *RESTAPIScraper) getTaskInstances(ctx context.Context, dagID, dagRunID string) ([]TaskInstance, error) {
 	path := fmt.Sprintf("/api/v1/dags/%s/dagRuns/%s/taskInstances", dagID, dagRunID)
 	
-	body, err := s.doRequest(ctx, path)
+	maxTasks := s.cfg.MaxTaskInstances
+	if maxTasks <= 0 {
+		maxTasks = DefaultMaxTaskInstances
+	}
+	page, err := withProjection(s, CapabilityTaskInstanceFields, path, taskInstanceFields, func(path string) (pagedResult[TaskInstance], error) {
+		return fetchPages(ctx, s, path, maxTasks, func(body []byte) ([]TaskInstance, int, error) {
+			var response TaskInstancesResponse
+			if err := json.Unmarshal(body, &response); err != nil {
+				return nil, 0, err
+			}
+			return response.TaskInstances, response.TotalEntries, nil
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
-	
-	var response TaskInstancesResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, err
+	tasks, total := page.items, page.total
+	if total > len(tasks) {
+		s.drops.Record(SignalMetrics, DropReasonTruncated, int64(total-len(tasks)),
+			zap.String("dag_id", dagID), zap.String("dag_run_id", dagRunID), zap.Int("limit", maxTasks))
 	}
 	
-	return response.TaskInstances, nil
+	return tasks, nil
 }
 
 func (s *RESTAPIScraper) getPools(ctx context.Context) ([]Pool, error) {
-	body, err := s.doRequest(ctx, "/api/v1/pools")
+	page, err := fetchConditionalPages(ctx, s, "/api/v1/pools", maxListItems, func(body []byte) ([]Pool, int, error) {
+		var response PoolsResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, 0, err
+		}
+		return response.Pools, response.TotalEntries, nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	
-	var response PoolsResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, err
-	}
-	
-	return response.Pools, nil
+	s.recordTruncated(SignalMetrics, EndpointPools, page.total, len(page.items), maxListItems)
+	return page.items, nil
 }
 
 func (s *RESTAPIScraper) getHealth(ctx context.Context) (*HealthResponse, error) {
@@ -184,44 +665,100 @@ func (s *RESTAPIScraper) getHealth(ctx context.Context) (*HealthResponse, error)
 	return &response, nil
 }
 
-func (s *RESTAPIScraper) getConnections(ctx context.Context) ([]Connection, error) {
-	body, err := s.doRequest(ctx, "/api/v1/connections?limit=100")
+func (s *RESTAPIScraper) getVersion(ctx context.Context) (*VersionResponse, error) {
+	body, err := s.doRequest(ctx, "/api/v1/version")
 	if err != nil {
 		return nil, err
 	}
 	
-	var response ConnectionsResponse
+	var response VersionResponse
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, err
 	}
 	
-	return response.Connections, nil
+	return &response, nil
 }
 
-func (s *RESTAPIScraper) getVariables(ctx context.Context) ([]Variable, error) {
-	body, err := s.doRequest(ctx, "/api/v1/variables?limit=100")
+func (s *RESTAPIScraper) getConnections(ctx context.Context) ([]Connection, error) {
+	page, err := fetchConditionalPages(ctx, s, "/api/v1/connections", maxListItems, func(body []byte) ([]Connection, int, error) {
+		var response ConnectionsResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, 0, err
+		}
+		return response.Connections, response.TotalEntries, nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	s.recordTruncated(SignalMetrics, EndpointConnections, page.total, len(page.items), maxListItems)
+	return page.items, nil
+}
+
+// getVariableCount returns the number of variables without listing them
+func (s *RESTAPIScraper) getVariableCount(ctx context.Context) (int, error) {
+	body, err := s.doRequest(ctx, "/api/v1/variables?limit=1")
+	if err != nil {
+		return 0, err
+	}
 	
 	var response VariablesResponse
 	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, err
+		return 0, err
 	}
 	
-	return response.Variables, nil
+	return response.TotalEntries, nil
 }
 
-func (s *RESTAPIScraper) getImportErrors(ctx context.Context) ([]ImportError, error) {
-	body, err := s.doRequest(ctx, "/api/v1/importErrors?limit=100")
+// getDatasetCount returns the number of datasets without listing them
+func (s *RESTAPIScraper) getDatasetCount(ctx context.Context) (int, error) {
+	body, err := s.doRequest(ctx, "/api/v1/datasets?limit=1")
 	if err != nil {
-		return nil, err
+		return 0, err
+	}
+	
+	var response DatasetsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return 0, err
+	}
+	
+	return response.TotalEntries, nil
+}
+
+// getProviders returns the first page of providers and the total number
+// installed
+func (s *RESTAPIScraper) getProviders(ctx context.Context) (pagedResult[Provider], error) {
+	return getConditional(ctx, s, "/api/v1/providers", func(body []byte) (pagedResult[Provider], error) {
+		var response ProvidersResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return pagedResult[Provider]{}, err
+		}
+		return pagedResult[Provider]{items: response.Providers, total: max(response.TotalEntries, len(response.Providers))}, nil
+	})
+}
+
+// getPlugins returns the first page of plugins and the total number loaded
+func (s *RESTAPIScraper) getPlugins(ctx context.Context) (pagedResult[Plugin], error) {
+	return getConditional(ctx, s, "/api/v1/plugins", func(body []byte) (pagedResult[Plugin], error) {
+		var response PluginsResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return pagedResult[Plugin]{}, err
+		}
+		return pagedResult[Plugin]{items: response.Plugins, total: max(response.TotalEntries, len(response.Plugins))}, nil
+	})
+}
+
+// getImportErrorCount returns the number of import errors without listing
+// them
+func (s *RESTAPIScraper) getImportErrorCount(ctx context.Context) (int, error) {
+	body, err := s.doRequest(ctx, "/api/v1/importErrors?limit=1")
+	if err != nil {
+		return 0, err
 	}
 	
 	var response ImportErrorsResponse
 	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, err
+		return 0, err
 	}
 	
-	return response.ImportErrors, nil
+	return response.TotalEntries, nil
 }
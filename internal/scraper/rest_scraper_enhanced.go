@@ -5,31 +5,53 @@ package scraper
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"time"
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/scraper/scrapererror"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 )
 
-func (s *RESTAPIScraper) scrapeComprehensive(ctx context.Context, now time.Time) {
+// scrapeComprehensive collects every enabled API group. Failed requests are
+// returned as partial scrape errors so the data that was collected still
+// reaches the pipeline.
+func (s *RESTAPIScraper) scrapeComprehensive(ctx context.Context, now time.Time) error {
 	ts := pcommon.NewTimestampFromTime(now)
+	var errs scrapererror.ScrapeErrors
 	
-	s.scrapeHealthMetrics(ctx, ts)
-	s.scrapeDAGMetrics(ctx, ts)
+	if s.endpointEnabled(EndpointHealth) {
+		s.scrapeHealthMetrics(ctx, ts, &errs)
+	}
+	if s.endpointEnabled(EndpointDAGs) {
+		s.scrapeDAGMetrics(ctx, ts, &errs)
+	}
 	
-	pools, err := s.getPools(ctx)
-	if err == nil {
-		s.recordEnhancedPoolMetrics(pools, ts)
+	if s.endpointEnabled(EndpointPools) {
+		pools, err := s.getPools(ctx)
+		if err != nil {
+			s.settings.Logger.Warn("Failed to get pools", zap.Error(err))
+			errs.AddPartial(1, fmt.Errorf("failed to get pools: %w", err))
+		} else {
+			s.recordEnhancedPoolMetrics(pools, ts)
+		}
 	}
 	
-	s.scrapeConnectionMetrics(ctx, ts)
-	s.scrapeConfigMetrics(ctx, ts)
+	if s.endpointEnabled(EndpointConnections) {
+		s.scrapeConnectionMetrics(ctx, ts, &errs)
+	}
+	s.scrapeConfigMetrics(ctx, ts, &errs)
+	
+	return errs.Combine()
 }
 
-func (s *RESTAPIScraper) scrapeHealthMetrics(ctx context.Context, ts pcommon.Timestamp) {
+func (s *RESTAPIScraper) scrapeHealthMetrics(ctx context.Context, ts pcommon.Timestamp, errs *scrapererror.ScrapeErrors) {
 	health, err := s.getHealth(ctx)
 	if err != nil {
 		s.settings.Logger.Warn("Failed to get health", zap.Error(err))
+		errs.AddPartial(1, fmt.Errorf("failed to get health: %w", err))
 		return
 	}
 	
@@ -42,14 +64,15 @@ func (s *RESTAPIScraper) scrapeHealthMetrics(ctx context.Context, ts pcommon.Tim
 	}
 }
 
-func (s *RESTAPIScraper) scrapeDAGMetrics(ctx context.Context, ts pcommon.Timestamp) {
-	dags, err := s.getDags(ctx)
+func (s *RESTAPIScraper) scrapeDAGMetrics(ctx context.Context, ts pcommon.Timestamp, errs *scrapererror.ScrapeErrors) {
+	dags, err := s.getDagsCached(ctx)
 	if err != nil {
 		s.settings.Logger.Error("Failed to get DAGs", zap.Error(err))
+		errs.AddPartial(1, fmt.Errorf("failed to get DAGs: %w", err))
 		return
 	}
 	
-	s.settings.Logger.Info("Scraping comprehensive DAG metrics", zap.Int("dag_count", len(dags)))
+	s.settings.Logger.Debug("Scraping comprehensive DAG metrics", zap.Int("dag_count", len(dags)))
 	
 	// Count DAGs by status and record with tags
 	pausedCount := int64(0)
@@ -73,25 +96,263 @@ func (s *RESTAPIScraper) scrapeDAGMetrics(ctx context.Context, ts pcommon.Timest
 	s.mb.RecordDAGCount(pausedCount, "paused", time.Now())
 	s.mb.RecordDAGCount(activeCount, "active", time.Now())
 	
-	// For each DAG, get runs
-	for _, dag := range dags {
-		dagRuns, err := s.getDAGRuns(ctx, dag.DAGID)
+	if s.cfg.TaskMetadata {
+		s.scrapeTaskMetadata(ctx, dags, ts, errs)
+	}
+	
+	if !s.endpointEnabled(EndpointDAGRuns) {
+		return
+	}
+	
+	// Run counts by state come from /dagStats when the webserver has it,
+	// rather than from enumerating each DAG's runs
+	var runStats map[string]map[string]int64
+	err = s.scrapeOptional(CapabilityDAGStats, func() error {
+		dagIDs := make([]string, len(dags))
+		for i, dag := range dags {
+			dagIDs[i] = dag.DAGID
+		}
+		var err error
+		runStats, err = s.getDAGStats(ctx, dagIDs)
+		return err
+	})
+	if err != nil {
+		errs.AddPartial(1, fmt.Errorf("failed to get DAG stats: %w", err))
+	}
+	
+	// Build requests sequentially so run trackers are only touched here
+	requests := make([]dagRunsRequest, len(dags))
+	for i, dag := range dags {
+		requests[i] = dagRunsRequest{dagID: dag.DAGID}
+		if s.cfg.IncrementalRuns {
+			tracker := s.runTracker(dag.DAGID)
+			requests[i].since = tracker.since()
+			requests[i].running = tracker.runningRunIDs()
+			for _, runID := range requests[i].running {
+				requests[i].runningFrom = earliest(requests[i].runningFrom, runLogicalDate(tracker.runs[runID]))
+			}
+			// Nothing changed since the last fetch, so there are no new
+			// runs to request
+			requests[i].skip = !requests[i].since.IsZero() && len(requests[i].running) == 0 &&
+				runsSettled(s.runStats[dag.DAGID], runStats[dag.DAGID])
+		}
+	}
+	if s.cfg.IncrementalRuns {
+		s.pruneRunTrackers(dags)
+	}
+	
+	// One paginated batch listing replaces a GET per DAG. If it fails, each
+	// DAG's runs are requested on their own.
+	if s.cfg.BatchDAGRuns && len(dags) > 0 {
+		listed, err := s.listDAGRuns(ctx, dags)
 		if err != nil {
+			s.settings.Logger.Warn("Failed to list DAG runs in batch, requesting them per DAG", zap.Error(err))
+		} else {
+			for i := range requests {
+				requests[i].listed = true
+				requests[i].runs = listed[requests[i].dagID]
+			}
+		}
+	}
+	
+	// Fetch runs and task instances for all DAGs with bounded concurrency,
+	// then record sequentially since the metrics builder is not thread-safe
+	results := make([]dagRunsResult, len(dags))
+	var g errgroup.Group
+	g.SetLimit(s.cfg.Concurrency)
+	for i := range requests {
+		g.Go(func() error {
+			results[i] = s.fetchDAGRuns(ctx, requests[i])
+			return nil
+		})
+	}
+	_ = g.Wait()
+	
+	if s.batchTaskInstances() {
+		s.fetchTaskInstancesBatch(ctx, dags, results)
+	}
+	
+	tries := newTaskTryStats()
+	workers := newWorkerTaskStats()
+	fetchedStats := make(map[string]map[string]int64, len(dags))
+	for i, dag := range dags {
+		s.recordDAGRuns(dag, results[i], runStats[dag.DAGID], tries, workers, ts, errs)
+		if stats, ok := runStats[dag.DAGID]; ok && (results[i].fetched || results[i].skipped) {
+			fetchedStats[dag.DAGID] = stats
+		}
+	}
+	s.runStats = fetchedStats
+	if s.endpointEnabled(EndpointTaskInstances) {
+		s.operatorDurations.emit(s.mb, ts.AsTime(), s.runRetention())
+		tries.record(s.mb, ts)
+		if s.cfg.WorkerHostname {
+			workers.record(s.mb, ts)
+		}
+	}
+	if s.aggregatedOutput() {
+		s.dagAggregates.emit(s.mb, ts.AsTime(), s.runRetention())
+	}
+}
+
+// aggregatedOutput reports whether per-run and per-task-instance points are
+// replaced by per-DAG aggregates
+func (s *RESTAPIScraper) aggregatedOutput() bool {
+	return s.cfg.OutputMode == OutputModeAggregated
+}
+
+// dagRunsRequest describes what to fetch for a single DAG. since, running and
+// skip are only set for incremental scraping.
+type dagRunsRequest struct {
+	dagID   string
+	since   time.Time
+	running []string
+	skip    bool
+	// runningFrom is the earliest logical date of the running runs
+	runningFrom time.Time
+	// listed is set when runs already came from the batch listing
+	listed bool
+	runs   []DAGRun
+}
+
+// dagRunsResult holds everything fetched for a single DAG
+type dagRunsResult struct {
+	fetched bool
+	// skipped is set when the DAG had no run activity and was not requested
+	skipped   bool
+	fetchedAt time.Time
+	runs      []DAGRun
+	tasks     map[string][]TaskInstance
+	errs      []error
+	// taskRunIDs are the runs whose task instances are fetched, and
+	// taskRunsFrom the earliest of their logical dates
+	taskRunIDs   []string
+	taskRunsFrom time.Time
+}
+
+func (s *RESTAPIScraper) fetchDAGRuns(ctx context.Context, req dagRunsRequest) dagRunsResult {
+	result := dagRunsResult{fetchedAt: time.Now()}
+	dagID := req.dagID
+	
+	if req.skip {
+		result.skipped = true
+		if s.endpointEnabled(EndpointTaskInstances) {
+			result.tasks = make(map[string][]TaskInstance)
+		}
+		return result
+	}
+	
+	dagRuns := req.runs
+	if !req.listed {
+		var err error
+		dagRuns, err = s.getDAGRuns(ctx, dagID, req.since)
+		if err != nil {
+			result.errs = append(result.errs, fmt.Errorf("failed to get DAG runs for %s: %w", dagID, err))
+			return result
+		}
+	}
+	result.fetched = true
+	result.runs = dagRuns
+	
+	if !s.endpointEnabled(EndpointTaskInstances) {
+		return result
+	}
+	
+	// Get task instances for recent/running runs, plus runs that were still
+	// active last time even if they were not updated since
+	runIDs := make([]string, 0, len(dagRuns)+len(req.running))
+	for _, run := range dagRuns {
+		if run.DAGRunID == "" {
 			continue
 		}
 		
-		runsByState := make(map[string]int64)
-		for _, run := range dagRuns {
-			// Use DAGRunID not RunID!
-			if run.DAGRunID == "" {
-				s.settings.Logger.Warn("Empty dag_run_id, skipping",
-					zap.String("dag_id", run.DAGID),
-					zap.String("state", run.State))
-				continue
-			}
-			
+		if run.State == "running" || time.Since(run.StartDate) < 5*time.Minute {
+			runIDs = append(runIDs, run.DAGRunID)
+			result.taskRunsFrom = earliest(result.taskRunsFrom, runLogicalDate(run))
+		}
+	}
+	runIDs = append(runIDs, req.running...)
+	result.taskRunsFrom = earliest(result.taskRunsFrom, req.runningFrom)
+	slices.Sort(runIDs)
+	result.taskRunIDs = slices.Compact(runIDs)
+	
+	// The batch listing covers every DAG at once after all runs are known
+	if !s.batchTaskInstances() {
+		s.fetchTaskInstances(ctx, dagID, &result)
+	}
+	return result
+}
+
+// fetchTaskInstances requests the task instances of each of a DAG's runs
+func (s *RESTAPIScraper) fetchTaskInstances(ctx context.Context, dagID string, result *dagRunsResult) {
+	result.tasks = make(map[string][]TaskInstance)
+	for _, runID := range result.taskRunIDs {
+		tasks, err := s.getTaskInstances(ctx, dagID, runID)
+		if err != nil {
+			result.errs = append(result.errs, fmt.Errorf("failed to get task instances for %s/%s: %w", dagID, runID, err))
+			continue
+		}
+		result.tasks[runID] = tasks
+	}
+}
+
+// recordDAGRuns records one DAG's runs and task instances. runStats holds the
+// DAG's run counts from /dagStats, or nil to count the fetched runs instead.
+func (s *RESTAPIScraper) recordDAGRuns(dag DAG, result dagRunsResult, runStats map[string]int64, tries taskTryStats, workers *workerTaskStats, ts pcommon.Timestamp, errs *scrapererror.ScrapeErrors) {
+	dagID := dag.DAGID
+	for _, err := range result.errs {
+		errs.AddPartial(1, err)
+	}
+	if !result.fetched && !result.skipped {
+		return
+	}
+	
+	validRuns := make([]DAGRun, 0, len(result.runs))
+	for _, run := range result.runs {
+		// Use DAGRunID not RunID!
+		if run.DAGRunID == "" {
+			s.drops.Record(SignalMetrics, DropReasonMissingID, 1,
+				zap.String("dag_id", run.DAGID),
+				zap.String("state", run.State))
+			continue
+		}
+		validRuns = append(validRuns, run)
+	}
+	
+	// Incremental scraping counts states over every tracked run, but only
+	// reports durations for runs that finished since the previous fetch
+	stateRuns, durationRuns := validRuns, validRuns
+	if s.cfg.IncrementalRuns {
+		tracker := s.runTracker(dagID)
+		// A skipped DAG keeps its watermark so the next fetch covers the gap
+		durationRuns = nil
+		if result.fetched {
+			durationRuns = tracker.merge(validRuns, result.fetchedAt, s.runRetention())
+		}
+		stateRuns = make([]DAGRun, 0, len(tracker.runs))
+		for _, run := range tracker.runs {
+			stateRuns = append(stateRuns, run)
+		}
+	}
+	
+	runsByState := runStats
+	if runsByState == nil {
+		runsByState = make(map[string]int64)
+		for _, run := range stateRuns {
 			runsByState[run.State]++
-			
+		}
+	}
+	
+	// Aggregated output replaces a point per finished run with a histogram
+	// per DAG and the state of its latest run
+	if s.aggregatedOutput() {
+		for _, run := range durationRuns {
+			s.dagAggregates.observeRun(run)
+		}
+		if run, ok := latestRun(stateRuns); ok {
+			s.mb.RecordDAGLastRunState(dagID, run.State, run.RunType, ts)
+		}
+	} else {
+		for _, run := range durationRuns {
 			// Record duration with full dimensions
 			if (run.State == "success" || run.State == "failed") && !run.EndDate.IsZero() && !run.StartDate.IsZero() {
 				duration := run.EndDate.Sub(run.StartDate).Seconds()
@@ -108,50 +369,92 @@ func (s *RESTAPIScraper) scrapeDAGMetrics(ctx context.Context, ts pcommon.Timest
 				}
 			}
 		}
+	}
+	
+	for state, count := range runsByState {
+		s.mb.RecordDAGRunsByState(count, dagID, state, time.Now())
+	}
+	
+	// Utilization against the DAG's own limits shows runs and tasks held back
+	// by max_active_runs/max_active_tasks rather than by pools or workers
+	if dag.MaxActiveRuns > 0 {
+		s.mb.RecordDAGActiveRunsUtilization(float64(runsByState["running"])/float64(dag.MaxActiveRuns), dagID, ts)
+	}
+	
+	runningTasks := int64(0)
+	for _, tasks := range result.tasks {
+		tasksByState := make(map[string]int64)
+		for _, task := range tasks {
+			tasksByState[task.State]++
+			if task.State == "running" {
+				runningTasks++
+			}
+			s.operatorDurations.observe(task)
+			tries.add(task.DAGID, task.TaskID, task.State, task.TryNumber, 1)
+			if s.cfg.WorkerHostname {
+				workers.add(task)
+			}
+			if s.aggregatedOutput() {
+				s.dagAggregates.observeTask(task)
+			}
+		}
 		
-		for state, count := range runsByState {
-			s.mb.RecordDAGRunsByState(count, dag.DAGID, state, time.Now())
+		if !s.aggregatedOutput() {
+			s.recordTaskInstancePoints(tasks, ts)
 		}
 		
-		// Get task instances for recent/running runs
-		for _, run := range dagRuns {
-			if run.DAGRunID == "" {
-				continue
-			}
-			
-			if run.State == "running" || time.Since(run.StartDate) < 5*time.Minute {
-				tasks, err := s.getTaskInstances(ctx, dag.DAGID, run.DAGRunID)
-				if err != nil {
-					continue
-				}
-				
-				tasksByState := make(map[string]int64)
-				for _, task := range tasks {
-					tasksByState[task.State]++
-					
-					// Record with ALL dimensions
-					if task.Duration > 0 && task.TaskID != "" && task.DAGRunID != "" {
-						s.mb.RecordTaskInstanceDurationWithDimensions(
-							task.Duration,
-							task.DAGID,
-							task.TaskID,
-							task.DAGRunID,
-							task.State,
-							task.Operator,
-							task.Pool,
-							task.Queue,
-							task.TryNumber,
-							ts,
-						)
-					}
-				}
-				
-				for state, count := range tasksByState {
-					s.mb.RecordTaskInstancesByState(count, dag.DAGID, state, time.Now())
-				}
-			}
+		for state, count := range tasksByState {
+			s.mb.RecordTaskInstancesByState(count, dagID, state, time.Now())
 		}
 	}
+	
+	// Running tasks can only belong to runs whose task instances were fetched
+	if result.tasks != nil && dag.MaxActiveTasks > 0 {
+		s.mb.RecordDAGActiveTasksUtilization(float64(runningTasks)/float64(dag.MaxActiveTasks), dagID, ts)
+	}
+}
+
+// recordTaskInstancePoints records a run's task instance durations, one point
+// per instance or a summary per widely mapped task
+func (s *RESTAPIScraper) recordTaskInstancePoints(tasks []TaskInstance, ts pcommon.Timestamp) {
+	// Mapped tasks are summarized per task unless the fan-out is small
+	// enough to keep one point per map index
+	unmapped, mapped := groupMappedTasks(tasks)
+	for _, group := range mapped {
+		if len(group.instances) <= s.cfg.MappedTaskRawMaxFanOut {
+			unmapped = append(unmapped, group.instances...)
+			continue
+		}
+		group.record(s.mb, ts)
+	}
+	for _, task := range unmapped {
+		s.recordTaskInstanceDuration(task, ts)
+	}
+}
+
+func (s *RESTAPIScraper) recordTaskInstanceDuration(task TaskInstance, ts pcommon.Timestamp) {
+	hostname := ""
+	if s.cfg.WorkerHostname {
+		hostname = task.Hostname
+	}
+	
+	// Record with ALL dimensions
+	if task.Duration > 0 && task.TaskID != "" && task.DAGRunID != "" {
+		s.mb.RecordTaskInstanceDurationWithDimensions(
+			task.Duration,
+			task.DAGID,
+			task.TaskID,
+			task.DAGRunID,
+			task.State,
+			task.Operator,
+			task.Pool,
+			task.Queue,
+			hostname,
+			task.TryNumber,
+			task.MapIndex,
+			ts,
+		)
+	}
 }
 
 func (s *RESTAPIScraper) recordEnhancedPoolMetrics(pools []Pool, ts pcommon.Timestamp) {
@@ -167,13 +470,19 @@ func (s *RESTAPIScraper) recordEnhancedPoolMetrics(pools []Pool, ts pcommon.Time
 		s.mb.RecordPoolTotalSlots(int64(pool.Slots), pool.Name, pool.Description, time.Now())
 		s.mb.RecordPoolDeferredSlots(int64(pool.DeferredSlots), pool.Name, time.Now())
 		s.mb.RecordPoolScheduledSlots(int64(pool.ScheduledSlots), pool.Name, time.Now())
+		
+		starvation := s.starvation.observe(pool, ts.AsTime())
+		s.mb.RecordPoolStarved(starvation.starved, pool.Name, ts)
+		s.mb.RecordPoolStarvedTime(starvation.seconds, pool.Name, pcommon.NewTimestampFromTime(starvation.start), ts)
 	}
+	s.starvation.prune(pools)
 }
 
-func (s *RESTAPIScraper) scrapeConnectionMetrics(ctx context.Context, ts pcommon.Timestamp) {
+func (s *RESTAPIScraper) scrapeConnectionMetrics(ctx context.Context, ts pcommon.Timestamp, errs *scrapererror.ScrapeErrors) {
 	connections, err := s.getConnections(ctx)
 	if err != nil {
 		s.settings.Logger.Warn("Failed to get connections", zap.Error(err))
+		errs.AddPartial(1, fmt.Errorf("failed to get connections: %w", err))
 		return
 	}
 	
@@ -189,14 +498,76 @@ func (s *RESTAPIScraper) scrapeConnectionMetrics(ctx context.Context, ts pcommon
 	}
 }
 
-func (s *RESTAPIScraper) scrapeConfigMetrics(ctx context.Context, ts pcommon.Timestamp) {
-	variables, err := s.getVariables(ctx)
-	if err == nil {
-		s.mb.RecordVariableCount(int64(len(variables)), time.Now())
+func (s *RESTAPIScraper) scrapeConfigMetrics(ctx context.Context, ts pcommon.Timestamp, errs *scrapererror.ScrapeErrors) {
+	if s.endpointEnabled(EndpointVariables) {
+		count, err := s.getVariableCount(ctx)
+		if err != nil {
+			errs.AddPartial(1, fmt.Errorf("failed to get variables: %w", err))
+		} else {
+			s.mb.RecordVariableCount(int64(count), time.Now())
+		}
 	}
 	
-	importErrors, err := s.getImportErrors(ctx)
-	if err == nil {
-		s.mb.RecordImportErrorCount(int64(len(importErrors)), time.Now())
+	if s.endpointEnabled(EndpointImportErrors) {
+		count, err := s.getImportErrorCount(ctx)
+		if err != nil {
+			errs.AddPartial(1, fmt.Errorf("failed to get import errors: %w", err))
+		} else {
+			s.mb.RecordImportErrorCount(int64(count), time.Now())
+		}
+	}
+	
+	if s.endpointEnabled(EndpointProviders) {
+		providers, err := s.getProviders(ctx)
+		if err != nil {
+			errs.AddPartial(1, fmt.Errorf("failed to get providers: %w", err))
+		} else {
+			s.mb.RecordProviderCount(int64(providers.total), time.Now())
+			for _, provider := range providers.items {
+				s.mb.RecordProviderInfo(provider.PackageName, provider.Version, time.Now())
+			}
+		}
+	}
+	
+	if s.endpointEnabled(EndpointPlugins) {
+		plugins, err := s.getPlugins(ctx)
+		if err != nil {
+			errs.AddPartial(1, fmt.Errorf("failed to get plugins: %w", err))
+		} else {
+			s.mb.RecordPluginCount(int64(plugins.total), time.Now())
+			for _, plugin := range plugins.items {
+				s.mb.RecordPluginInfo(plugin.Name, plugin.Source, time.Now())
+			}
+		}
+	}
+	
+	if s.endpointEnabled(EndpointDatasets) {
+		err := s.scrapeOptional(CapabilityDatasets, func() error {
+			count, err := s.getDatasetCount(ctx)
+			if err != nil {
+				return err
+			}
+			s.mb.RecordDatasetCount(int64(count), time.Now())
+			return nil
+		})
+		if err != nil {
+			errs.AddPartial(1, fmt.Errorf("failed to get datasets: %w", err))
+		}
+	}
+	
+	if s.endpointEnabled(EndpointDAGWarnings) {
+		err := s.scrapeOptional(CapabilityDAGWarnings, func() error {
+			warnings, err := s.getDAGWarnings(ctx, SignalMetrics)
+			if err != nil {
+				return err
+			}
+			for warningType, count := range countDAGWarnings(warnings) {
+				s.mb.RecordDAGWarningCount(count, warningType, time.Now())
+			}
+			return nil
+		})
+		if err != nil {
+			errs.AddPartial(1, fmt.Errorf("failed to get DAG warnings: %w", err))
+		}
 	}
 }
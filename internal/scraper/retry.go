@@ -5,6 +5,7 @@ package scraper
 
 import (
 	"context"
+	"errors"
 	"math"
 	"time"
 
@@ -27,7 +28,22 @@ func DefaultRetryConfig() RetryConfig {
 	}
 }
 
-// RetryWithBackoff executes a function with exponential backoff retry logic
+// permanentError marks an error that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so RetryWithBackoff returns it without further attempts
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// RetryWithBackoff executes a function with exponential backoff retry logic.
+// Errors wrapped with Permanent are returned immediately, unwrapped.
 func RetryWithBackoff(ctx context.Context, cfg RetryConfig, logger *zap.Logger, operation string, fn func() error) error {
 	var lastErr error
 	
@@ -53,6 +69,10 @@ func RetryWithBackoff(ctx context.Context, cfg RetryConfig, logger *zap.Logger,
 		}
 		
 		lastErr = fn()
+		var permanent *permanentError
+		if errors.As(lastErr, &permanent) {
+			return permanent.err
+		}
 		if lastErr == nil {
 			if attempt > 0 {
 				logger.Info("Operation succeeded after retry",
@@ -0,0 +1,211 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const webSessionCookie = "session"
+
+// csrfTokenPattern finds the hidden CSRF field in the Flask-AppBuilder login form
+var csrfTokenPattern = regexp.MustCompile(`name="csrf_token"[^>]*value="([^"]+)"`)
+
+// webSession is a logged-in webserver session
+type webSession struct {
+	// host overrides the request host when the login flow decides which
+	// webserver to talk to
+	host      string
+	cookies   []*http.Cookie
+	csrfToken string
+}
+
+// sessionTransport attaches a webserver session to every request, logging in
+// when there is no session, when it is older than lifetime, or when the
+// webserver rejects it
+type sessionTransport struct {
+	base     http.RoundTripper
+	login    func(ctx context.Context) (*webSession, error)
+	lifetime time.Duration
+
+	mu      sync.Mutex
+	current *webSession
+	expires time.Time
+}
+
+func newSessionTransport(base http.RoundTripper, lifetime time.Duration, login func(ctx context.Context) (*webSession, error)) *sessionTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &sessionTransport{base: base, login: login, lifetime: lifetime}
+}
+
+func (t *sessionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	session, err := t.session(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.send(req, session)
+	if err != nil || (resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusForbidden) {
+		return resp, err
+	}
+
+	// The session was revoked or expired early; log in once more before
+	// letting the caller see the rejection
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	t.invalidate(session)
+
+	session, err = t.session(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	return t.send(req, session)
+}
+
+// send attaches the session without modifying the caller's request
+func (t *sessionTransport) send(req *http.Request, session *webSession) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	if session.host != "" {
+		out.URL.Scheme = "https"
+		out.URL.Host = session.host
+		out.Host = session.host
+	}
+	out.Header.Del("Authorization")
+	for _, cookie := range session.cookies {
+		out.AddCookie(cookie)
+	}
+	if session.csrfToken != "" {
+		out.Header.Set("X-CSRFToken", session.csrfToken)
+	}
+	return t.base.RoundTrip(out)
+}
+
+func (t *sessionTransport) invalidate(session *webSession) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current == session {
+		t.current = nil
+	}
+}
+
+func (t *sessionTransport) session(ctx context.Context) (*webSession, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.current != nil && (t.lifetime <= 0 || time.Now().Before(t.expires)) {
+		return t.current, nil
+	}
+
+	session, err := t.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.current = session
+	t.expires = time.Now().Add(t.lifetime)
+	return session, nil
+}
+
+// noRedirectClient returns a client that reports redirects instead of
+// following them, since login flows set their cookies on the redirect
+func noRedirectClient(base http.RoundTripper, jar http.CookieJar) *http.Client {
+	return &http.Client{
+		Transport: base,
+		Jar:       jar,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// newLoginTransport logs in through the webserver's /login form, for
+// deployments that disable basic auth on the API but accept session cookies
+func newLoginTransport(base http.RoundTripper, endpoint, username, password string) *sessionTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return newSessionTransport(base, 0, func(ctx context.Context) (*webSession, error) {
+		return formLogin(ctx, base, endpoint, username, password)
+	})
+}
+
+func formLogin(ctx context.Context, base http.RoundTripper, endpoint, username, password string) (*webSession, error) {
+	loginURL, err := url.Parse(strings.TrimRight(endpoint, "/") + "/login/")
+	if err != nil {
+		return nil, err
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	client := noRedirectClient(base, jar)
+
+	// The login page issues the pre-login session and the CSRF token bound to it
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, loginURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	page, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("login page returned %d", resp.StatusCode)
+	}
+	match := csrfTokenPattern.FindSubmatch(page)
+	if match == nil {
+		return nil, fmt.Errorf("login page at %s has no CSRF token", loginURL)
+	}
+	csrfToken := string(match[1])
+
+	form := url.Values{
+		"username":   {username},
+		"password":   {password},
+		"csrf_token": {csrfToken},
+	}
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, loginURL.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err = client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	// A successful login redirects to the UI; a rejected one redirects back
+	// to the login form
+	location := resp.Header.Get("Location")
+	if resp.StatusCode != http.StatusFound && resp.StatusCode != http.StatusSeeOther {
+		return nil, fmt.Errorf("login returned %d", resp.StatusCode)
+	}
+	if strings.Contains(location, "/login") {
+		return nil, fmt.Errorf("%w: webserver rejected the login for %s", ErrAuthFailed, username)
+	}
+
+	cookies := jar.Cookies(loginURL)
+	for _, cookie := range cookies {
+		if cookie.Name == webSessionCookie && cookie.Value != "" {
+			return &webSession{cookies: cookies, csrfToken: csrfToken}, nil
+		}
+	}
+	return nil, fmt.Errorf("login at %s did not return a session cookie", loginURL)
+}
@@ -0,0 +1,474 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/scraper/scrapererror"
+	"go.uber.org/zap"
+)
+
+// How state changes reach the receiver
+const (
+	// StateChangeModeNotify listens to the NOTIFY of triggers
+	StateChangeModeNotify = "notify"
+	// StateChangeModeLogicalReplication streams the tables' changes from a
+	// publication through a replication slot
+	StateChangeModeLogicalReplication = "logical_replication"
+)
+
+// Default publication and replication slot of the logical replication mode
+const (
+	DefaultStatePublication = "airflow_state_changes"
+	DefaultStateSlot        = "otel_airflow_state_changes"
+)
+
+// cdcStatusInterval is how often the confirmed position is reported, well
+// below the server's default wal_sender_timeout of 60s
+const cdcStatusInterval = 10 * time.Second
+
+// maxStateRows bounds the last known states kept for tables without
+// REPLICA IDENTITY FULL
+const maxStateRows = 100000
+
+// stateRowKey identifies a task_instance or dag_run row
+type stateRowKey struct {
+	table    string
+	dagID    string
+	runID    string
+	taskID   string
+	mapIndex int
+}
+
+// StateCDCConfig configures the logical replication state change source
+type StateCDCConfig struct {
+	PostgresConnConfig
+	// Publication must publish inserts and updates of task_instance and
+	// dag_run
+	Publication string
+	// Slot is created when it does not exist
+	Slot string
+	// TemporarySlot creates the slot for each session, so the server keeps no
+	// WAL for the receiver while it is down, at the cost of the changes made
+	// meanwhile
+	TemporarySlot bool
+	// Redactor rewrites sensitive attribute values
+	Redactor *Redactor
+}
+
+// StateCDCListener streams task instance and DAG run state changes through
+// logical replication with the pgoutput plugin. The slot only moves past a
+// change once its records were accepted by the pipeline, so changes made
+// while the receiver was down are delivered on restart.
+type StateCDCListener struct {
+	cfg      *StateCDCConfig
+	settings receiver.Settings
+
+	mu        sync.Mutex
+	buffer    []stateChange
+	streamErr error
+	// readLSN is the position up to which every change is buffered, pending
+	// or delivered; pendingLSN was readLSN when pending was filled
+	readLSN    lsn
+	pendingLSN lsn
+	// flushLSN is confirmed to the server, which keeps the WAL after it
+	flushLSN lsn
+	pending  []stateChange
+	// cancel stops the streaming goroutine, done is closed once it returned
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// Decoding state, only used by the streaming goroutine
+	relations map[uint32]*pgoutputRelationInfo
+	inTxn     bool
+	txnTime   time.Time
+	txn       []stateChange
+	// states are the last states seen, for updates without the old row
+	states map[stateRowKey]string
+}
+
+func NewStateCDCListener(cfg *StateCDCConfig, settings receiver.Settings) *StateCDCListener {
+	return &StateCDCListener{
+		cfg:      cfg,
+		settings: settings,
+		states:   make(map[stateRowKey]string),
+	}
+}
+
+func (l *StateCDCListener) Start(ctx context.Context, _ component.Host) error {
+	if err := l.stream(ctx); err != nil {
+		return err
+	}
+	l.settings.Logger.Info("Streaming Airflow state changes",
+		zap.String("host", l.cfg.Host),
+		zap.String("database", l.cfg.Database),
+		zap.String("publication", l.cfg.Publication),
+		zap.String("slot", l.cfg.Slot),
+		zap.Bool("temporary_slot", l.cfg.TemporarySlot))
+	return nil
+}
+
+// stream opens a replication session, starts replicating from the confirmed
+// position and decodes the stream in the background
+func (l *StateCDCListener) stream(ctx context.Context) error {
+	conn, err := l.cfg.ConnectReplication(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open replication session: %w", err)
+	}
+	if err := l.startReplication(ctx, conn); err != nil {
+		conn.Close(context.Background())
+		return err
+	}
+
+	streamCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	l.cancel, l.done = cancel, done
+	go func() {
+		defer close(done)
+		err := l.receive(streamCtx, conn)
+
+		// Report what was delivered before leaving
+		closeCtx, closeCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer closeCancel()
+		l.sendStatus(conn)
+		conn.Close(closeCtx)
+
+		if streamCtx.Err() == nil {
+			l.mu.Lock()
+			l.streamErr = err
+			l.mu.Unlock()
+		}
+	}()
+	return nil
+}
+
+func (l *StateCDCListener) startReplication(ctx context.Context, conn *pgconn.PgConn) error {
+	results, err := conn.Exec(ctx, fmt.Sprintf(
+		`SELECT 1 FROM pg_publication WHERE pubname = '%s'`, l.cfg.Publication)).ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to look up publication: %w", err)
+	}
+	if len(results) == 0 || len(results[0].Rows) == 0 {
+		return fmt.Errorf("publication %q does not exist", l.cfg.Publication)
+	}
+
+	create := l.cfg.TemporarySlot
+	if !create {
+		results, err := conn.Exec(ctx, fmt.Sprintf(
+			`SELECT 1 FROM pg_replication_slots WHERE slot_name = '%s'`, l.cfg.Slot)).ReadAll()
+		if err != nil {
+			return fmt.Errorf("failed to look up replication slot: %w", err)
+		}
+		create = len(results) == 0 || len(results[0].Rows) == 0
+	}
+	if create {
+		temporary := ""
+		if l.cfg.TemporarySlot {
+			temporary = " TEMPORARY"
+		}
+		_, err := conn.Exec(ctx, fmt.Sprintf(`CREATE_REPLICATION_SLOT %s%s LOGICAL pgoutput`,
+			l.cfg.Slot, temporary)).ReadAll()
+		if err != nil {
+			return fmt.Errorf("failed to create replication slot %q: %w", l.cfg.Slot, err)
+		}
+		l.settings.Logger.Info("Created replication slot", zap.String("slot", l.cfg.Slot))
+	}
+
+	// The server resumes from the slot's confirmed position, or from the one
+	// given when further along. Changes already read are still held here, so
+	// a new session continues after them.
+	l.mu.Lock()
+	start := l.readLSN
+	l.mu.Unlock()
+	conn.Frontend().Send(&pgproto3.Query{String: fmt.Sprintf(
+		`START_REPLICATION SLOT %s LOGICAL %s (proto_version '1', publication_names '%s')`,
+		l.cfg.Slot, start, l.cfg.Publication)})
+	if err := conn.Frontend().Flush(); err != nil {
+		return fmt.Errorf("failed to start replication: %w", err)
+	}
+	for {
+		msg, err := conn.ReceiveMessage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to start replication: %w", err)
+		}
+		switch msg := msg.(type) {
+		case *pgproto3.CopyBothResponse:
+			l.relations = make(map[uint32]*pgoutputRelationInfo)
+			l.inTxn, l.txn = false, nil
+			return nil
+		case *pgproto3.ErrorResponse:
+			return fmt.Errorf("failed to start replication: %w", pgconn.ErrorResponseToPgError(msg))
+		}
+	}
+}
+
+// receive decodes the stream until ctx is done or the session fails. While
+// the buffer is full it stops reading, so the server holds the changes back.
+func (l *StateCDCListener) receive(ctx context.Context, conn *pgconn.PgConn) error {
+	nextStatus := time.Now()
+	for {
+		if !time.Now().Before(nextStatus) {
+			if err := l.sendStatus(conn); err != nil {
+				return err
+			}
+			nextStatus = time.Now().Add(cdcStatusInterval)
+		}
+
+		if l.full() {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		receiveCtx, cancel := context.WithDeadline(ctx, nextStatus)
+		msg, err := conn.ReceiveMessage(receiveCtx)
+		cancel()
+		if err != nil {
+			if pgconn.Timeout(err) && ctx.Err() == nil {
+				continue
+			}
+			return err
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.CopyData:
+			if len(msg.Data) == 0 {
+				continue
+			}
+			switch msg.Data[0] {
+			case msgPrimaryKeepalive:
+				keepalive, err := parsePrimaryKeepalive(msg.Data[1:])
+				if err != nil {
+					return err
+				}
+				if !l.inTxn {
+					l.advance(keepalive.walEnd)
+				}
+				if keepalive.replyRequested {
+					nextStatus = time.Now()
+				}
+			case msgXLogData:
+				xld, err := parseXLogData(msg.Data[1:])
+				if err != nil {
+					return err
+				}
+				if err := l.decode(xld.data); err != nil {
+					return fmt.Errorf("failed to decode change at %s: %w", xld.walStart, err)
+				}
+			}
+		case *pgproto3.ErrorResponse:
+			return pgconn.ErrorResponseToPgError(msg)
+		case *pgproto3.CopyDone:
+			return errors.New("server ended replication")
+		}
+	}
+}
+
+// decode handles one pgoutput message. Changes are held until their
+// transaction commits.
+func (l *StateCDCListener) decode(data []byte) error {
+	if len(data) == 0 {
+		return errShortMessage
+	}
+	r := &wireReader{buf: data[1:]}
+	switch data[0] {
+	case pgoutputBegin:
+		r.uint64() // final LSN
+		l.inTxn, l.txnTime, l.txn = true, r.time(), nil
+	case pgoutputCommit:
+		r.byte()   // flags
+		r.uint64() // commit LSN
+		end := lsn(r.uint64())
+		if r.err != nil {
+			return r.err
+		}
+		l.inTxn = false
+		if len(l.txn) == 0 {
+			l.advance(end)
+			return nil
+		}
+		l.mu.Lock()
+		l.buffer = append(l.buffer, l.txn...)
+		l.readLSN = max(l.readLSN, end)
+		l.mu.Unlock()
+		l.txn = nil
+	case pgoutputRelation:
+		id, rel := parsePgoutputRelation(r)
+		if r.err == nil {
+			l.relations[id] = rel
+		}
+	case pgoutputInsert, pgoutputUpdate:
+		rel, ok := l.relations[r.uint32()]
+		if r.err != nil {
+			return r.err
+		}
+		if !ok {
+			return errors.New("change for unknown relation")
+		}
+		var old map[string]tupleValue
+		tag := r.byte()
+		if tag == 'K' || tag == 'O' {
+			// A key-only old row sends every other column, state included,
+			// as NULL, so only a full old row tells the previous state
+			if tuple := rel.parseTuple(r); tag == 'O' {
+				old = tuple
+			}
+			tag = r.byte()
+		}
+		if tag != 'N' {
+			return fmt.Errorf("unexpected tuple type %q", tag)
+		}
+		row := rel.parseTuple(r)
+		if r.err != nil {
+			return r.err
+		}
+		if rel.name == "task_instance" || rel.name == "dag_run" {
+			if change, ok := l.stateChange(rel.name, old, row); ok {
+				l.txn = append(l.txn, change)
+			}
+		}
+	}
+	return r.err
+}
+
+// stateChange turns a replicated row into a state change, unless its state
+// did not change. Only tables with REPLICA IDENTITY FULL send the old row;
+// for others, the last state seen is compared instead.
+func (l *StateCDCListener) stateChange(table string, old, row map[string]tupleValue) (stateChange, bool) {
+	state := row["state"]
+	if state.kind == 'u' {
+		return stateChange{}, false
+	}
+	change := stateChange{
+		Table:     table,
+		DAGID:     row["dag_id"].text,
+		RunID:     row["run_id"].text,
+		TaskID:    row["task_id"].text,
+		State:     state.text,
+		ChangedAt: l.txnTime,
+	}
+	key := stateRowKey{table: table, dagID: change.DAGID, runID: change.RunID, taskID: change.TaskID}
+	if v, err := strconv.Atoi(row["map_index"].text); err == nil {
+		change.MapIndex = &v
+		key.mapIndex = v
+	}
+	if v, err := strconv.Atoi(row["try_number"].text); err == nil {
+		change.TryNumber = &v
+	}
+
+	previous, known := l.states[key]
+	if oldState, ok := old["state"]; ok {
+		previous, known = oldState.text, true
+	}
+	if known && previous == change.State {
+		return stateChange{}, false
+	}
+	change.PreviousState = previous
+
+	if len(l.states) >= maxStateRows {
+		clear(l.states)
+	}
+	l.states[key] = change.State
+	return change, true
+}
+
+// advance moves the read position over changes that were not buffered,
+// confirming it right away when nothing is waiting for the pipeline
+func (l *StateCDCListener) advance(pos lsn) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.readLSN = max(l.readLSN, pos)
+	if len(l.buffer) == 0 && len(l.pending) == 0 {
+		l.flushLSN = max(l.flushLSN, l.readLSN)
+	}
+}
+
+func (l *StateCDCListener) full() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buffer)+len(l.pending) >= maxStateChanges
+}
+
+func (l *StateCDCListener) sendStatus(conn *pgconn.PgConn) error {
+	l.mu.Lock()
+	received, flushed := l.readLSN, l.flushLSN
+	l.mu.Unlock()
+	conn.Frontend().Send(&pgproto3.CopyData{Data: standbyStatusUpdate(received, flushed, time.Now())})
+	if err := conn.Frontend().Flush(); err != nil {
+		return fmt.Errorf("failed to send replication status: %w", err)
+	}
+	return nil
+}
+
+func (l *StateCDCListener) Scrape(ctx context.Context) (plog.Logs, error) {
+	lb := NewStateChangeLogsBuilder(l.settings)
+	lb.SetRedactor(l.cfg.Redactor)
+
+	l.mu.Lock()
+	l.pending = append(l.pending, l.buffer...)
+	l.buffer = nil
+	l.pendingLSN = l.readLSN
+	streamErr := l.streamErr
+	l.streamErr = nil
+	for _, change := range l.pending {
+		lb.RecordStateChange(change)
+	}
+	l.mu.Unlock()
+
+	// The slot keeps the changes after the confirmed position, so with a
+	// permanent slot a new session misses nothing
+	if l.stopped() {
+		if streamErr != nil {
+			l.settings.Logger.Warn("State change replication stopped, reconnecting", zap.Error(streamErr))
+		}
+		if err := l.stream(ctx); err != nil {
+			// A partial error keeps the records, which were read before the
+			// session ended, so they are still delivered and committed
+			return lb.Emit(), scrapererror.NewPartialScrapeError(errors.Join(streamErr, err), 0)
+		}
+	}
+	return lb.Emit(), nil
+}
+
+// stopped reports whether the streaming goroutine has returned
+func (l *StateCDCListener) stopped() bool {
+	select {
+	case <-l.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Commit confirms the changes of the last flush, so the server may discard
+// their WAL
+func (l *StateCDCListener) Commit() {
+	l.mu.Lock()
+	l.pending = nil
+	l.flushLSN = max(l.flushLSN, l.pendingLSN)
+	l.mu.Unlock()
+}
+
+func (l *StateCDCListener) Shutdown(_ context.Context) error {
+	if l.cancel != nil {
+		l.cancel()
+		<-l.done
+		l.cancel = nil
+	}
+	return nil
+}
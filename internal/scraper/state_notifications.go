@@ -0,0 +1,329 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/scraper/scrapererror"
+	"go.uber.org/zap"
+)
+
+// DefaultStateChannel is the NOTIFY channel of the state change triggers
+const DefaultStateChannel = "airflow_state_changes"
+
+// stateTriggerName names the triggers installed on task_instance and dag_run
+const stateTriggerName = "otel_airflow_state_notify"
+
+// stateFunctionName names the trigger function that sends the notifications
+const stateFunctionName = "otel_airflow_notify_state"
+
+// maxStateChanges bounds the notifications buffered between two flushes
+const maxStateChanges = 10000
+
+// stateTables are the tables whose state changes are notified
+var stateTables = []string{"task_instance", "dag_run"}
+
+// stateIdentifierPattern only admits channel, publication and slot names
+// that need no quoting in the statements they are inlined in
+var stateIdentifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// ValidateStateIdentifier checks that name is a plain lowercase identifier
+func ValidateStateIdentifier(name string) error {
+	if !stateIdentifierPattern.MatchString(name) {
+		return fmt.Errorf("invalid name %q: must be a lowercase identifier of at most 63 characters", name)
+	}
+	return nil
+}
+
+// StateTriggerSQL returns the statements that create the trigger function
+// for channel. The function sends one notification per new row and per state
+// change, holding the row's identity and its previous state.
+func StateTriggerSQL(channel string) string {
+	return fmt.Sprintf(`
+		CREATE OR REPLACE FUNCTION %[1]s() RETURNS trigger AS $$
+		DECLARE
+			rec jsonb := to_jsonb(NEW);
+		BEGIN
+			PERFORM pg_notify('%[2]s', jsonb_strip_nulls(jsonb_build_object(
+				'table', TG_TABLE_NAME,
+				'dag_id', rec->'dag_id',
+				'run_id', rec->'run_id',
+				'task_id', rec->'task_id',
+				'map_index', rec->'map_index',
+				'try_number', rec->'try_number',
+				'state', rec->'state',
+				'previous_state', CASE WHEN TG_OP = 'UPDATE' THEN to_jsonb(OLD)->'state' END,
+				'changed_at', clock_timestamp()
+			))::text);
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql
+	`, stateFunctionName, channel)
+}
+
+// stateTriggersSQL creates the triggers of a table. Updates only fire when
+// the state changes, so heartbeats and other updates cost nothing.
+func stateTriggersSQL(table string) []string {
+	return []string{
+		fmt.Sprintf(`CREATE TRIGGER %[1]s_insert AFTER INSERT ON %[2]s
+			FOR EACH ROW EXECUTE FUNCTION %[3]s()`, stateTriggerName, table, stateFunctionName),
+		fmt.Sprintf(`CREATE TRIGGER %[1]s_update AFTER UPDATE OF state ON %[2]s
+			FOR EACH ROW WHEN (OLD.state IS DISTINCT FROM NEW.state) EXECUTE FUNCTION %[3]s()`, stateTriggerName, table, stateFunctionName),
+	}
+}
+
+// stateChange is the payload of a state change notification
+type stateChange struct {
+	Table         string    `json:"table"`
+	DAGID         string    `json:"dag_id"`
+	RunID         string    `json:"run_id"`
+	TaskID        string    `json:"task_id"`
+	MapIndex      *int      `json:"map_index"`
+	TryNumber     *int      `json:"try_number"`
+	State         string    `json:"state"`
+	PreviousState string    `json:"previous_state"`
+	ChangedAt     time.Time `json:"changed_at"`
+}
+
+// StateChangeConfig configures the state change listener
+type StateChangeConfig struct {
+	PostgresConnConfig
+	// Channel is the NOTIFY channel listened on
+	Channel string
+	// InstallTrigger creates the trigger function and the triggers on
+	// startup; otherwise they are expected to exist already
+	InstallTrigger bool
+	// Redactor rewrites sensitive attribute values
+	Redactor *Redactor
+}
+
+// StateChangeListener turns the notifications of the state change triggers
+// into log records as they arrive, instead of waiting for the next poll.
+// Notifications are buffered between flushes; those sent while the listening
+// session is down are lost, so the database metrics remain the source of
+// truth.
+type StateChangeListener struct {
+	cfg      *StateChangeConfig
+	settings receiver.Settings
+	drops    *DropTracker
+	db       *sql.DB
+
+	mu        sync.Mutex
+	buffer    []string
+	listenErr error
+	// cancel stops the listening goroutine, done is closed once it returned
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// pending are the changes of the last flush, emitted again until
+	// committed
+	pending []stateChange
+}
+
+func NewStateChangeListener(cfg *StateChangeConfig, settings receiver.Settings, drops *DropTracker) *StateChangeListener {
+	return &StateChangeListener{
+		cfg:      cfg,
+		settings: settings,
+		drops:    drops,
+	}
+}
+
+func (l *StateChangeListener) Start(ctx context.Context, _ component.Host) error {
+	db, err := l.cfg.OpenDB(false)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	if l.cfg.InstallTrigger {
+		if err := l.installTriggers(ctx, db); err != nil {
+			db.Close()
+			return fmt.Errorf("failed to install state change triggers: %w", err)
+		}
+	}
+	l.db = db
+	if err := l.listen(ctx); err != nil {
+		l.db = nil
+		db.Close()
+		return err
+	}
+
+	l.settings.Logger.Info("Listening for Airflow state changes",
+		zap.String("host", l.cfg.Host),
+		zap.String("database", l.cfg.Database),
+		zap.String("channel", l.cfg.Channel),
+		zap.Bool("install_trigger", l.cfg.InstallTrigger))
+	return nil
+}
+
+// installTriggers replaces the trigger function and creates the triggers that
+// do not exist yet. Existing triggers are left alone, since recreating them
+// would lock the tables the scheduler writes to.
+func (l *StateChangeListener) installTriggers(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, StateTriggerSQL(l.cfg.Channel)); err != nil {
+		return err
+	}
+	for _, table := range stateTables {
+		var installed bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM pg_trigger
+				WHERE tgrelid = $1::regclass AND tgname LIKE $2
+			)
+		`, table, stateTriggerName+"%").Scan(&installed)
+		if err != nil {
+			return err
+		}
+		if installed {
+			continue
+		}
+		for _, stmt := range stateTriggersSQL(table) {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("%s: %w", table, err)
+			}
+		}
+		l.settings.Logger.Info("Installed state change triggers", zap.String("table", table))
+	}
+	return tx.Commit()
+}
+
+// listen pins a session, subscribes it to the channel and waits for
+// notifications in the background
+func (l *StateChangeListener) listen(ctx context.Context) error {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open listening session: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "LISTEN "+l.cfg.Channel); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to listen on %q: %w", l.cfg.Channel, err)
+	}
+
+	listenCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	l.cancel, l.done = cancel, done
+	go func() {
+		defer close(done)
+		defer conn.Close()
+		var waitErr error
+		conn.Raw(func(driverConn any) error {
+			pgxConn := driverConn.(*stdlib.Conn).Conn()
+			for {
+				notification, err := pgxConn.WaitForNotification(listenCtx)
+				if err != nil {
+					waitErr = err
+					// The session may be mid-message, so it is not reused
+					return driver.ErrBadConn
+				}
+				l.add(notification.Payload)
+			}
+		})
+		if listenCtx.Err() == nil {
+			l.mu.Lock()
+			l.listenErr = waitErr
+			l.mu.Unlock()
+		}
+	}()
+	return nil
+}
+
+// add buffers a payload, dropping it when the buffer is full
+func (l *StateChangeListener) add(payload string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.pending)+len(l.buffer) >= maxStateChanges {
+		l.drops.Record(SignalLogs, DropReasonTruncated, 1,
+			zap.String("source", "state_changes"), zap.Int("limit", maxStateChanges))
+		return
+	}
+	l.buffer = append(l.buffer, payload)
+}
+
+func (l *StateChangeListener) Scrape(ctx context.Context) (plog.Logs, error) {
+	lb := NewStateChangeLogsBuilder(l.settings)
+	lb.SetRedactor(l.cfg.Redactor)
+
+	l.mu.Lock()
+	payloads := l.buffer
+	l.buffer = nil
+	listenErr := l.listenErr
+	l.listenErr = nil
+	for _, payload := range payloads {
+		var change stateChange
+		if err := json.Unmarshal([]byte(payload), &change); err != nil {
+			l.drops.Record(SignalLogs, DropReasonParseError, 1,
+				zap.String("source", "state_changes"), zap.Error(err))
+			continue
+		}
+		l.pending = append(l.pending, change)
+	}
+	for _, change := range l.pending {
+		lb.RecordStateChange(change)
+	}
+	l.mu.Unlock()
+
+	// Notifications are only sent to live sessions, so listen again right
+	// away; the ones sent in between are lost
+	if l.stopped() {
+		if listenErr != nil {
+			l.settings.Logger.Warn("State change listener disconnected, listening again", zap.Error(listenErr))
+		}
+		if err := l.listen(ctx); err != nil {
+			// A partial error keeps the pending changes, so they are still
+			// delivered and committed
+			return lb.Emit(), scrapererror.NewPartialScrapeError(errors.Join(listenErr, err), 0)
+		}
+	}
+	return lb.Emit(), nil
+}
+
+// stopped reports whether the listening goroutine has returned
+func (l *StateChangeListener) stopped() bool {
+	select {
+	case <-l.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Commit forgets the notifications of the last flush
+func (l *StateChangeListener) Commit() {
+	l.mu.Lock()
+	l.pending = nil
+	l.mu.Unlock()
+}
+
+func (l *StateChangeListener) Shutdown(_ context.Context) error {
+	if l.cancel != nil {
+		l.cancel()
+		<-l.done
+		l.cancel = nil
+	}
+	if l.db != nil {
+		return l.db.Close()
+	}
+	return nil
+}
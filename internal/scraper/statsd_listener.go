@@ -0,0 +1,184 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"errors"
+	"hash/fnv"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	// DefaultStatsDReadBatchSize packets are read per recvmmsg call
+	DefaultStatsDReadBatchSize = 32
+	// statsDShardCount spreads series over independently locked maps
+	statsDShardCount = 16
+)
+
+// statsDShard holds the series whose keys hash to it
+type statsDShard struct {
+	mu      sync.Mutex
+	metrics map[string]*StatsDMetric
+}
+
+func newStatsDShards() []*statsDShard {
+	shards := make([]*statsDShard, statsDShardCount)
+	for i := range shards {
+		shards[i] = &statsDShard{metrics: make(map[string]*StatsDMetric)}
+	}
+	return shards
+}
+
+// resetSets starts a new interval for set cardinality
+func (sh *statsDShard) resetSets() {
+	for _, metric := range sh.metrics {
+		if metric.Type == "s" {
+			metric.Members = make(map[string]struct{})
+		}
+	}
+}
+
+// statsDSeriesKey identifies a series by name and tags, in a stable order so
+// the same series always lands in the same shard
+func statsDSeriesKey(metric *StatsDMetric) string {
+	if len(metric.Tags) == 0 {
+		return metric.Name
+	}
+	keys := make([]string, 0, len(metric.Tags))
+	for k := range metric.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(metric.Name)
+	for _, k := range keys {
+		b.WriteByte(',')
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(metric.Tags[k])
+	}
+	return b.String()
+}
+
+func (s *StatsDScraper) shardFor(key string) *statsDShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return s.shards[h.Sum32()%uint32(len(s.shards))]
+}
+
+func (s *StatsDScraper) parserWorkers() int {
+	if s.cfg.ParserWorkers <= 0 {
+		return 1
+	}
+	return s.cfg.ParserWorkers
+}
+
+func (s *StatsDScraper) readBatchSize() int {
+	if s.cfg.ReadBatchSize <= 0 {
+		return DefaultStatsDReadBatchSize
+	}
+	return s.cfg.ReadBatchSize
+}
+
+func (s *StatsDScraper) readBufferSize() int {
+	if s.cfg.ReadBufferSize <= 0 {
+		return DefaultStatsDReadBufferSize
+	}
+	return s.cfg.ReadBufferSize
+}
+
+// startParsers runs the workers that parse and aggregate received packets
+// until the listener closes the packet channel
+func (s *StatsDScraper) startParsers() {
+	for i := 0; i < s.parserWorkers(); i++ {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			for packet := range s.packets {
+				s.parseAndAggregate(string(packet))
+			}
+		}()
+	}
+}
+
+// listen reads packets until the connection is closed. UDP sockets are read
+// in batches with recvmmsg where the platform supports it.
+func (s *StatsDScraper) listen() {
+	defer s.wg.Done()
+	defer close(s.packets)
+
+	var err error
+	switch s.transport() {
+	case "unixgram":
+		err = s.readPackets()
+	case "udp6":
+		err = s.readBatches(ipv6.NewPacketConn(s.conn).ReadBatch)
+	default:
+		err = s.readBatches(ipv4.NewPacketConn(s.conn).ReadBatch)
+	}
+	if err != nil && !errors.Is(err, net.ErrClosed) {
+		s.settings.Logger.Error("StatsD listener stopped", zap.Error(err))
+	}
+}
+
+// readBatches fills a batch of buffers per call. ipv4.Message and
+// ipv6.Message are the same type, so both packet conns fit readBatch.
+func (s *StatsDScraper) readBatches(readBatch func([]ipv4.Message, int) (int, error)) error {
+	batch := make([]ipv4.Message, s.readBatchSize())
+	for i := range batch {
+		batch[i].Buffers = [][]byte{make([]byte, s.readBufferSize())}
+	}
+	for {
+		n, err := readBatch(batch, 0)
+		if err != nil {
+			if s.stopping() || errors.Is(err, net.ErrClosed) {
+				return net.ErrClosed
+			}
+			s.settings.Logger.Error("Error reading StatsD packets", zap.Error(err))
+			continue
+		}
+		for _, msg := range batch[:n] {
+			s.dispatch(msg.Buffers[0][:msg.N])
+		}
+	}
+}
+
+func (s *StatsDScraper) readPackets() error {
+	buf := make([]byte, s.readBufferSize())
+	for {
+		n, _, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			if s.stopping() || errors.Is(err, net.ErrClosed) {
+				return net.ErrClosed
+			}
+			s.settings.Logger.Error("Error reading StatsD packet", zap.Error(err))
+			continue
+		}
+		s.dispatch(buf[:n])
+	}
+}
+
+// dispatch hands a copy of the packet to the parsers. The send blocks when
+// they fall behind, leaving further packets queued in the socket buffer.
+func (s *StatsDScraper) dispatch(packet []byte) {
+	s.packetsReceived.Add(1)
+	s.packets <- append([]byte(nil), packet...)
+}
+
+func (s *StatsDScraper) stopping() bool {
+	select {
+	case <-s.stopChan:
+		return true
+	default:
+		return false
+	}
+}
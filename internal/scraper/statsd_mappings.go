@@ -0,0 +1,169 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"strings"
+)
+
+// DefaultStatsDPrefix is Airflow's default [metrics] statsd_prefix
+const DefaultStatsDPrefix = "airflow"
+
+// statsDMapping turns a well-known Airflow StatsD metric into a named metric
+// with a proper unit, instead of the generic passthrough
+type statsDMapping struct {
+	name        string
+	unit        string
+	description string
+	// scale converts the StatsD value into unit, e.g. 0.001 for ms to s
+	scale float64
+	// suffixAttribute receives the rest of the name for metrics that Airflow
+	// also emits with a trailing qualifier, e.g. executor.open_slots.CeleryExecutor
+	suffixAttribute string
+	// gaugeMerge is how gauge points combine when attributes are removed;
+	// empty keeps the last point
+	gaugeMerge string
+}
+
+// statsDMappings is keyed by the StatsD name without the prefix
+var statsDMappings = map[string]statsDMapping{
+	"scheduler.scheduler_loop_duration": {
+		name:        "airflow.scheduler.loop.duration",
+		unit:        "s",
+		description: "Time taken by one scheduler loop",
+		scale:       0.001,
+	},
+	"scheduler.critical_section_busy": {
+		name:        "airflow.scheduler.critical_section.busy",
+		unit:        "{occurrences}",
+		description: "Times a scheduler tried to enter the critical section while another scheduler held the lock",
+		scale:       1,
+	},
+	"scheduler.critical_section_duration": {
+		name:        "airflow.scheduler.critical_section.duration",
+		unit:        "s",
+		description: "Time spent in the scheduler critical section, which holds the pool lock",
+		scale:       0.001,
+	},
+	"executor.open_slots": {
+		name:            "airflow.executor.slots.open",
+		unit:            "{slots}",
+		description:     "Open slots on the executor",
+		scale:           1,
+		suffixAttribute: "executor.name",
+		gaugeMerge:      GaugeMergeSum,
+	},
+	"executor.queued_tasks": {
+		name:            "airflow.executor.tasks.queued",
+		unit:            "{tasks}",
+		description:     "Tasks queued on the executor",
+		scale:           1,
+		suffixAttribute: "executor.name",
+		gaugeMerge:      GaugeMergeSum,
+	},
+	"executor.running_tasks": {
+		name:            "airflow.executor.tasks.running",
+		unit:            "{tasks}",
+		description:     "Tasks running on the executor",
+		scale:           1,
+		suffixAttribute: "executor.name",
+		gaugeMerge:      GaugeMergeSum,
+	},
+	"dagbag_size": {
+		name:        "airflow.dag_processing.dagbag.size",
+		unit:        "{dags}",
+		description: "DAGs found by the DAG processor in its last scan",
+		scale:       1,
+		gaugeMerge:  GaugeMergeSum,
+	},
+	"dag_processing.total_parse_time": {
+		name:        "airflow.dag_processing.total_parse_time",
+		unit:        "s",
+		description: "Time taken to scan and parse all DAG files once",
+		scale:       1,
+	},
+	"dag_processing.import_errors": {
+		name:        "airflow.dag_processing.import_errors",
+		unit:        "{errors}",
+		description: "DAG files that failed to import in the last scan",
+		scale:       1,
+		gaugeMerge:  GaugeMergeSum,
+	},
+	"dag_processing.last_duration": {
+		name:            "airflow.dag_processing.file.parse.duration",
+		unit:            "s",
+		description:     "Time taken to parse a DAG file",
+		scale:           0.001,
+		suffixAttribute: "dag.file",
+	},
+	"dag_processing.last_run.seconds_ago": {
+		name:            "airflow.dag_processing.file.parse.age",
+		unit:            "s",
+		description:     "Time since a DAG file was last parsed",
+		scale:           1,
+		suffixAttribute: "dag.file",
+	},
+}
+
+// lookupStatsDMapping finds the mapping for a received metric name, which
+// carries Airflow's statsd_prefix. suffix is the qualifier after the mapped
+// name, if any.
+func lookupStatsDMapping(prefix, name string) (mapping statsDMapping, suffix string, ok bool) {
+	if prefix != "" {
+		trimmed, found := strings.CutPrefix(name, prefix+".")
+		if !found {
+			return statsDMapping{}, "", false
+		}
+		name = trimmed
+	}
+	if mapping, ok := statsDMappings[name]; ok {
+		return mapping, "", true
+	}
+
+	// Qualified names are split at each dot, longest known name first
+	for i := strings.LastIndexByte(name, '.'); i > 0; i = strings.LastIndexByte(name[:i], '.') {
+		if mapping, ok := statsDMappings[name[:i]]; ok && mapping.suffixAttribute != "" {
+			return mapping, name[i+1:], true
+		}
+	}
+	return statsDMapping{}, "", false
+}
+
+// passthroughStatsDName applies the rename rules and prefix stripping to a
+// metric without a named mapping. A rule matches the name without the prefix
+// exactly or as a leading dotted segment, in which case the rest of the name
+// is kept, and the longest matching rule wins.
+func passthroughStatsDName(cfg *StatsDConfig, name string) string {
+	stripped := name
+	if cfg.Prefix != "" {
+		trimmed, found := strings.CutPrefix(name, cfg.Prefix+".")
+		if !found {
+			return name
+		}
+		stripped = trimmed
+	}
+
+	for i := len(stripped); i > 0; i = strings.LastIndexByte(stripped[:i], '.') {
+		if renamed, ok := cfg.Rename[stripped[:i]]; ok {
+			return renamed + stripped[i:]
+		}
+	}
+	if cfg.StripPrefix {
+		return stripped
+	}
+	return name
+}
+
+// tags returns the metric's tags plus the qualifier attribute
+func (m statsDMapping) tags(tags map[string]string, suffix string) map[string]string {
+	if suffix == "" {
+		return tags
+	}
+	out := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		out[k] = v
+	}
+	out[m.suffixAttribute] = suffix
+	return out
+}
@@ -6,10 +6,13 @@ package scraper
 import (
 	"context"
 	"fmt"
+	"math"
 	"net"
+	"os"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
@@ -21,89 +24,169 @@ import (
 // StatsDConfig for scraper
 type StatsDConfig struct {
 	Endpoint            string
+	Transport           string
 	AggregationInterval time.Duration
+	// Prefix is Airflow's statsd_prefix, stripped before matching well-known
+	// metric names
+	Prefix string
+	// StripPrefix also removes Prefix from passed-through metric names
+	StripPrefix bool
+	// Rename maps passed-through names, without the prefix, to new names
+	Rename map[string]string
+	// ReadBufferSize bounds a single packet, DefaultStatsDReadBufferSize when 0
+	ReadBufferSize int
+	// SocketBufferSize sets the kernel receive buffer (SO_RCVBUF) when positive
+	SocketBufferSize int
+	// ReadBatchSize is the number of UDP packets read per system call
+	ReadBatchSize int
+	// ParserWorkers parse packets and aggregate them concurrently
+	ParserWorkers int
+	// DisabledAttributes are dropped from every data point, tags included
+	DisabledAttributes []string
+	// Redactor rewrites sensitive attribute values, tags included
+	Redactor *Redactor
+	// ResourcePerDAG reports points tagged with a dag.id on a resource per DAG
+	ResourcePerDAG bool
 }
 
+// DefaultStatsDReadBufferSize fits the largest UDP payload
+const DefaultStatsDReadBufferSize = 65535
+
 // StatsDMetric represents an aggregated StatsD metric
 type StatsDMetric struct {
 	Name       string
 	Value      float64
-	Type       string // counter, gauge, timer
+	Type       string // counter, gauge, timer (ms, h, d), set
+	// RawValue is a set member, which need not be numeric
+	RawValue   string
 	SampleRate float64
 	Tags       map[string]string
-	Count      int64
+	// Count and Sum of timers are weighted by 1/SampleRate, so they estimate
+	// what an unsampled client would have sent
+	Count      float64
 	Sum        float64
 	Min        float64
 	Max        float64
+	// StartTime is when the series was first observed, the start of its
+	// cumulative counter
+	StartTime  time.Time
+	// Members holds the unique values a set received this interval
+	Members    map[string]struct{}
 }
 
 type StatsDScraper struct {
 	cfg      *StatsDConfig
 	settings receiver.Settings
-	conn     *net.UDPConn
+	conn     net.PacketConn
 	mb       *MetricsBuilder
+	drops    *DropTracker
+	
+	// Series are spread over shards so parser workers rarely contend
+	shards  []*statsDShard
+	packets chan []byte
 	
-	mu      sync.RWMutex
-	metrics map[string]*StatsDMetric
+	// Listener counters since startTime, so a quiet Airflow can be told
+	// apart from packets the receiver cannot use
+	startTime        time.Time
+	packetsReceived  atomic.Int64
+	linesParsed      atomic.Int64
+	parseErrors      atomic.Int64
+	unsupportedTypes atomic.Int64
 	
 	stopChan chan struct{}
 	wg       sync.WaitGroup
 }
 
-func NewStatsDScraper(cfg *StatsDConfig, settings receiver.Settings) *StatsDScraper {
+func NewStatsDScraper(cfg *StatsDConfig, settings receiver.Settings, drops *DropTracker) *StatsDScraper {
+	mb := NewMetricsBuilder(settings)
+	mb.SetDisabledAttributes(cfg.DisabledAttributes)
+	mb.SetDropTracker(drops)
+	mb.SetRedactor(cfg.Redactor)
+	mb.SetResourcePerDAG(cfg.ResourcePerDAG)
 	return &StatsDScraper{
 		cfg:      cfg,
 		settings: settings,
-		mb:       NewMetricsBuilder(),
-		metrics:  make(map[string]*StatsDMetric),
+		mb:       mb,
+		drops:    drops,
+		shards:   newStatsDShards(),
 		stopChan: make(chan struct{}),
+		
+		startTime: time.Now(),
 	}
 }
 
 func (s *StatsDScraper) Start(ctx context.Context, host component.Host) error {
 	s.settings.Logger.Info("Starting StatsD scraper", 
 		zap.String("endpoint", s.cfg.Endpoint),
+		zap.String("transport", s.transport()),
 		zap.Duration("aggregation_interval", s.cfg.AggregationInterval))
 	
-	addr, err := net.ResolveUDPAddr("udp", s.cfg.Endpoint)
-	if err != nil {
-		return fmt.Errorf("failed to resolve UDP address: %w", err)
+	var conn net.PacketConn
+	var err error
+	if s.transport() == "unixgram" {
+		conn, err = s.listenUnixgram()
+	} else {
+		conn, err = s.listenUDP()
 	}
-	
-	conn, err := net.ListenUDP("udp", addr)
 	if err != nil {
-		return fmt.Errorf("failed to listen on UDP: %w", err)
+		return err
+	}
+	if s.cfg.SocketBufferSize > 0 {
+		if buffered, ok := conn.(interface{ SetReadBuffer(int) error }); ok {
+			if err := buffered.SetReadBuffer(s.cfg.SocketBufferSize); err != nil {
+				conn.Close()
+				return fmt.Errorf("failed to set socket buffer size: %w", err)
+			}
+		}
 	}
 	
 	s.conn = conn
+	s.packets = make(chan []byte, s.parserWorkers()*s.readBatchSize())
 	s.wg.Add(1)
 	go s.listen()
+	s.startParsers()
 	
 	s.settings.Logger.Info("StatsD receiver started successfully")
 	return nil
 }
 
-func (s *StatsDScraper) listen() {
-	defer s.wg.Done()
-	buf := make([]byte, 65535)
-	
-	for {
-		select {
-		case <-s.stopChan:
-			return
-		default:
-			s.conn.SetReadDeadline(time.Now().Add(1 * time.Second))
-			n, _, err := s.conn.ReadFromUDP(buf)
-			if err != nil {
-				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-					continue
-				}
-				s.settings.Logger.Error("Error reading from UDP", zap.Error(err))
-				continue
-			}
-			s.parseAndAggregate(string(buf[:n]))
+func (s *StatsDScraper) transport() string {
+	if s.cfg.Transport == "" {
+		return "udp"
+	}
+	return s.cfg.Transport
+}
+
+func (s *StatsDScraper) listenUDP() (net.PacketConn, error) {
+	addr, err := net.ResolveUDPAddr(s.transport(), s.cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve UDP address: %w", err)
+	}
+	
+	conn, err := net.ListenUDP(s.transport(), addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on UDP: %w", err)
+	}
+	return conn, nil
+}
+
+// listenUnixgram binds a datagram socket at the endpoint path, replacing a
+// stale socket left behind by a previous run
+func (s *StatsDScraper) listenUnixgram() (net.PacketConn, error) {
+	if info, err := os.Stat(s.cfg.Endpoint); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("statsd endpoint %s exists and is not a socket", s.cfg.Endpoint)
 		}
+		if err := os.Remove(s.cfg.Endpoint); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+	
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: s.cfg.Endpoint, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on unix socket: %w", err)
 	}
+	return conn, nil
 }
 
 func (s *StatsDScraper) parseAndAggregate(data string) {
@@ -113,9 +196,19 @@ func (s *StatsDScraper) parseAndAggregate(data string) {
 			continue
 		}
 		metric := s.parseStatsDLine(line)
-		if metric != nil {
-			s.aggregate(metric)
+		if metric == nil {
+			s.parseErrors.Add(1)
+			s.drops.Record(SignalMetrics, DropReasonParseError, 1, zap.String("line", line))
+			continue
+		}
+		if !isSupportedStatsDType(metric.Type) {
+			s.unsupportedTypes.Add(1)
+			s.drops.Record(SignalMetrics, DropReasonUnsupportedType, 1,
+				zap.String("name", metric.Name), zap.String("type", metric.Type))
+			continue
 		}
+		s.linesParsed.Add(1)
+		s.aggregate(metric)
 	}
 }
 
@@ -130,14 +223,20 @@ func (s *StatsDScraper) parseStatsDLine(line string) *StatsDMetric {
 		return nil
 	}
 	
-	value, err := strconv.ParseFloat(nameValue[1], 64)
-	if err != nil {
-		return nil
+	// Set members are counted, not parsed
+	var value float64
+	if parts[1] != "s" {
+		var err error
+		value, err = strconv.ParseFloat(nameValue[1], 64)
+		if err != nil {
+			return nil
+		}
 	}
 	
 	metric := &StatsDMetric{
 		Name:       nameValue[0],
 		Value:      value,
+		RawValue:   nameValue[1],
 		Type:       parts[1],
 		SampleRate: 1.0,
 		Tags:       make(map[string]string),
@@ -162,69 +261,132 @@ func (s *StatsDScraper) parseStatsDLine(line string) *StatsDMetric {
 	return metric
 }
 
-func (s *StatsDScraper) aggregate(metric *StatsDMetric) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	
-	key := metric.Name
-	for k, v := range metric.Tags {
-		key += fmt.Sprintf(",%s=%s", k, v)
+func isSupportedStatsDType(metricType string) bool {
+	switch metricType {
+	case "c", "g", "ms", "h", "d", "s":
+		return true
 	}
+	return false
+}
+
+func (s *StatsDScraper) aggregate(metric *StatsDMetric) {
+	key := statsDSeriesKey(metric)
+	shard := s.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
 	
-	existing, exists := s.metrics[key]
+	existing, exists := shard.metrics[key]
 	if !exists {
-		s.metrics[key] = &StatsDMetric{
-			Name:  metric.Name,
-			Type:  metric.Type,
-			Tags:  metric.Tags,
-			Value: metric.Value,
-			Count: 1,
-			Sum:   metric.Value,
-			Min:   metric.Value,
-			Max:   metric.Value,
+		existing = &StatsDMetric{
+			Name: metric.Name,
+			Type: metric.Type,
+			Tags: metric.Tags,
+			Min:  metric.Value,
+			Max:  metric.Value,
+			
+			StartTime: time.Now(),
 		}
-		return
+		if metric.Type == "s" {
+			existing.Members = make(map[string]struct{})
+		}
+		shard.metrics[key] = existing
 	}
 	
+	// A sample sent at rate 0.1 stands for ten events
+	weight := sampleWeight(metric.SampleRate)
 	switch metric.Type {
 	case "c":
-		existing.Value += metric.Value / metric.SampleRate
+		existing.Value += metric.Value * weight
 	case "g":
 		existing.Value = metric.Value
-	case "ms", "h":
-		existing.Count++
-		existing.Sum += metric.Value
+	case "ms", "h", "d":
+		existing.Count += weight
+		existing.Sum += metric.Value * weight
 		if metric.Value < existing.Min {
 			existing.Min = metric.Value
 		}
 		if metric.Value > existing.Max {
 			existing.Max = metric.Value
 		}
+	case "s":
+		existing.Members[metric.RawValue] = struct{}{}
 	}
 }
 
+// sampleWeight is the number of events a sample represents. Rates outside
+// (0, 1] are treated as unsampled.
+func sampleWeight(rate float64) float64 {
+	if rate <= 0 || rate > 1 {
+		return 1
+	}
+	return 1 / rate
+}
+
 func (s *StatsDScraper) Scrape(ctx context.Context) (pmetric.Metrics, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	
-	for _, metric := range s.metrics {
-		switch metric.Type {
-		case "c":
-			s.mb.RecordGenericCounter(int64(metric.Value), metric.Name, metric.Tags, time.Now())
-		case "g":
-			s.mb.RecordGenericGauge(metric.Value, metric.Name, metric.Tags, time.Now())
-		case "ms", "h":
-			if metric.Count > 0 {
-				avg := metric.Sum / float64(metric.Count)
-				s.mb.RecordGenericTimer(avg, metric.Min, metric.Max, metric.Name, metric.Tags, time.Now())
-			}
+	series := 0
+	for _, shard := range s.shards {
+		shard.mu.Lock()
+		series += len(shard.metrics)
+		for _, metric := range shard.metrics {
+			s.record(metric)
 		}
+		shard.resetSets()
+		shard.mu.Unlock()
 	}
 	
-	s.settings.Logger.Debug("Scraped StatsD metrics", zap.Int("metric_count", len(s.metrics)))
+	s.mb.RecordReceiverStatsDListener(s.packetsReceived.Load(), map[string]int64{
+		"parsed":                  s.linesParsed.Load(),
+		DropReasonParseError:      s.parseErrors.Load(),
+		DropReasonUnsupportedType: s.unsupportedTypes.Load(),
+	}, s.startTime, time.Now())
+	
+	s.settings.Logger.Debug("Scraped StatsD metrics", zap.Int("metric_count", series))
 	return s.mb.Emit(), nil
 }
 
+// record emits one aggregated series
+func (s *StatsDScraper) record(metric *StatsDMetric) {
+	if mapping, suffix, ok := lookupStatsDMapping(s.cfg.Prefix, metric.Name); ok {
+		s.recordMapped(metric, mapping, mapping.tags(metric.Tags, suffix))
+		return
+	}
+	
+	name := passthroughStatsDName(s.cfg, metric.Name)
+	switch metric.Type {
+	case "c":
+		s.mb.RecordGenericCounter(int64(math.Round(metric.Value)), name, metric.Tags, metric.StartTime, time.Now())
+	case "g":
+		s.mb.RecordGenericGauge(metric.Value, name, metric.Tags, time.Now())
+	case "ms", "h", "d":
+		if metric.Count > 0 {
+			avg := metric.Sum / metric.Count
+			s.mb.RecordGenericTimer(avg, metric.Min, metric.Max, name, metric.Tags, time.Now())
+			s.mb.RecordStatsDTimerCount(metric.Count, name, "", metric.Tags, metric.StartTime, time.Now())
+		}
+	case "s":
+		s.mb.RecordStatsDSet(len(metric.Members), name, "", metric.Tags, time.Now())
+	}
+}
+
+// recordMapped emits a well-known Airflow metric under its own name and unit
+func (s *StatsDScraper) recordMapped(metric *StatsDMetric, mapping statsDMapping, tags map[string]string) {
+	now := time.Now()
+	switch metric.Type {
+	case "c":
+		s.mb.RecordStatsDCounter(metric.Value*mapping.scale, mapping, tags, metric.StartTime, now)
+	case "g":
+		s.mb.RecordStatsDGauge(metric.Value*mapping.scale, mapping, tags, now)
+	case "ms", "h", "d":
+		if metric.Count > 0 {
+			avg := metric.Sum / metric.Count
+			s.mb.RecordStatsDTimer(avg*mapping.scale, metric.Min*mapping.scale, metric.Max*mapping.scale, mapping, tags, now)
+			s.mb.RecordStatsDTimerCount(metric.Count, mapping.name, mapping.description, tags, metric.StartTime, now)
+		}
+	case "s":
+		s.mb.RecordStatsDSet(len(metric.Members), mapping.name, mapping.description, tags, now)
+	}
+}
+
 func (s *StatsDScraper) Shutdown(ctx context.Context) error {
 	s.settings.Logger.Info("Shutting down StatsD scraper")
 	close(s.stopChan)
@@ -232,5 +394,10 @@ func (s *StatsDScraper) Shutdown(ctx context.Context) error {
 		s.conn.Close()
 	}
 	s.wg.Wait()
+	if s.conn != nil && s.transport() == "unixgram" {
+		if err := os.Remove(s.cfg.Endpoint); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove unix socket: %w", err)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+)
+
+// ErrAuthFailed marks errors caused by rejected credentials, which retrying
+// will not fix
+var ErrAuthFailed = errors.New("authentication failed")
+
+// StatusReporter combines the health of every scraper in a receiver into the
+// single component status seen by the healthcheck extension. The worst
+// scraper status wins, so one recovering scraper cannot hide another that is
+// still failing.
+type StatusReporter struct {
+	mu       sync.Mutex
+	host     component.Host
+	events   map[string]*componentstatus.Event
+	reported componentstatus.Status
+}
+
+func NewStatusReporter() *StatusReporter {
+	return &StatusReporter{
+		events: make(map[string]*componentstatus.Event),
+	}
+}
+
+// SetHost records the host to report to. Scrapers call it from Start, and the
+// first call wins since every scraper of a receiver shares the same host.
+func (r *StatusReporter) SetHost(host component.Host) {
+	if r == nil || host == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.host == nil {
+		r.host = host
+	}
+}
+
+// Report updates one scraper's status and reports the combined status when
+// it changes
+func (r *StatusReporter) Report(scraperType string, ev *componentstatus.Event) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	r.events[scraperType] = ev
+	
+	worst := ev
+	for _, other := range r.events {
+		if statusSeverity(other.Status()) > statusSeverity(worst.Status()) {
+			worst = other
+		}
+	}
+	if r.host == nil || worst.Status() == r.reported {
+		return
+	}
+	r.reported = worst.Status()
+	componentstatus.ReportStatus(r.host, worst)
+}
+
+func statusSeverity(status componentstatus.Status) int {
+	switch status {
+	case componentstatus.StatusPermanentError:
+		return 2
+	case componentstatus.StatusRecoverableError:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsPermanentError reports whether an error needs operator action, such as
+// rejected REST API or database credentials
+func IsPermanentError(err error) bool {
+	if errors.Is(err, ErrAuthFailed) {
+		return true
+	}
+	// Class 28 is invalid authorization specification
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && len(pgErr.Code) >= 2 && pgErr.Code[:2] == "28"
+}
@@ -0,0 +1,183 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// CapabilityTaskInstanceBatch is POST /dags/~/dagRuns/~/taskInstances/list
+// with paging, which older webservers reject with a 400
+const CapabilityTaskInstanceBatch = "task_instance_batch"
+
+// taskInstancesListRequest is the body of
+// POST /api/v1/dags/~/dagRuns/~/taskInstances/list
+type taskInstancesListRequest struct {
+	DAGIDs           []string `json:"dag_ids,omitempty"`
+	DAGRunIDs        []string `json:"dag_run_ids,omitempty"`
+	ExecutionDateGTE string   `json:"execution_date_gte,omitempty"`
+	PageOffset       int      `json:"page_offset"`
+	PageLimit        int      `json:"page_limit"`
+}
+
+// batchTaskInstances reports whether task instances of all DAGs are listed
+// together rather than per run
+func (s *RESTAPIScraper) batchTaskInstances() bool {
+	return s.cfg.BatchTaskInstances && s.endpointEnabled(EndpointTaskInstances) &&
+		s.caps.supported(CapabilityTaskInstanceBatch)
+}
+
+// fetchTaskInstancesBatch lists the task instances of every run in results
+// with one paginated request, filtered to their DAGs, run ids and logical
+// dates. If the listing fails, each run's task instances are requested on
+// their own; if it is cut off, so are those of the runs it may not cover.
+func (s *RESTAPIScraper) fetchTaskInstancesBatch(ctx context.Context, dags []DAG, results []dagRunsResult) {
+	var dagIDs, runIDs []string
+	var from time.Time
+	unbounded := false
+	targets := make(map[runKey]*dagRunsResult)
+	for i, dag := range dags {
+		result := &results[i]
+		if !result.fetched {
+			continue
+		}
+		result.tasks = make(map[string][]TaskInstance)
+		if len(result.taskRunIDs) == 0 {
+			continue
+		}
+		dagIDs = append(dagIDs, dag.DAGID)
+		// A DAG without a known logical date needs an unfiltered listing
+		unbounded = unbounded || result.taskRunsFrom.IsZero()
+		from = earliest(from, result.taskRunsFrom)
+		for _, runID := range result.taskRunIDs {
+			targets[runKey{dagID: dag.DAGID, runID: runID}] = result
+		}
+		runIDs = append(runIDs, result.taskRunIDs...)
+	}
+	if len(targets) == 0 {
+		return
+	}
+	if unbounded {
+		from = time.Time{}
+	}
+	// Run ids such as scheduled__<date> repeat across DAGs
+	slices.Sort(runIDs)
+	runIDs = slices.Compact(runIDs)
+
+	maxTasks := s.cfg.MaxTaskInstances
+	if maxTasks <= 0 {
+		maxTasks = DefaultMaxTaskInstances
+	}
+	page, err := s.listTaskInstances(ctx, dagIDs, runIDs, from, maxTasks*len(targets))
+	if err != nil {
+		if errors.Is(err, ErrBadRequest) && s.caps.disable(CapabilityTaskInstanceBatch) {
+			s.settings.Logger.Info("Airflow API does not support batch task instance listing, requesting them per run",
+				zap.String("endpoint", s.cfg.Endpoint))
+		} else {
+			s.settings.Logger.Warn("Failed to list task instances in batch, requesting them per run", zap.Error(err))
+		}
+		var g errgroup.Group
+		g.SetLimit(s.cfg.Concurrency)
+		for i, dag := range dags {
+			if results[i].fetched && len(results[i].taskRunIDs) > 0 {
+				g.Go(func() error {
+					s.fetchTaskInstances(ctx, dag.DAGID, &results[i])
+					return nil
+				})
+			}
+		}
+		_ = g.Wait()
+		return
+	}
+
+	// Every requested run gets an entry, even with no task instances yet
+	for key, result := range targets {
+		result.tasks[key.runID] = nil
+	}
+	for _, task := range page.items {
+		key := runKey{dagID: task.DAGID, runID: task.DAGRunID}
+		if result, ok := targets[key]; ok {
+			result.tasks[key.runID] = append(result.tasks[key.runID], task)
+		}
+	}
+	if page.total <= len(page.items) {
+		return
+	}
+
+	// The listing is unordered, so a run below its own cap may be missing
+	// task instances; those runs are requested on their own, which also
+	// accounts for their truncation
+	var mu sync.Mutex
+	var g errgroup.Group
+	g.SetLimit(s.cfg.Concurrency)
+	for key, result := range targets {
+		if len(result.tasks[key.runID]) >= maxTasks {
+			continue
+		}
+		g.Go(func() error {
+			tasks, err := s.getTaskInstances(ctx, key.dagID, key.runID)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				delete(result.tasks, key.runID)
+				result.errs = append(result.errs, fmt.Errorf("failed to get task instances for %s/%s: %w", key.dagID, key.runID, err))
+				return nil
+			}
+			result.tasks[key.runID] = tasks
+			return nil
+		})
+	}
+	_ = g.Wait()
+}
+
+// listTaskInstances pages through the task instances of runIDs in dagIDs
+// with a logical date at or after from, reading at most maxResults
+func (s *RESTAPIScraper) listTaskInstances(ctx context.Context, dagIDs, runIDs []string, from time.Time, maxResults int) (pagedResult[TaskInstance], error) {
+	query := taskInstancesListRequest{DAGIDs: dagIDs, DAGRunIDs: runIDs}
+	if !from.IsZero() {
+		query.ExecutionDateGTE = from.UTC().Format(time.RFC3339)
+	}
+	page, err := readPages(s, maxResults, func(limit, offset int) ([]TaskInstance, int, error) {
+		query.PageLimit, query.PageOffset = limit, offset
+		body, err := s.doPost(ctx, "/api/v1/dags/~/dagRuns/~/taskInstances/list", query)
+		if err != nil {
+			return nil, 0, err
+		}
+		var response TaskInstancesResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, 0, err
+		}
+		return response.TaskInstances, response.TotalEntries, nil
+	})
+	if err != nil {
+		return pagedResult[TaskInstance]{}, err
+	}
+	return page, nil
+}
+
+// runLogicalDate is a run's logical date, named execution_date before
+// Airflow 2.2
+func runLogicalDate(run DAGRun) time.Time {
+	if !run.LogicalDate.IsZero() {
+		return run.LogicalDate
+	}
+	return run.ExecutionDate
+}
+
+// earliest returns the earlier of two times, ignoring zero times
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
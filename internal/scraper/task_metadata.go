@@ -0,0 +1,135 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/scraper/scrapererror"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// taskDefinitions is the cached task list of one DAG
+type taskDefinitions struct {
+	tasks   []Task
+	expires time.Time
+}
+
+// TaskInfo is the static definition of a task with its upstream edges
+// resolved from the downstream lists of its siblings
+type TaskInfo struct {
+	DAGID             string
+	TaskID            string
+	Operator          string
+	OperatorModule    string
+	Owner             string
+	Retries           int64
+	TriggerRule       string
+	Pool              string
+	Queue             string
+	UpstreamTaskIDs   []string
+	DownstreamTaskIDs []string
+}
+
+func (s *RESTAPIScraper) getTasks(ctx context.Context, dagID string) ([]Task, error) {
+	body, err := s.doRequest(ctx, fmt.Sprintf("/api/v1/dags/%s/tasks", url.PathEscape(dagID)))
+	if err != nil {
+		return nil, err
+	}
+	
+	var response TasksResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	
+	return response.Tasks, nil
+}
+
+// scrapeTaskMetadata records airflow.task.info for every task of every DAG.
+// Task definitions only change on DAG file reloads, so they are cached for
+// DAGCacheTTL like the DAG inventory.
+func (s *RESTAPIScraper) scrapeTaskMetadata(ctx context.Context, dags []DAG, ts pcommon.Timestamp, errs *scrapererror.ScrapeErrors) {
+	now := time.Now()
+	
+	// Work out which DAGs need a refresh before fetching concurrently
+	var stale []string
+	current := make(map[string]bool, len(dags))
+	for _, dag := range dags {
+		current[dag.DAGID] = true
+		if cached, ok := s.taskCache[dag.DAGID]; !ok || !now.Before(cached.expires) {
+			stale = append(stale, dag.DAGID)
+		}
+	}
+	for dagID := range s.taskCache {
+		if !current[dagID] {
+			delete(s.taskCache, dagID)
+		}
+	}
+	
+	fetched := make([][]Task, len(stale))
+	fetchErrs := make([]error, len(stale))
+	var g errgroup.Group
+	g.SetLimit(s.cfg.Concurrency)
+	for i, dagID := range stale {
+		g.Go(func() error {
+			fetched[i], fetchErrs[i] = s.getTasks(ctx, dagID)
+			return nil
+		})
+	}
+	_ = g.Wait()
+	
+	for i, dagID := range stale {
+		if fetchErrs[i] != nil {
+			s.settings.Logger.Warn("Failed to get tasks", zap.String("dag_id", dagID), zap.Error(fetchErrs[i]))
+			errs.AddPartial(1, fmt.Errorf("failed to get tasks for DAG %s: %w", dagID, fetchErrs[i]))
+			continue
+		}
+		s.taskCache[dagID] = taskDefinitions{tasks: fetched[i], expires: now.Add(s.cfg.DAGCacheTTL)}
+	}
+	
+	for _, dag := range dags {
+		cached, ok := s.taskCache[dag.DAGID]
+		if !ok {
+			continue
+		}
+		for _, info := range buildTaskInfos(dag.DAGID, cached.tasks) {
+			s.mb.RecordTaskInfo(info, ts)
+		}
+	}
+}
+
+// buildTaskInfos resolves upstream relationships, which the API only exposes
+// as downstream_task_ids
+func buildTaskInfos(dagID string, tasks []Task) []TaskInfo {
+	upstream := make(map[string][]string, len(tasks))
+	for _, task := range tasks {
+		for _, downstreamID := range task.DownstreamTaskIDs {
+			upstream[downstreamID] = append(upstream[downstreamID], task.TaskID)
+		}
+	}
+	
+	infos := make([]TaskInfo, len(tasks))
+	for i, task := range tasks {
+		infos[i] = TaskInfo{
+			DAGID:             dagID,
+			TaskID:            task.TaskID,
+			Operator:          task.ClassRef.ClassName,
+			OperatorModule:    task.ClassRef.ModulePath,
+			Owner:             task.Owner,
+			Retries:           int64(task.Retries),
+			TriggerRule:       task.TriggerRule,
+			Pool:              task.Pool,
+			Queue:             task.Queue,
+			UpstreamTaskIDs:   upstream[task.TaskID],
+			DownstreamTaskIDs: task.DownstreamTaskIDs,
+		}
+	}
+	return infos
+}
@@ -0,0 +1,61 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+type taskKey struct {
+	dagID  string
+	taskID string
+}
+
+// taskTries counts finished task instances of one task by the attempt they
+// finished on
+type taskTries struct {
+	byTry     map[int]int64
+	retries   int64
+	successes int64
+}
+
+// taskTryStats aggregates attempts per task so flaky tasks stand out even
+// when their retries eventually succeed
+type taskTryStats map[taskKey]*taskTries
+
+func newTaskTryStats() taskTryStats {
+	return make(taskTryStats)
+}
+
+// add counts finished task instances; other states are ignored
+func (t taskTryStats) add(dagID, taskID, state string, try int, count int64) {
+	if state != "success" && state != "failed" {
+		return
+	}
+	if try < 1 {
+		try = 1
+	}
+	key := taskKey{dagID: dagID, taskID: taskID}
+	tries, ok := t[key]
+	if !ok {
+		tries = &taskTries{byTry: make(map[int]int64)}
+		t[key] = tries
+	}
+	tries.byTry[try] += count
+	tries.retries += int64(try-1) * count
+	if state == "success" {
+		tries.successes += count
+	}
+}
+
+func (t taskTryStats) record(mb *MetricsBuilder, ts pcommon.Timestamp) {
+	for key, tries := range t {
+		for try, count := range tries.byTry {
+			mb.RecordTaskInstanceTries(count, key.dagID, key.taskID, try, ts)
+		}
+		if tries.successes > 0 {
+			mb.RecordTaskRetriesPerSuccess(float64(tries.retries)/float64(tries.successes), key.dagID, key.taskID, ts)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scraper
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+type workerStateKey struct {
+	hostname string
+	state    string
+}
+
+// workerTaskStats aggregates the fetched task instances by the worker that
+// ran them, so slow or overloaded Celery workers stand out
+type workerTaskStats struct {
+	counts    map[workerStateKey]int64
+	durations map[string]float64
+	finished  map[string]int64
+}
+
+func newWorkerTaskStats() *workerTaskStats {
+	return &workerTaskStats{
+		counts:    make(map[workerStateKey]int64),
+		durations: make(map[string]float64),
+		finished:  make(map[string]int64),
+	}
+}
+
+// add counts a task instance; instances not yet picked up by a worker have
+// no hostname and are ignored
+func (w *workerTaskStats) add(task TaskInstance) {
+	if task.Hostname == "" {
+		return
+	}
+	w.counts[workerStateKey{hostname: task.Hostname, state: task.State}]++
+	if (task.State == "success" || task.State == "failed") && task.Duration > 0 {
+		w.durations[task.Hostname] += task.Duration
+		w.finished[task.Hostname]++
+	}
+}
+
+func (w *workerTaskStats) record(mb *MetricsBuilder, ts pcommon.Timestamp) {
+	for key, count := range w.counts {
+		mb.RecordWorkerTaskInstances(count, key.hostname, key.state, ts)
+	}
+	for hostname, finished := range w.finished {
+		mb.RecordWorkerTaskDurationAvg(w.durations[hostname]/float64(finished), hostname, ts)
+	}
+}
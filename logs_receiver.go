@@ -5,103 +5,376 @@ package airflowreceiver
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/scraper"
+	"go.opentelemetry.io/collector/scraper/scrapererror"
+	"go.opentelemetry.io/collector/scraper/scraperhelper"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 	
 	scraper_internal "github.com/npcomplete777/airflowreceiver/internal/scraper"
 )
 
+// logsSource is a poller that produces log records, such as the event log
+// scraper or the DAG inventory watcher
+type logsSource interface {
+	Start(ctx context.Context, host component.Host) error
+	Scrape(ctx context.Context) (plog.Logs, error)
+	Shutdown(ctx context.Context) error
+}
+
+type logsSourceEntry struct {
+	name     string
+	source   logsSource
+	interval time.Duration
+}
+
 type logsReceiver struct {
 	settings receiver.Settings
 	consumer consumer.Logs
-	scraper  *scraper_internal.LogScraper
-	cancel   context.CancelFunc
-	interval time.Duration
+	sources  []logsSourceEntry
+	strict   bool
+	info     *scraper_internal.AirflowInfo
+	// redactor rewrites sensitive attribute values of every source
+	redactor *scraper_internal.Redactor
+	// controllerCfg holds the initial delay and timeout of every source;
+	// each source sets its own interval
+	controllerCfg scraperhelper.ControllerConfig
+	// drops counts batches the pipeline permanently rejected
+	drops *scraper_internal.DropTracker
+	
+	// One scraper controller per source, so each reports the collector's
+	// standard scraper telemetry under its own name
+	controllers []receiver.Logs
+}
+
+// logsSourceScraper adapts a logs source to the scraper interface. Unless
+// strict, a source that fails to start is started again on the next scrape.
+type logsSourceScraper struct {
+	entry   logsSourceEntry
+	strict  bool
+	info    *scraper_internal.AirflowInfo
+	logger  *zap.Logger
+	host    component.Host
+	started bool
+	// failed reports whether the last scrape returned no data; the
+	// controller consumes an empty batch then, which must not be committed
+	failed bool
 }
 
-func newLogsReceiver(
-	settings receiver.Settings,
-	cfg *LogConfig,
-	consumer consumer.Logs,
-) (*logsReceiver, error) {
+// newLogsReceiver creates a receiver with no sources. With strict set, a
+// source that fails to start fails receiver startup; otherwise its start is
+// retried on every poll.
+func newLogsReceiver(settings receiver.Settings, consumer consumer.Logs, strict bool, info *scraper_internal.AirflowInfo) *logsReceiver {
+	return &logsReceiver{
+		settings:      settings,
+		consumer:      consumer,
+		strict:        strict,
+		info:          info,
+		controllerCfg: scraperhelper.NewDefaultControllerConfig(),
+	}
+}
+
+// addEventLogSource polls the metadata database log table
+func (r *logsReceiver) addEventLogSource(cfg *LogConfig, drops *scraper_internal.DropTracker) {
 	logCfg := &scraper_internal.LogScraperConfig{
-		Host:               cfg.Host,
-		Port:               cfg.Port,
-		Database:           cfg.Database,
-		Username:           cfg.Username,
-		Password:           string(cfg.Password),
-		SSLMode:            cfg.SSLMode,
+		PostgresConnConfig: scraper_internal.PostgresConnConfig{
+			Host:        cfg.Host,
+			Port:        cfg.Port,
+			Database:    cfg.Database,
+			Username:    cfg.Username,
+			Password:    string(cfg.Password),
+			SSLMode:     cfg.SSLMode,
+			SSLRootCert: cfg.SSLRootCert,
+			SSLCert:     cfg.SSLCert,
+			SSLKey:      cfg.SSLKey,
+			
+			StatementTimeout: cfg.StatementTimeout,
+			ApplicationName:  cfg.ApplicationName,
+			RDSIAM:           cfg.RDSIAM.internal(cfg.AuthMode),
+		},
 		CollectionInterval: cfg.CollectionInterval,
+		EventFilter: scraper_internal.EventFilter{
+			Include: cfg.IncludeEvents,
+			Exclude: cfg.ExcludeEvents,
+		},
+		BodyFormat: cfg.BodyFormat,
+		Redactor:   r.redactor,
+		Start:      cfg.start(),
 	}
 	
-	return &logsReceiver{
-		settings: settings,
-		consumer: consumer,
-		scraper:  scraper_internal.NewLogScraper(logCfg, settings),
+	r.sources = append(r.sources, logsSourceEntry{
+		name:     "event_logs",
+		source:   scraper_internal.NewLogScraper(logCfg, r.settings, drops),
 		interval: cfg.CollectionInterval,
-	}, nil
+	})
 }
 
-func (r *logsReceiver) Start(ctx context.Context, host component.Host) error {
-	r.settings.Logger.Info("Starting Airflow logs receiver",
-		zap.Duration("interval", r.interval))
+// addRESTEventLogSource reads the audit log from the REST API for
+// deployments without database access
+func (r *logsReceiver) addRESTEventLogSource(restCfg *RESTAPIConfig, cfg *LogConfig, limiter *rate.Limiter, drops *scraper_internal.DropTracker) {
+	scraperCfg := newRESTAPIScraperConfig(restCfg)
+	scraperCfg.Name = "rest_api_event_logs"
+	scraperCfg.Limiter = limiter
+	scraperCfg.Info = r.info
+	scraperCfg.Redactor = r.redactor
+	rest := scraper_internal.NewRESTAPIScraper(scraperCfg, r.settings, drops)
+	filter := scraper_internal.EventFilter{
+		Include: cfg.IncludeEvents,
+		Exclude: cfg.ExcludeEvents,
+	}
 	
-	// Start the log scraper's database connection
-	if err := r.scraper.Start(ctx, host); err != nil {
-		return err
+	r.sources = append(r.sources, logsSourceEntry{
+		name:     "rest_event_logs",
+		source:   scraper_internal.NewRESTEventLogScraper(rest, r.settings, drops, filter, cfg.BodyFormat, cfg.start()),
+		interval: cfg.CollectionInterval,
+	})
+}
+
+// addInventorySource emits DAG inventory change events from the REST API
+func (r *logsReceiver) addInventorySource(cfg *RESTAPIConfig, limiter *rate.Limiter, drops *scraper_internal.DropTracker) {
+	scraperCfg := newRESTAPIScraperConfig(cfg)
+	scraperCfg.Limiter = limiter
+	scraperCfg.Info = r.info
+	scraperCfg.Redactor = r.redactor
+	rest := scraper_internal.NewRESTAPIScraper(scraperCfg, r.settings, drops)
+	
+	r.sources = append(r.sources, logsSourceEntry{
+		name:     "inventory",
+		source:   scraper_internal.NewInventoryWatcher(rest, r.settings),
+		interval: cfg.CollectionInterval,
+	})
+}
+
+// addDAGWarningSource emits DAG warnings from the REST API as they appear
+func (r *logsReceiver) addDAGWarningSource(cfg *RESTAPIConfig, limiter *rate.Limiter, drops *scraper_internal.DropTracker) {
+	scraperCfg := newRESTAPIScraperConfig(cfg)
+	scraperCfg.Name = "rest_api_dag_warnings"
+	scraperCfg.Limiter = limiter
+	scraperCfg.Info = r.info
+	scraperCfg.Redactor = r.redactor
+	rest := scraper_internal.NewRESTAPIScraper(scraperCfg, r.settings, drops)
+	
+	r.sources = append(r.sources, logsSourceEntry{
+		name:     "dag_warnings",
+		source:   scraper_internal.NewDAGWarningWatcher(rest, r.settings),
+		interval: cfg.CollectionInterval,
+	})
+}
+
+// addFailedTaskLogSource emits the log tail of every failed task attempt
+func (r *logsReceiver) addFailedTaskLogSource(cfg *RESTAPIConfig, limiter *rate.Limiter, drops *scraper_internal.DropTracker) {
+	scraperCfg := newRESTAPIScraperConfig(cfg)
+	scraperCfg.Name = "rest_api_task_logs"
+	scraperCfg.Limiter = limiter
+	scraperCfg.Info = r.info
+	scraperCfg.Redactor = r.redactor
+	rest := scraper_internal.NewRESTAPIScraper(scraperCfg, r.settings, drops)
+	
+	r.sources = append(r.sources, logsSourceEntry{
+		name:     "failed_task_logs",
+		source:   scraper_internal.NewFailedTaskLogWatcher(rest, r.settings, cfg.FailedTaskLogBytes),
+		interval: cfg.CollectionInterval,
+	})
+}
+
+// addStateChangeSource emits task instance and DAG run state changes as the
+// metadata database reports them. Standbys deliver no NOTIFY, nor logical
+// replication before Postgres 16, so it always connects to the primary,
+// ignoring read_replica.
+func (r *logsReceiver) addStateChangeSource(cfg *DatabaseConfig, drops *scraper_internal.DropTracker) {
+	connCfg := scraper_internal.PostgresConnConfig{
+		Host:        cfg.Host,
+		Port:        cfg.Port,
+		Database:    cfg.Database,
+		Username:    cfg.Username,
+		Password:    string(cfg.Password),
+		SSLMode:     cfg.SSLMode,
+		SSLRootCert: cfg.SSLRootCert,
+		SSLCert:     cfg.SSLCert,
+		SSLKey:      cfg.SSLKey,
+		
+		StatementTimeout: cfg.StatementTimeout,
+		ApplicationName:  cfg.ApplicationName,
+		RDSIAM:           cfg.RDSIAM.internal(cfg.AuthMode),
 	}
 	
-	// Create cancellable context for polling goroutine
-	ctx, cancel := context.WithCancel(context.Background())
-	r.cancel = cancel
+	var source logsSource
+	if cfg.StateChanges.Mode == scraper_internal.StateChangeModeLogicalReplication {
+		source = scraper_internal.NewStateCDCListener(&scraper_internal.StateCDCConfig{
+			PostgresConnConfig: connCfg,
+			Publication:        cfg.StateChanges.Publication,
+			Slot:               cfg.StateChanges.Slot,
+			TemporarySlot:      cfg.StateChanges.TemporarySlot,
+			Redactor:           r.redactor,
+		}, r.settings)
+	} else {
+		source = scraper_internal.NewStateChangeListener(&scraper_internal.StateChangeConfig{
+			PostgresConnConfig: connCfg,
+			Channel:            cfg.StateChanges.Channel,
+			InstallTrigger:     cfg.StateChanges.InstallTrigger,
+			Redactor:           r.redactor,
+		}, r.settings, drops)
+	}
 	
-	// Start polling goroutine
-	go r.poll(ctx)
+	r.sources = append(r.sources, logsSourceEntry{
+		name:     "state_changes",
+		source:   source,
+		interval: cfg.StateChanges.FlushInterval,
+	})
+}
+
+func (r *logsReceiver) Start(ctx context.Context, host component.Host) error {
+	r.settings.Logger.Info("Starting Airflow logs receiver", zap.Int("sources", len(r.sources)))
 	
+	for _, entry := range r.sources {
+		controller, err := r.newController(entry)
+		if err != nil {
+			return fmt.Errorf("failed to create %s logs source: %w", entry.name, err)
+		}
+		// Added first so Shutdown releases a source that failed to start
+		r.controllers = append(r.controllers, controller)
+		if err := controller.Start(ctx, host); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (r *logsReceiver) poll(ctx context.Context) {
-	ticker := time.NewTicker(r.interval)
-	defer ticker.Stop()
+// newController schedules a source on a scraper controller, which wraps each
+// scrape in the collector's scraper spans and log record counts
+func (r *logsReceiver) newController(entry logsSourceEntry) (receiver.Logs, error) {
+	sc := &logsSourceScraper{
+		entry:  entry,
+		strict: r.strict,
+		info:   r.info,
+		logger: r.settings.Logger,
+	}
+	factory := scraper.NewFactory(component.MustNewType(entry.name), nil,
+		scraper.WithLogs(func(context.Context, scraper.Settings, component.Config) (scraper.Logs, error) {
+			return scraper.NewLogs(sc.scrape, scraper.WithStart(sc.start), scraper.WithShutdown(entry.source.Shutdown))
+		}, component.StabilityLevelAlpha))
 	
-	for {
-		select {
-		case <-ctx.Done():
-			r.settings.Logger.Info("Stopping logs polling")
-			return
-		case <-ticker.C:
-			r.scrapeLogs(ctx)
-		}
+	// The controller scrapes once as soon as initial_delay has passed, then
+	// every interval, so logs do not lag a full interval behind startup
+	cfg := r.controllerCfg
+	cfg.CollectionInterval = entry.interval
+	next := sourceConsumer{
+		Logs:   r.consumer,
+		source: sc,
+		name:   entry.name,
+		retry:  scraper_internal.DefaultRetryConfig(),
+		logger: r.settings.Logger,
+		drops:  r.drops,
 	}
+	if checkpointer, ok := entry.source.(scraper_internal.LogsCheckpointer); ok {
+		next.checkpointer = checkpointer
+	}
+	return scraperhelper.NewLogsController(&cfg, r.settings, next,
+		scraperhelper.AddFactoryWithConfig(factory, nil))
 }
 
-func (r *logsReceiver) scrapeLogs(ctx context.Context) {
-	logs, err := r.scraper.Scrape(ctx)
-	if err != nil {
-		r.settings.Logger.Error("Failed to scrape logs", zap.Error(err))
-		return
+// sourceConsumer delivers one source's batches. Transient pipeline errors are
+// retried with backoff, which also holds back the source's next poll. A
+// source's read position is only committed once the batch is accepted, or
+// rejected for good; otherwise the batch is read and emitted again next poll.
+type sourceConsumer struct {
+	consumer.Logs
+	source       *logsSourceScraper
+	name         string
+	checkpointer scraper_internal.LogsCheckpointer
+	retry        scraper_internal.RetryConfig
+	logger       *zap.Logger
+	drops        *scraper_internal.DropTracker
+}
+
+func (c sourceConsumer) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	// The controller discarded what the failed scrape read, so it is read
+	// again next poll
+	if c.source.failed {
+		return nil
+	}
+	if ld.LogRecordCount() == 0 {
+		c.commit()
+		return nil
 	}
 	
-	if logs.LogRecordCount() == 0 {
-		return
+	err := scraper_internal.RetryWithBackoff(ctx, c.retry, c.logger, "consume "+c.name+" logs", func() error {
+		// Each attempt gets its own copy in case a consumer modified the last one
+		batch := plog.NewLogs()
+		ld.CopyTo(batch)
+		err := c.Logs.ConsumeLogs(ctx, batch)
+		if consumererror.IsPermanent(err) {
+			return scraper_internal.Permanent(err)
+		}
+		return err
+	})
+	if err != nil && !consumererror.IsPermanent(err) {
+		return err
+	}
+	if err != nil {
+		// Reading the batch again would only get it rejected again
+		c.drops.Record(scraper_internal.SignalLogs, scraper_internal.DropReasonRejected, int64(ld.LogRecordCount()),
+			zap.String("source", c.name), zap.Error(err))
+	}
+	c.commit()
+	return err
+}
+
+func (c sourceConsumer) commit() {
+	if c.checkpointer != nil {
+		c.checkpointer.Commit()
+	}
+}
+
+func (s *logsSourceScraper) start(ctx context.Context, host component.Host) error {
+	s.host = host
+	if err := s.entry.source.Start(ctx, host); err != nil {
+		if s.strict {
+			return fmt.Errorf("failed to start %s logs source: %w", s.entry.name, err)
+		}
+		s.logger.Warn("Failed to start logs source, retrying next poll",
+			zap.String("source", s.entry.name), zap.Error(err))
+		return nil
+	}
+	s.started = true
+	return nil
+}
+
+func (s *logsSourceScraper) scrape(ctx context.Context) (plog.Logs, error) {
+	s.failed = true
+	if !s.started {
+		if err := s.entry.source.Start(ctx, s.host); err != nil {
+			return plog.NewLogs(), fmt.Errorf("failed to start %s logs source: %w", s.entry.name, err)
+		}
+		s.started = true
 	}
 	
-	if err := r.consumer.ConsumeLogs(ctx, logs); err != nil {
-		r.settings.Logger.Error("Failed to consume logs", zap.Error(err))
+	logs, err := s.entry.source.Scrape(ctx)
+	if err != nil && !scrapererror.IsPartialScrapeError(err) {
+		return logs, err
 	}
+	s.failed = false
+	s.info.ApplyLogs(logs)
+	return logs, err
 }
 
 func (r *logsReceiver) Shutdown(ctx context.Context) error {
 	r.settings.Logger.Info("Shutting down Airflow logs receiver")
 	
-	if r.cancel != nil {
-		r.cancel()
+	var errs []error
+	for _, controller := range r.controllers {
+		if err := controller.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
 	}
-	
-	return r.scraper.Shutdown(ctx)
+	return errors.Join(errs...)
 }
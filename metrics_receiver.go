@@ -0,0 +1,35 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package airflowreceiver
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// metricsReceiver runs every metrics component built for one receiver
+// configuration, such as the scraper controller and the OTLP passthrough
+// server, and starts and stops them together
+type metricsReceiver struct {
+	components []component.Component
+}
+
+func (r *metricsReceiver) Start(ctx context.Context, host component.Host) error {
+	for _, c := range r.components {
+		if err := c.Start(ctx, host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *metricsReceiver) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, c := range r.components {
+		errs = append(errs, c.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,156 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package airflowreceiver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+
+	scraper_internal "github.com/npcomplete777/airflowreceiver/internal/scraper"
+)
+
+const (
+	otlpMetricsPath     = "/v1/metrics"
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+// otlpReceiver accepts metrics from Airflow's native OpenTelemetry
+// integration, enriches them and forwards them to the pipeline
+type otlpReceiver struct {
+	cfg      *OTLPConfig
+	settings receiver.Settings
+	consumer consumer.Metrics
+	enricher *scraper_internal.OTLPEnricher
+	server   *http.Server
+	wg       sync.WaitGroup
+}
+
+func newOTLPReceiver(
+	settings receiver.Settings,
+	cfg *OTLPConfig,
+	enricher *scraper_internal.OTLPEnricher,
+	consumer consumer.Metrics,
+) *otlpReceiver {
+	return &otlpReceiver{
+		cfg:      cfg,
+		settings: settings,
+		consumer: consumer,
+		enricher: enricher,
+	}
+}
+
+func (r *otlpReceiver) Start(ctx context.Context, host component.Host) error {
+	r.settings.Logger.Info("Starting Airflow OTLP passthrough receiver",
+		zap.String("endpoint", r.cfg.Endpoint))
+
+	if err := r.enricher.Start(ctx, host); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(otlpMetricsPath, r.handleMetrics)
+
+	server, err := r.cfg.ToServer(ctx, host, r.settings.TelemetrySettings, mux)
+	if err != nil {
+		return errors.Join(err, r.enricher.Shutdown(ctx))
+	}
+	listener, err := r.cfg.ToListener(ctx)
+	if err != nil {
+		return errors.Join(err, r.enricher.Shutdown(ctx))
+	}
+	r.server = server
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		if err := r.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			r.settings.Logger.Error("OTLP passthrough server failed", zap.Error(err))
+		}
+	}()
+
+	return nil
+}
+
+func (r *otlpReceiver) handleMetrics(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	// Parameters such as charset are allowed and ignored
+	contentType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, "invalid content type", http.StatusUnsupportedMediaType)
+		return
+	}
+	otlpReq := pmetricotlp.NewExportRequest()
+	switch contentType {
+	case contentTypeProtobuf:
+		err = otlpReq.UnmarshalProto(body)
+	case contentTypeJSON:
+		err = otlpReq.UnmarshalJSON(body)
+	default:
+		http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to decode OTLP request", http.StatusBadRequest)
+		return
+	}
+
+	md := otlpReq.Metrics()
+	r.enricher.Enrich(md)
+
+	if err := r.consumer.ConsumeMetrics(req.Context(), md); err != nil {
+		status := http.StatusServiceUnavailable
+		if consumererror.IsPermanent(err) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	var resp []byte
+	if contentType == contentTypeJSON {
+		resp, err = pmetricotlp.NewExportResponse().MarshalJSON()
+	} else {
+		resp, err = pmetricotlp.NewExportResponse().MarshalProto()
+	}
+	if err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(resp)
+}
+
+func (r *otlpReceiver) Shutdown(ctx context.Context) error {
+	r.settings.Logger.Info("Shutting down Airflow OTLP passthrough receiver")
+
+	var err error
+	if r.server != nil {
+		err = r.server.Shutdown(ctx)
+	}
+	r.wg.Wait()
+	return errors.Join(err, r.enricher.Shutdown(ctx))
+}
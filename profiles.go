@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package airflowreceiver
+
+import (
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/confmap"
+
+	scraper_internal "github.com/npcomplete777/airflowreceiver/internal/scraper"
+)
+
+const (
+	ProfileMinimal  = "minimal"
+	ProfileStandard = "standard"
+	ProfileDeep     = "deep"
+)
+
+// collectionProfile is a curated bundle of settings applied before the user
+// configuration is unmarshaled, so any explicitly configured value wins.
+type collectionProfile struct {
+	collectionInterval time.Duration
+	restInterval       time.Duration
+	restEndpoints      []string
+	includePastRuns    bool
+	pastRunsLookback   time.Duration
+	databaseInterval   time.Duration
+	// disabledAttributes are the optional attributes the profile turns off
+	disabledAttributes []string
+}
+
+var collectionProfiles = map[string]collectionProfile{
+	// Health, inventory and capacity only: a handful of requests per scrape.
+	ProfileMinimal: {
+		collectionInterval: 2 * time.Minute,
+		restInterval:       2 * time.Minute,
+		restEndpoints: []string{
+			scraper_internal.EndpointHealth,
+			scraper_internal.EndpointDAGs,
+			scraper_internal.EndpointPools,
+			scraper_internal.EndpointImportErrors,
+		},
+		databaseInterval:   5 * time.Minute,
+		disabledAttributes: scraper_internal.OptionalAttributes,
+	},
+	// DAG run states and durations without per-task-instance detail.
+	ProfileStandard: {
+		collectionInterval: time.Minute,
+		restInterval:       time.Minute,
+		restEndpoints: []string{
+			scraper_internal.EndpointHealth,
+			scraper_internal.EndpointDAGs,
+			scraper_internal.EndpointDAGRuns,
+			scraper_internal.EndpointPools,
+			scraper_internal.EndpointConnections,
+			scraper_internal.EndpointVariables,
+			scraper_internal.EndpointImportErrors,
+			scraper_internal.EndpointProviders,
+			scraper_internal.EndpointPlugins,
+			scraper_internal.EndpointDAGWarnings,
+		},
+		databaseInterval:   time.Minute,
+		disabledAttributes: []string{"hostname", "map_index"},
+	},
+	// Everything, including task instances and historical runs.
+	ProfileDeep: {
+		collectionInterval: 30 * time.Second,
+		restInterval:       30 * time.Second,
+		restEndpoints:      scraper_internal.AllEndpoints(),
+		includePastRuns:    true,
+		pastRunsLookback:   24 * time.Hour,
+		databaseInterval:   30 * time.Second,
+	},
+}
+
+// Unmarshal applies the selected profile's settings first and then the user
+// configuration on top of them.
+func (cfg *Config) Unmarshal(componentParser *confmap.Conf) error {
+	if componentParser == nil {
+		return nil
+	}
+
+	if profile, ok := componentParser.Get("profile").(string); ok && profile != "" {
+		// The profile only fills in the modes that end up enabled, so they
+		// are read ahead of the rest
+		modes, err := componentParser.Sub("collection_modes")
+		if err != nil {
+			return err
+		}
+		if err := modes.Unmarshal(&cfg.CollectionModes); err != nil {
+			return err
+		}
+		if err := cfg.applyProfile(profile); err != nil {
+			return err
+		}
+	}
+
+	return componentParser.Unmarshal(cfg)
+}
+
+func (cfg *Config) applyProfile(name string) error {
+	p, ok := collectionProfiles[name]
+	if !ok {
+		return fmt.Errorf("unknown profile %q (expected %s, %s or %s)", name, ProfileMinimal, ProfileStandard, ProfileDeep)
+	}
+
+	cfg.ControllerConfig.CollectionInterval = p.collectionInterval
+
+	if len(p.disabledAttributes) > 0 {
+		cfg.Attributes = make(map[string]AttributeConfig, len(p.disabledAttributes))
+		for _, key := range p.disabledAttributes {
+			cfg.Attributes[key] = AttributeConfig{Enabled: false}
+		}
+	}
+
+	if cfg.CollectionModes.RESTAPI {
+		if cfg.RESTAPIConfig == nil {
+			cfg.RESTAPIConfig = &RESTAPIConfig{ClientConfig: confighttp.NewDefaultClientConfig()}
+		}
+		cfg.RESTAPIConfig.CollectionInterval = p.restInterval
+		cfg.RESTAPIConfig.Endpoints = append([]string(nil), p.restEndpoints...)
+		cfg.RESTAPIConfig.IncludePastRuns = p.includePastRuns
+		cfg.RESTAPIConfig.PastRunsLookback = p.pastRunsLookback
+	}
+
+	if cfg.CollectionModes.Database {
+		if cfg.DatabaseConfig == nil {
+			cfg.DatabaseConfig = &DatabaseConfig{}
+		}
+		cfg.DatabaseConfig.CollectionInterval = p.databaseInterval
+	}
+
+	return nil
+}